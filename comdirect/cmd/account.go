@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"log"
@@ -41,7 +42,7 @@ func printAccountTable(account *comdirect.AccountBalances) {
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 	table.SetCenterSeparator("|")
 	for _, a := range account.Values {
-		table.Append([]string{a.AccountId, a.Account.AccountType.Text, a.Account.Iban, a.Account.CreditLimit.Value})
+		table.Append([]string{a.AccountId, a.Account.AccountType.Text, iban.Format(a.Account.Iban), a.Account.CreditLimit.Value.String()})
 	}
 	table.Render()
 }
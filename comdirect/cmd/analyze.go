@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/analyze"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topFlag int
+
+	monthsHeader    = []string{"MONTH", "COUNT", "INCOME", "EXPENSES", "NET", "BALANCE", "UNIT"}
+	payeesHeader    = []string{"PAYEE", "COUNT", "VALUE", "UNIT"}
+	recurringHeader = []string{"PAYEE", "PERIOD", "COUNT", "LAST", "NEXT", "VALUE", "UNIT"}
+
+	analyzeCmd = &cobra.Command{
+		Use:   "analyze",
+		Short: "analyze account transactions over time",
+	}
+	cashflowCmd = &cobra.Command{
+		Use:   "cashflow ACCOUNT_ID",
+		Short: "print the monthly cash flow, top payees and recurring payments of an account",
+		Args:  cobra.ExactArgs(1),
+		Run:   cashflow,
+	}
+)
+
+func cashflow(cmd *cobra.Command, args []string) {
+	from, err := comdirect.ParseDate(fromFlag)
+	if err != nil {
+		log.Fatalf("Failed to parse date from command line: %s", err)
+	}
+	if from.IsZero() {
+		// the last twelve months including the current one
+		now := time.Now().In(comdirect.Location)
+		from = comdirect.NewDate(now.Year(), now.Month()-11, 1)
+	}
+	to, err := comdirect.ParseDate(toFlag)
+	if err != nil {
+		log.Fatalf("Failed to parse date from command line: %s", err)
+	}
+
+	client := initClient()
+	// the balances are reconstructed from the current balance, so transactions after --to are needed as well
+	query := comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusBooked).From(from)
+	transactions := getAllTransactions(client, args[0], query)
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	balance, err := client.Balance(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to retrieve balance: %s", err)
+	}
+
+	report, err := analyze.Cashflow(*balance, transactions.Values, analyze.WithPeriod(from, to), analyze.WithTopPayees(topFlag))
+	if err != nil {
+		log.Fatalf("Failed to analyze transactions: %s", err)
+	}
+	switch formatFlag {
+	case "json":
+		printJSON(report)
+	case "csv":
+		printCashflowCSV(report)
+	default:
+		printCashflowTables(report)
+	}
+}
+
+func monthRow(m analyze.Month) []string {
+	return []string{m.Month, strconv.Itoa(m.Count), formatAmountValue(m.Income), formatAmountValue(m.Expenses),
+		formatAmountValue(m.Net), formatAmountValue(m.ClosingBalance), m.Net.Unit}
+}
+
+func payeeRow(p analyze.Payee) []string {
+	return []string{p.Name, strconv.Itoa(p.Count), formatAmountValue(p.Amount), p.Amount.Unit}
+}
+
+func recurringRow(r analyze.Recurring) []string {
+	return []string{r.Payee, r.Period, strconv.Itoa(r.Count), r.Last.String(), r.Next.String(), formatAmountValue(r.Amount), r.Amount.Unit}
+}
+
+// printCashflowCSV prints the months, top payees and recurring payments as CSV tables separated by empty lines.
+func printCashflowCSV(report *analyze.Report) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(monthsHeader)
+	for _, m := range report.Months {
+		table.Write(monthRow(m))
+	}
+	table.Flush()
+	fmt.Println()
+	table.Write(payeesHeader)
+	for _, p := range report.TopPayees {
+		table.Write(payeeRow(p))
+	}
+	table.Flush()
+	fmt.Println()
+	table.Write(recurringHeader)
+	for _, r := range report.Recurring {
+		table.Write(recurringRow(r))
+	}
+	table.Flush()
+}
+
+func printCashflowTables(report *analyze.Report) {
+	fmt.Printf("Cash flow from %s to %s\n\n", report.From, report.To)
+	months := newAnalyzeTable(monthsHeader)
+	months.SetCaption(true, fmt.Sprintf("income %s, expenses %s, opening balance %s",
+		formatAmountValue(report.Income), formatAmountValue(report.Expenses), formatAmountValue(report.OpeningBalance)))
+	for _, m := range report.Months {
+		months.Append(monthRow(m))
+	}
+	months.Render()
+	fmt.Println()
+
+	payees := newAnalyzeTable(payeesHeader)
+	for _, p := range report.TopPayees {
+		payees.Append(payeeRow(p))
+	}
+	payees.Render()
+	fmt.Println()
+
+	recurring := newAnalyzeTable(recurringHeader)
+	for _, r := range report.Recurring {
+		recurring.Append(recurringRow(r))
+	}
+	recurring.Render()
+}
+
+func newAnalyzeTable(header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	return table
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/archive"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveFlag string
+
+	archiveCmd = &cobra.Command{
+		Use:   "archive",
+		Short: "manage the local archive of accounts, transactions, positions and documents",
+		Long: "The archive is a local SQLite database that keeps the history of your accounts and depots beyond\n" +
+			"the retention limits of comdirect. Run 'comdirect archive update' daily, e.g. with cron, to record snapshots.",
+	}
+
+	archiveUpdateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "fetch the current data from comdirect and store it in the archive",
+		Args:  cobra.NoArgs,
+		Run:   archiveUpdate,
+	}
+
+	archiveQueryCmd = &cobra.Command{
+		Use:   "query SQL",
+		Short: "run a read-only SQL query against the archive",
+		Long: "run a read-only SQL query against the archive, e.g.\n\n" +
+			"  comdirect archive query \"SELECT booking_date, amount, counterparty FROM transactions ORDER BY booking_date DESC LIMIT 10\"\n\n" +
+			"The archive has the tables balances, transactions, positions, depot_transactions, orders and documents.",
+		Args: cobra.MinimumNArgs(1),
+		Run:  archiveQuery,
+	}
+)
+
+func archiveUpdate(cmd *cobra.Command, args []string) {
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	a := openArchive(ctx)
+	defer a.Close()
+
+	result, err := a.Update(ctx, client)
+	if err != nil {
+		log.Fatalf("Failed to update archive: %s", err)
+	}
+	switch formatFlag {
+	case "json":
+		printJSON(result)
+	default:
+		fmt.Printf("Archived %d balances, %d transactions, %d positions, %d depot transactions, %d orders and %d documents in %s\n",
+			result.Balances, result.Transactions, result.Positions, result.DepotTransactions, result.Orders, result.Documents, archiveFlag)
+	}
+}
+
+func archiveQuery(cmd *cobra.Command, args []string) {
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	a := openArchive(ctx)
+	defer a.Close()
+
+	result, err := a.Query(ctx, strings.Join(args, " "))
+	if err != nil {
+		log.Fatalf("Failed to query archive: %s", err)
+	}
+	switch formatFlag {
+	case "json":
+		printJSON(resultObjects(result))
+	case "csv":
+		table := csv.NewWriter(os.Stdout)
+		table.Write(result.Columns)
+		table.WriteAll(result.Rows)
+	default:
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(result.Columns)
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+		table.SetCenterSeparator("|")
+		table.AppendBulk(result.Rows)
+		table.Render()
+	}
+}
+
+func openArchive(ctx context.Context) *archive.Archive {
+	a, err := archive.Open(ctx, archiveFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return a
+}
+
+// resultObjects converts the rows of result to objects keyed by column name.
+func resultObjects(result *archive.Result) []map[string]string {
+	objects := make([]map[string]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		object := make(map[string]string, len(row))
+		for i, value := range row {
+			object[result.Columns[i]] = value
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func defaultArchivePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "comdirect-archive.db"
+	}
+	return filepath.Join(dir, "comdirect", "archive.db")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/csv"
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"log"
@@ -42,7 +43,7 @@ func printBalanceCSV(balances *comdirect.AccountBalances) {
 	table := csv.NewWriter(os.Stdout)
 	table.Write([]string{"ID", "TYPE", "IBAN", "BALANCE"})
 	for _, a := range balances.Values {
-		table.Write([]string{a.AccountId, a.Account.AccountType.Text, a.Account.Iban, a.Balance.Value})
+		table.Write([]string{a.AccountId, a.Account.AccountType.Text, a.Account.Iban, a.Balance.Value.String()})
 	}
 	table.Flush()
 }
@@ -53,7 +54,7 @@ func printBalanceTable(account *comdirect.AccountBalances) {
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 	table.SetCenterSeparator("|")
 	for _, a := range account.Values {
-		table.Append([]string{a.AccountId, a.Account.AccountType.Text, a.Account.Iban, a.Balance.Value})
+		table.Append([]string{a.AccountId, a.Account.AccountType.Text, iban.Format(a.Account.Iban), a.Balance.Value.String()})
 	}
 	table.Render()
 }
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/categorize"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	categoriesFlag string
+	summaryFlag    bool
+
+	categorizedHeader = []string{"BOOKING DATE", "PAYEE", "CATEGORY", "TAGS", "VALUE", "UNIT"}
+	totalsHeader      = []string{"MONTH", "CATEGORY", "COUNT", "VALUE", "UNIT"}
+	categorizeCmd     = &cobra.Command{
+		Use:   "categorize ACCOUNT_ID",
+		Short: "categorize account transactions with the rules of the categories file",
+		Args:  cobra.ExactArgs(1),
+		Run:   categorizeTransactions,
+	}
+)
+
+// categorizedTransactions are AccountTransactions annotated with their category and tags.
+type categorizedTransactions struct {
+	Paging comdirect.Paging         `json:"paging"`
+	Values []categorize.Transaction `json:"values"`
+}
+
+func categorizeTransactions(cmd *cobra.Command, args []string) {
+	query, err := transactionQuery()
+	if err != nil {
+		log.Fatalf("Invalid transaction filter: %s", err)
+	}
+	categorizer := loadCategorizer(cmd)
+	if categorizer == nil {
+		log.Fatalf("No categories file at %s, create one or use --categories", categoriesFlag)
+	}
+	client := initClient()
+	transactions := fetchTransactions(client, args[0], query)
+	categorized := categorizer.CategorizeAll(transactions.Values)
+
+	if summaryFlag {
+		totals := categorize.Totals(categorized)
+		switch formatFlag {
+		case "json":
+			printJSON(totals)
+		case "csv":
+			printTotalsCSV(totals)
+		default:
+			printTotalsTable(totals)
+		}
+		return
+	}
+	switch formatFlag {
+	case "json":
+		printJSON(categorizedTransactions{Paging: transactions.Paging, Values: categorized})
+	case "csv":
+		printCategorizedCSV(categorized)
+	default:
+		printCategorizedTable(categorized)
+	}
+}
+
+// loadCategorizer loads the categories file of --categories. It returns nil if the default categories
+// file does not exist.
+func loadCategorizer(cmd *cobra.Command) *categorize.Categorizer {
+	categorizer, err := categorize.Load(categoriesFlag)
+	if errors.Is(err, fs.ErrNotExist) && !cmd.Flags().Changed("categories") {
+		return nil
+	}
+	if err != nil {
+		log.Fatalf("Failed to load categories: %s", err)
+	}
+	return categorizer
+}
+
+func defaultCategoriesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "comdirect-categories.yaml"
+	}
+	return filepath.Join(dir, "comdirect", "categories.yaml")
+}
+
+func categorizedRow(t categorize.Transaction) []string {
+	return []string{
+		t.BookingDate.String(),
+		categorize.Payee(t.AccountTransaction),
+		t.Category,
+		strings.Join(t.Tags, ","),
+		formatAmountValue(t.Amount),
+		t.Amount.Unit,
+	}
+}
+
+func printCategorizedCSV(transactions []categorize.Transaction) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(categorizedHeader)
+	for _, t := range transactions {
+		table.Write(categorizedRow(t))
+	}
+	table.Flush()
+}
+
+func printCategorizedTable(transactions []categorize.Transaction) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(categorizedHeader)
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, t := range transactions {
+		table.Append(categorizedRow(t))
+	}
+	table.Render()
+}
+
+func totalRow(t categorize.Total) []string {
+	return []string{t.Month, t.Category, strconv.Itoa(t.Count), formatAmountValue(t.Amount), t.Amount.Unit}
+}
+
+func printTotalsCSV(totals []categorize.Total) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(totalsHeader)
+	for _, t := range totals {
+		table.Write(totalRow(t))
+	}
+	table.Flush()
+}
+
+func printTotalsTable(totals []categorize.Total) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(totalsHeader)
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, t := range totals {
+		table.Append(totalRow(t))
+	}
+	table.Render()
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"log"
+	"os"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/export"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	depotTransactionHeader = []string{"TRANSACTION ID", "BOOKING DATE", "WKN", "NAME", "TYPE", "QUANTITY", "PRICE", "VALUE", "UNIT"}
+	depotTransactionCmd    = &cobra.Command{
+		Use:   "transaction DEPOT_ID",
+		Short: "list depot transactions",
+		Args:  cobra.ExactArgs(1),
+		Run:   depotTransaction,
+	}
+)
+
+func depotTransaction(cmd *cobra.Command, args []string) {
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+
+	transactions := &comdirect.DepotTransactions{}
+	for t, err := range client.AllDepotTransactions(ctx, args[0]) {
+		if err != nil {
+			log.Fatalf("Failed to retrieve depot transactions: %s", err)
+		}
+		transactions.Values = append(transactions.Values, t)
+	}
+	transactions.Paging.Matches = len(transactions.Values)
+
+	switch formatFlag {
+	case "json":
+		printJSON(transactions)
+	case "csv":
+		printDepotTransactionsCSV(transactions)
+	case "ofx", "qif":
+		statement := export.NewInvestmentStatement(getDepot(client, args[0]), nil, transactions.Values)
+		printInvestmentStatement(statement)
+	case "ledger", "hledger", "beancount":
+		if err := newJournal().WriteDepotTransactions(os.Stdout, getDepot(client, args[0]), transactions.Values); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		printDepotTransactionsTable(transactions)
+	}
+}
+
+// getDepot returns the depot with the given ID, or a depot with only the ID if it is not found.
+func getDepot(client *comdirect.Client, depotID string) comdirect.Depot {
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	depots, err := client.Depots(ctx)
+	if err != nil {
+		log.Fatalf("Failed to retrieve depots: %s", err)
+	}
+	for _, d := range depots.Values {
+		if d.DepotId == depotID {
+			return d
+		}
+	}
+	return comdirect.Depot{DepotId: depotID}
+}
+
+// printInvestmentStatement prints the statement in OFX or QIF format.
+func printInvestmentStatement(statement *export.InvestmentStatement) {
+	var err error
+	if formatFlag == "ofx" {
+		err = export.WriteInvestmentOFX(os.Stdout, statement)
+	} else {
+		err = export.WriteInvestmentQIF(os.Stdout, statement)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func depotTransactionRow(t comdirect.DepotTransaction) []string {
+	return []string{
+		t.TransactionID,
+		t.BookingDate.String(),
+		t.Instrument.WKN,
+		t.Instrument.ShortName,
+		t.TransactionType,
+		t.Quantity.Value.String(),
+		t.ExecutionPrice.Value.String(),
+		t.TransactionValue.Value.String(),
+		t.TransactionValue.Unit,
+	}
+}
+
+func printDepotTransactionsCSV(transactions *comdirect.DepotTransactions) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(depotTransactionHeader)
+	for _, t := range transactions.Values {
+		table.Write(depotTransactionRow(t))
+	}
+	table.Flush()
+}
+
+func printDepotTransactionsTable(transactions *comdirect.DepotTransactions) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(depotTransactionHeader)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, t := range transactions.Values {
+		table.Append(depotTransactionRow(t))
+	}
+	table.Render()
+}
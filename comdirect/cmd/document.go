@@ -86,7 +86,7 @@ func printDocumentTable(documents *comdirect.Documents) {
 		if len(name) > 30 {
 			name = name[:30]
 		}
-		table.Append([]string{d.DocumentID, name + "...", d.DateCreation, fmt.Sprintf("%t", d.DocumentMetaData.AlreadyRead), d.MimeType})
+		table.Append([]string{d.DocumentID, name + "...", d.DateCreation.String(), fmt.Sprintf("%t", d.DocumentMetaData.AlreadyRead), d.MimeType})
 	}
 	table.Render()
 }
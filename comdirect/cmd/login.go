@@ -3,7 +3,6 @@ package cmd
 import (
 	"bufio"
 	"fmt"
-	"github.com/jsattler/go-comdirect/comdirect/keychain"
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -55,27 +54,25 @@ func login(cmd *cobra.Command, args []string) {
 		Password:     passwordFlag,
 		ClientId:     clientIDFlag,
 		ClientSecret: clientSecretFlag,
+		TANHandler:   newTerminalTANHandler(),
 	}
 
-	if err := keychain.StoreAuthOptions(options); err != nil {
+	store := openStore()
+	if err := store.StoreAuthOptions(options); err != nil {
 		log.Fatal(err)
 	}
 
-	authenticator := comdirect.NewAuthenticator(options)
+	client := comdirect.NewWithAuthOptions(options, comdirect.WithTokenStore(store.TokenStore()))
 	ctx, cancel := contextWithTimeout()
 	defer cancel()
 
-	fmt.Println("Open your comdirect photoTAN app to complete the login")
+	fmt.Println("Complete the login with your TAN procedure, e.g. approve it in the comdirect photoTAN app")
 
-	authentication, err := authenticator.Authenticate(ctx)
+	authentication, err := client.Authenticate(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if err := keychain.StoreAuthentication(authentication); err != nil {
-		log.Fatal(err)
-	}
-
 	fmt.Printf("Successfully logged in - the session will expire in 10 minutes (%s)\n",
 		authentication.ExpiryTime().
 			Add(time.Duration(authentication.AccessToken().ExpiresIn)*time.Second).
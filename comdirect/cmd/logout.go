@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/jsattler/go-comdirect/comdirect/keychain"
 	"github.com/spf13/cobra"
+	"log"
 )
 
 var (
@@ -15,7 +16,10 @@ var (
 )
 
 func logout(cmd *cobra.Command, args []string) {
-	keychain.DeleteAuthentication()
-	keychain.DeleteAuthOptions()
+	store := openStore()
+	_ = store.TokenStore().Delete(context.Background())
+	if err := store.DeleteAuthOptions(); err != nil {
+		log.Fatal(err)
+	}
 	fmt.Println("Successfully logged out")
 }
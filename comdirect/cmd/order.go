@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sideFlag       string
+	instrumentFlag string
+	quantityFlag   string
+	limitFlag      string
+	venueFlag      string
+	validityFlag   string
+	dryRunFlag     bool
+	yesFlag        bool
+	orderTypeFlag  string
+
+	orderHeader = []string{"ID", "STATUS", "SIDE", "TYPE", "QUANTITY", "LIMIT", "UNIT", "VALIDITY"}
+
+	orderCmd = &cobra.Command{
+		Use:   "order",
+		Short: "list, create, update and delete orders and trade with quotes",
+	}
+	orderListCmd = &cobra.Command{
+		Use:   "list DEPOT_ID",
+		Short: "list the orders of a depot",
+		Args:  cobra.ExactArgs(1),
+		Run:   listOrders,
+	}
+	orderShowCmd = &cobra.Command{
+		Use:   "show ORDER_ID",
+		Short: "show an order with its sub-orders and executions",
+		Args:  cobra.ExactArgs(1),
+		Run:   showOrder,
+	}
+	orderCreateCmd = &cobra.Command{
+		Use:     "create DEPOT_ID",
+		Aliases: []string{"place"},
+		Short:   "place a market or limit order in a depot",
+		Args:    cobra.ExactArgs(1),
+		Run:     createOrder,
+	}
+	orderCostsCmd = &cobra.Command{
+		Use:   "costs DEPOT_ID",
+		Short: "print the ex-ante costs of an order without placing it",
+		Args:  cobra.ExactArgs(1),
+		Run:   orderCosts,
+	}
+	orderQuoteCmd = &cobra.Command{
+		Use:   "quote DEPOT_ID",
+		Short: "request a binding quote and execute it before it expires",
+		Args:  cobra.ExactArgs(1),
+		Run:   quoteOrder,
+	}
+	orderUpdateCmd = &cobra.Command{
+		Use:     "update ORDER_ID",
+		Aliases: []string{"change"},
+		Short:   "change the quantity, limit or validity of an open order",
+		Args:    cobra.ExactArgs(1),
+		Run:     updateOrder,
+	}
+	orderDeleteCmd = &cobra.Command{
+		Use:     "delete ORDER_ID",
+		Aliases: []string{"cancel"},
+		Short:   "cancel an open order",
+		Args:    cobra.ExactArgs(1),
+		Run:     deleteOrder,
+	}
+)
+
+func listOrders(cmd *cobra.Command, args []string) {
+	// comdirect distinguishes orders cancelled by the user, the system and the venue, so cancelled orders
+	// are filtered here instead of querying only one of the cancelled statuses
+	cancelled := slices.Contains([]string{"cancelled", "canceled"}, strings.ToLower(statusFlag))
+	query := comdirect.NewOrderQuery().Venue(venueFlag)
+	if statusFlag != "" && !cancelled {
+		query.Status(parseOrderStatus(statusFlag))
+	}
+	if sideFlag != "" {
+		query.Side(orderSide())
+	}
+	if orderTypeFlag != "" {
+		orderType, err := comdirect.ParseOrderType(orderTypeFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		query.OrderType(orderType)
+	}
+	client := initClient()
+	if instrumentFlag != "" {
+		query.Instrument(instrumentID(client))
+	}
+
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	orders := &comdirect.Orders{}
+	options := query.Options()
+	options.Add(comdirect.PagingCountQueryKey, countFlag)
+	for o, err := range client.AllOrders(ctx, args[0], options) {
+		if err != nil {
+			log.Fatalf("Failed to retrieve orders: %s", err)
+		}
+		if cancelled && !o.OrderStatus.Cancelled() {
+			continue
+		}
+		orders.Values = append(orders.Values, o)
+	}
+	orders.Paging.Matches = len(orders.Values)
+
+	switch formatFlag {
+	case "json":
+		printJSON(orders)
+	case "csv":
+		printOrderCSV(orders.Values)
+	default:
+		printOrderTable(orders.Values...)
+	}
+}
+
+func showOrder(cmd *cobra.Command, args []string) {
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	order, err := client.Order(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to retrieve order: %s", err)
+	}
+	if formatFlag == "json" {
+		printJSON(order)
+		return
+	}
+	// sub-orders, e.g. the legs of a one-cancels-other order, are listed below the order
+	printOrderTable(append([]comdirect.Order{*order}, order.SubOrders...)...)
+	if len(order.Executions) > 0 {
+		fmt.Println()
+		printExecutionTable(order.Executions)
+	}
+}
+
+// parseOrderStatus parses the status of the command line case-insensitively, e.g. "open" or "cancelled_user".
+func parseOrderStatus(s string) comdirect.OrderStatus {
+	status, err := comdirect.ParseOrderStatus(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return status
+}
+
+// orderSide parses the --side flag.
+func orderSide() comdirect.OrderSide {
+	side, err := comdirect.ParseOrderSide(sideFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return side
+}
+
+func createOrder(cmd *cobra.Command, args []string) {
+	client := initClient()
+	request := newOrderRequest(client, args[0])
+
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	if dryRunFlag {
+		if err := client.PreValidateOrder(ctx, request); err != nil {
+			log.Fatalf("Order is invalid: %s", err)
+		}
+		fmt.Println("The order is valid and was not placed.")
+		return
+	}
+	order, err := client.CreateOrder(ctx, request)
+	if err != nil {
+		log.Fatalf("Failed to create order: %s", err)
+	}
+	printOrder(order)
+}
+
+func orderCosts(cmd *cobra.Command, args []string) {
+	client := initClient()
+	request := newOrderRequest(client, args[0])
+
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	costs, err := client.ExAnteOrder(ctx, request)
+	if err != nil {
+		log.Fatalf("Failed to retrieve order costs: %s", err)
+	}
+	switch formatFlag {
+	case "json":
+		printJSON(costs)
+	default:
+		printCostsTable(costs)
+	}
+}
+
+// newOrderRequest creates a market order or, if a limit is given, a limit order from the command line.
+// The instrument is looked up by WKN, ISIN or ID, the venue by ID or name. The order is checked
+// against the capabilities of the venue before it is sent to comdirect.
+func newOrderRequest(client *comdirect.Client, depotID string) *comdirect.OrderRequest {
+	venue := orderVenue(client)
+	request := &comdirect.OrderRequest{
+		DepotID:   depotID,
+		Side:      orderSide(),
+		VenueID:   venue.VenueID,
+		OrderType: comdirect.OrderTypeMarket,
+	}
+	applyOrderFlags(request)
+	if request.Limit != nil {
+		request.OrderType = comdirect.OrderTypeLimit
+	}
+	if request.ValidityType == "" {
+		request.ValidityType = comdirect.ValidityTypeGoodForDay
+	}
+
+	request.InstrumentID = instrumentID(client)
+	if err := request.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := venue.ValidateOrder(request); err != nil {
+		log.Fatal(err)
+	}
+	return request
+}
+
+// orderVenue looks up the venue of the --venue flag in the order dimensions.
+func orderVenue(client *comdirect.Client) *comdirect.Venue {
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	dimensions, err := client.Dimensions(ctx)
+	if err != nil {
+		log.Fatalf("Failed to retrieve order dimensions: %s", err)
+	}
+	venue, ok := comdirect.FindVenue(dimensions, venueFlag)
+	if !ok {
+		log.Fatalf("No venue found for %q", venueFlag)
+	}
+	return venue
+}
+
+// instrumentID looks up the ID of the instrument of the --instrument flag.
+func instrumentID(client *comdirect.Client) string {
+	instruments, err := client.Instrument(instrumentFlag)
+	if err != nil {
+		log.Fatalf("Failed to retrieve instrument: %s", err)
+	}
+	if len(instruments) == 0 {
+		log.Fatalf("No instrument found for %s", instrumentFlag)
+	}
+	return instruments[0].InstrumentID
+}
+
+func quoteOrder(cmd *cobra.Command, args []string) {
+	quantity, err := comdirect.NewAmountValue(quantityFlag, "XXX")
+	if err != nil {
+		log.Fatalf("Invalid quantity: %s", err)
+	}
+	client := initClient()
+	request := &comdirect.QuoteRequest{
+		InstrumentID: instrumentID(client),
+		VenueID:      orderVenue(client).VenueID,
+		Side:         orderSide(),
+		Quantity:     quantity,
+	}
+
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	ticket, err := client.CreateQuoteTicket(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to create quote ticket: %s", err)
+	}
+	request.QuoteTicketID = ticket.QuoteTicketID
+	quote, err := client.CreateQuoteRequest(ctx, request)
+	if err != nil {
+		log.Fatalf("Failed to request quote: %s", err)
+	}
+	printQuoteTable(quote)
+
+	if !yesFlag && !confirm(fmt.Sprintf("Execute the quote within %s?", quote.TimeLeft().Round(time.Second))) {
+		fmt.Println("The quote was not executed.")
+		return
+	}
+	order, err := client.ExecuteQuote(ctx, args[0], quote)
+	if err != nil {
+		log.Fatalf("Failed to execute quote: %s", err)
+	}
+	printOrder(order)
+}
+
+// confirm asks a yes or no question on stdin and defaults to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func updateOrder(cmd *cobra.Command, args []string) {
+	request := &comdirect.OrderRequest{OrderID: args[0]}
+	applyOrderFlags(request)
+
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	order, err := client.UpdateOrder(ctx, request)
+	if err != nil {
+		log.Fatalf("Failed to update order: %s", err)
+	}
+	printOrder(order)
+}
+
+func deleteOrder(cmd *cobra.Command, args []string) {
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	if err := client.DeleteOrder(ctx, args[0]); err != nil {
+		log.Fatalf("Failed to delete order: %s", err)
+	}
+	fmt.Printf("Order %s was deleted.\n", args[0])
+}
+
+// applyOrderFlags sets the quantity, limit and validity of request from the command line.
+// Orders with a validity date are good till date.
+func applyOrderFlags(request *comdirect.OrderRequest) {
+	quantity, err := comdirect.NewAmountValue(quantityFlag, "XXX")
+	if err != nil {
+		log.Fatalf("Invalid quantity: %s", err)
+	}
+	request.Quantity = quantity
+	if limitFlag != "" {
+		limit, err := comdirect.NewAmountValue(limitFlag, "EUR")
+		if err != nil {
+			log.Fatalf("Invalid limit: %s", err)
+		}
+		request.Limit = &limit
+	}
+	if validityFlag != "" {
+		request.ValidityType = comdirect.ValidityTypeGoodTillDate
+		request.Validity = validityFlag
+	}
+}
+
+func printOrder(order *comdirect.Order) {
+	switch formatFlag {
+	case "json":
+		printJSON(order)
+	default:
+		printOrderTable(*order)
+	}
+}
+
+func orderRow(o comdirect.Order) []string {
+	limit := ""
+	if !o.Limit.IsZero() {
+		limit = o.Limit.Value.String()
+	}
+	return []string{
+		o.OrderID,
+		string(o.OrderStatus),
+		string(o.Side),
+		string(o.OrderType),
+		o.Quantity.Value.String(),
+		limit,
+		o.Limit.Unit,
+		o.Validity,
+	}
+}
+
+func printOrderCSV(orders []comdirect.Order) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(orderHeader)
+	for _, o := range orders {
+		table.Write(orderRow(o))
+	}
+	table.Flush()
+}
+
+func printOrderTable(orders ...comdirect.Order) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(orderHeader)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, o := range orders {
+		table.Append(orderRow(o))
+	}
+	table.Render()
+}
+
+func printExecutionTable(executions []comdirect.Execution) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"EXECUTION", "TIMESTAMP", "QUANTITY", "PRICE", "UNIT"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, e := range executions {
+		table.Append([]string{
+			e.ExecutionID,
+			e.ExecutionTimestamp.String(),
+			e.ExecutedQuantity.Value.String(),
+			e.ExecutionPrice.Value.String(),
+			e.ExecutionPrice.Unit,
+		})
+	}
+	table.Render()
+}
+
+func printCostsTable(costs *comdirect.CostIndication) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"COSTS", "VALUE", "UNIT", "PERCENT"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	table.SetCaption(true, fmt.Sprintf("order volume %s", costs.OrderVolume))
+	rows := []struct {
+		name string
+		cost comdirect.Cost
+	}{
+		{"Order costs", costs.OrderCosts},
+		{"Product costs", costs.ProductCosts},
+		{"Third-party fees", costs.ThirdPartyFees},
+		{"Total costs", costs.TotalCosts},
+		{"Effect on return, first year", costs.EffectOnReturn.FirstYear},
+		{"Effect on return, following years", costs.EffectOnReturn.FollowingYears},
+		{"Effect on return, last year", costs.EffectOnReturn.LastYear},
+	}
+	for _, r := range rows {
+		table.Append([]string{r.name, r.cost.Amount.Value.String(), r.cost.Amount.Unit, r.cost.Relative.String() + " %"})
+	}
+	table.Render()
+}
+
+func printQuoteTable(quote *comdirect.Quote) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "SIDE", "QUANTITY", "PRICE", "UNIT", "EXPIRY"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	table.Append([]string{
+		quote.QuoteID,
+		string(quote.Side),
+		quote.Quantity.Value.String(),
+		quote.Price.Value.String(),
+		quote.Price.Unit,
+		quote.ExpiryTimestamp.String(),
+	})
+	table.Render()
+}
@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"encoding/csv"
+	"log"
+	"os"
+
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/export"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var (
@@ -33,6 +36,15 @@ func position(cmd *cobra.Command, args []string) {
 		printPositionsTable(positions)
 	case "csv":
 		printPositionsCSV(positions)
+	case "ofx", "qif":
+		var transactions []comdirect.DepotTransaction
+		for t, err := range client.AllDepotTransactions(ctx, args[0]) {
+			if err != nil {
+				log.Fatalf("Failed to retrieve depot transactions: %s", err)
+			}
+			transactions = append(transactions, t)
+		}
+		printInvestmentStatement(export.NewInvestmentStatement(getDepot(client, args[0]), positions.Values, transactions))
 	default:
 		printPositionsTable(positions)
 	}
@@ -42,7 +54,7 @@ func printPositionsCSV(positions *comdirect.DepotPositions) {
 	table := csv.NewWriter(os.Stdout)
 	table.Write([]string{"POSITION ID", "WKN", "QUANTITY", "CURRENT PRICE", "PREVDAY %", "PURCHASE %", "PURCHASE", "CURRENT"})
 	for _, d := range positions.Values {
-		table.Write([]string{d.PositionId, d.Wkn, d.Quantity.Value, d.CurrentPrice.Price.Value, d.ProfitLossPrevDayRel, d.ProfitLossPurchaseRel, d.PurchaseValue.Value, d.CurrentValue.Value})
+		table.Write([]string{d.PositionId, d.Wkn, d.Quantity.Value.String(), d.CurrentPrice.Price.Value.String(), d.ProfitLossPrevDayRel, d.ProfitLossPurchaseRel, d.PurchaseValue.Value.String(), d.CurrentValue.Value.String()})
 	}
 	table.Flush()
 }
@@ -53,7 +65,7 @@ func printPositionsTable(depots *comdirect.DepotPositions) {
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 	table.SetCenterSeparator("|")
 	for _, d := range depots.Values {
-		table.Append([]string{d.PositionId, d.Wkn, d.Quantity.Value, d.CurrentPrice.Price.Value, d.ProfitLossPrevDayRel, d.ProfitLossPurchaseRel, d.PurchaseValue.Value, d.CurrentValue.Value})
+		table.Append([]string{d.PositionId, d.Wkn, d.Quantity.Value.String(), d.CurrentPrice.Price.Value.String(), d.ProfitLossPrevDayRel, d.ProfitLossPurchaseRel, d.PurchaseValue.Value.String(), d.CurrentValue.Value.String()})
 	}
 	table.Render()
 }
@@ -43,7 +43,7 @@ func printReportsCSV(reports *comdirect.Reports) {
 	table.Write(reportsHeader)
 	for _, r := range reports.Values {
 		var balance string
-		if r.Balance.Balance.Value == "" {
+		if r.Balance.Balance.Unit == "" {
 			balance = formatAmountValue(r.Balance.PrevDayValue)
 		} else {
 			balance = formatAmountValue(r.Balance.Balance)
@@ -61,7 +61,7 @@ func printReportsTable(reports *comdirect.Reports) {
 	table.SetCenterSeparator("|")
 	for _, r := range reports.Values {
 		var balance string
-		if r.Balance.Balance.Value == "" {
+		if r.Balance.Balance.Unit == "" {
 			balance = formatAmountValue(r.Balance.PrevDayValue)
 		} else {
 			balance = formatAmountValue(r.Balance.Balance)
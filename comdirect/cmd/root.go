@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"time"
 
-	"github.com/jsattler/go-comdirect/comdirect/keychain"
+	"github.com/jsattler/go-comdirect/pkg/analyze"
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/txsync"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +26,15 @@ var (
 	passwordFlag     string
 	clientIDFlag     string
 	clientSecretFlag string
+	tanTypeFlag      string
+	tanImageFlag     string
+	storeFlag        string
+	vaultFlag        string
+	ibanFlag         string
+	statusFlag       string
+	fromFlag         string
+	toFlag           string
+	rulesFlag        string
 
 	rootCmd = &cobra.Command{
 		Use:   "comdirect",
@@ -47,13 +56,88 @@ func init() {
 	documentCmd.Flags().StringVar(&folderFlag, "folder", "", "folder to save downloads")
 	documentCmd.Flags().BoolVar(&downloadFlag, "download", false, "whether to download documents")
 
+	transferCmd.Flags().StringVar(&creditorNameFlag, "name", "", "name of the creditor")
+	transferCmd.Flags().StringVar(&creditorIBANFlag, "iban", "", "IBAN of the creditor")
+	transferCmd.Flags().StringVar(&creditorBICFlag, "bic", "", "BIC of the creditor (optional)")
+	transferCmd.Flags().StringVar(&amountFlag, "amount", "", "amount in EUR, e.g. 100.50")
+	transferCmd.Flags().StringVar(&remittanceInfoFlag, "remittance-info", "", "remittance information (max. 140 characters)")
+	transferCmd.Flags().StringVar(&referenceFlag, "reference", "", "end-to-end reference (max. 35 characters)")
+	transferCmd.Flags().BoolVar(&instantFlag, "instant", false, "send a SEPA instant credit transfer")
+	_ = transferCmd.MarkFlagRequired("name")
+	_ = transferCmd.MarkFlagRequired("iban")
+	_ = transferCmd.MarkFlagRequired("amount")
+
+	transactionCmd.Flags().StringVarP(&formatFlag, "format", "f", "markdown", "output format (markdown, csv, json, camt053, mt940, ofx, qif, ledger, hledger or beancount)")
+	transactionCmd.Flags().StringVar(&rulesFlag, "rules", "", "YAML file mapping accounts, payees and IBANs to journal accounts")
 	transactionCmd.PersistentFlags().StringVar(&sinceFlag, "since", "", "Date of the earliest transaction date to retrieve in the form YYYY-MM-DD")
+	transactionCmd.PersistentFlags().StringVar(&statusFlag, "status", "", "booking status of the transactions (booked, notbooked or both)")
+	transactionCmd.PersistentFlags().StringVar(&fromFlag, "from", "", "earliest booking date of the transactions in the form YYYY-MM-DD")
+	transactionCmd.PersistentFlags().StringVar(&toFlag, "to", "", "latest booking date of the transactions in the form YYYY-MM-DD")
+	transactionCmd.Flags().StringVar(&categoriesFlag, "categories", defaultCategoriesPath(), "YAML file with the rules to categorize transactions")
+	transactionCmd.PersistentFlags().StringVar(&ibanFlag, "iban", "", "only show transactions with a creditor with this IBAN")
+
+	categorizeCmd.Flags().StringVar(&categoriesFlag, "categories", defaultCategoriesPath(), "YAML file with the rules to categorize transactions")
+	categorizeCmd.Flags().BoolVar(&summaryFlag, "summary", false, "print the totals per month and category instead of the transactions")
+	categorizeCmd.Flags().StringVar(&sinceFlag, "since", "", "Date of the earliest transaction date to retrieve in the form YYYY-MM-DD")
+	categorizeCmd.Flags().StringVar(&statusFlag, "status", "", "booking status of the transactions (booked, notbooked or both)")
+	categorizeCmd.Flags().StringVar(&fromFlag, "from", "", "earliest booking date of the transactions in the form YYYY-MM-DD")
+	categorizeCmd.Flags().StringVar(&toFlag, "to", "", "latest booking date of the transactions in the form YYYY-MM-DD")
+	categorizeCmd.Flags().StringVar(&ibanFlag, "iban", "", "only show transactions with a creditor with this IBAN")
+
+	cashflowCmd.Flags().StringVar(&fromFlag, "from", "", "first booking date in the form YYYY-MM-DD, defaults to the start of the last twelve months")
+	cashflowCmd.Flags().StringVar(&toFlag, "to", "", "last booking date in the form YYYY-MM-DD")
+	cashflowCmd.Flags().IntVar(&topFlag, "top", analyze.DefaultTopPayees, "number of top payees")
+
+	depotTransactionCmd.Flags().StringVarP(&formatFlag, "format", "f", "markdown", "output format (markdown, csv, json, ofx, qif, ledger, hledger or beancount)")
+	depotTransactionCmd.Flags().StringVar(&rulesFlag, "rules", "", "YAML file mapping accounts to journal accounts")
+
+	for _, c := range []*cobra.Command{orderCreateCmd, orderCostsCmd} {
+		c.Flags().StringVar(&sideFlag, "side", "", "BUY or SELL")
+		c.Flags().StringVar(&instrumentFlag, "instrument", "", "WKN, ISIN or ID of the instrument")
+		c.Flags().StringVar(&venueFlag, "venue", "", "ID or name of the venue, see the dimensions of the orders API")
+		c.Flags().StringVar(&quantityFlag, "quantity", "", "number of shares or nominal value")
+		c.Flags().StringVar(&limitFlag, "limit", "", "limit in EUR, places a market order if empty")
+		c.Flags().StringVar(&validityFlag, "validity", "", "last day of the order in the form YYYY-MM-DD, defaults to the current day")
+		_ = c.MarkFlagRequired("side")
+		_ = c.MarkFlagRequired("instrument")
+		_ = c.MarkFlagRequired("venue")
+		_ = c.MarkFlagRequired("quantity")
+	}
+	orderListCmd.Flags().StringVar(&statusFlag, "status", "", "status of the orders (open, executed, cancelled for all cancelled statuses, or a status like cancelled_user)")
+	orderListCmd.Flags().StringVar(&sideFlag, "side", "", "BUY or SELL")
+	orderListCmd.Flags().StringVar(&orderTypeFlag, "type", "", "order type, e.g. MARKET or LIMIT")
+	orderListCmd.Flags().StringVar(&venueFlag, "venue", "", "ID of the venue")
+	orderListCmd.Flags().StringVar(&instrumentFlag, "instrument", "", "WKN, ISIN or ID of the instrument")
+
+	orderQuoteCmd.Flags().StringVar(&sideFlag, "side", "", "BUY or SELL")
+	orderQuoteCmd.Flags().StringVar(&instrumentFlag, "instrument", "", "WKN, ISIN or ID of the instrument")
+	orderQuoteCmd.Flags().StringVar(&venueFlag, "venue", "", "ID or name of the venue, see the dimensions of the orders API")
+	orderQuoteCmd.Flags().StringVar(&quantityFlag, "quantity", "", "number of shares or nominal value")
+	orderQuoteCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "execute the quote without asking for confirmation")
+	for _, flag := range []string{"side", "instrument", "venue", "quantity"} {
+		_ = orderQuoteCmd.MarkFlagRequired(flag)
+	}
+	orderCreateCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "only pre-validate the order without placing it")
+	orderUpdateCmd.Flags().StringVar(&quantityFlag, "quantity", "", "new number of shares or nominal value")
+	orderUpdateCmd.Flags().StringVar(&limitFlag, "limit", "", "new limit in EUR")
+	orderUpdateCmd.Flags().StringVar(&validityFlag, "validity", "", "new last day of the order in the form YYYY-MM-DD")
+	_ = orderUpdateCmd.MarkFlagRequired("quantity")
+
+	syncCmd.Flags().StringVar(&stateFlag, "state", defaultStatePath(), "path of the sync state file")
+	syncCmd.Flags().StringVar(&fromFlag, "from", "", "earliest booking date of the first sync in the form YYYY-MM-DD")
+	syncCmd.Flags().IntVar(&overlapFlag, "overlap", txsync.DefaultOverlap, "number of days before the last booking date to request again")
+
+	archiveCmd.PersistentFlags().StringVar(&archiveFlag, "archive", defaultArchivePath(), "path of the archive database")
 
 	rootCmd.PersistentFlags().StringVar(&indexFlag, "index", "0", "page index")
 	rootCmd.PersistentFlags().StringVar(&countFlag, "count", "20", "page count")
-	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "f", "markdown", "output format (markdown, csv or json)")
+	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "f", "markdown", "output format (markdown, csv, json, ofx or qif)")
 	rootCmd.PersistentFlags().IntVarP(&timeoutFlag, "timeout", "t", 30, "timeout in seconds to validate session TAN (default 30sec)")
 	rootCmd.PersistentFlags().StringVar(&excludeFlag, "exclude", "", "exclude field from response")
+	rootCmd.PersistentFlags().StringVar(&tanTypeFlag, "tan-type", "", "preferred TAN type (push, photo or sms)")
+	rootCmd.PersistentFlags().StringVar(&tanImageFlag, "tan-image", "", "save the photoTAN image to this file instead of rendering it in the terminal")
+	rootCmd.PersistentFlags().StringVar(&storeFlag, "store", defaultStore(), "where to store credentials and session (keyring or vault), defaults to $"+storeEnv)
+	rootCmd.PersistentFlags().StringVar(&vaultFlag, "vault", defaultVaultPath(), "path of the encrypted vault file, defaults to $"+vaultEnv)
 
 	rootCmd.AddCommand(documentCmd)
 	rootCmd.AddCommand(depotCmd)
@@ -62,11 +146,36 @@ func init() {
 	rootCmd.AddCommand(logoutCmd)
 	rootCmd.AddCommand(reportCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(vaultCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(orderCmd)
 
 	accountCmd.AddCommand(balanceCmd)
 	accountCmd.AddCommand(transactionCmd)
+	accountCmd.AddCommand(transferCmd)
+	accountCmd.AddCommand(categorizeCmd)
 
 	depotCmd.AddCommand(positionCmd)
+	depotCmd.AddCommand(depotTransactionCmd)
+
+	vaultCmd.AddCommand(vaultInitCmd)
+	vaultCmd.AddCommand(vaultRotateCmd)
+	vaultCmd.AddCommand(vaultExportCmd)
+
+	analyzeCmd.AddCommand(cashflowCmd)
+
+	orderCmd.AddCommand(orderListCmd)
+	orderCmd.AddCommand(orderShowCmd)
+	orderCmd.AddCommand(orderCreateCmd)
+	orderCmd.AddCommand(orderCostsCmd)
+	orderCmd.AddCommand(orderQuoteCmd)
+	orderCmd.AddCommand(orderUpdateCmd)
+	orderCmd.AddCommand(orderDeleteCmd)
+
+	archiveCmd.AddCommand(archiveUpdateCmd)
+	archiveCmd.AddCommand(archiveQueryCmd)
 }
 
 func contextWithTimeout() (context.Context, context.CancelFunc) {
@@ -74,37 +183,34 @@ func contextWithTimeout() (context.Context, context.CancelFunc) {
 }
 
 func formatAmountValue(av comdirect.AmountValue) string {
-	value, err := strconv.ParseFloat(av.Value, 64)
-	if err != nil {
-		return ""
+	value := av.Value.Round(2)
+	if value.Sign() >= 0 {
+		return fmt.Sprintf("%5s", "+"+value.String())
 	}
-
-	return fmt.Sprintf("%+5.2f", value)
+	return fmt.Sprintf("%5s", value.String())
 }
 
 func initClient() *comdirect.Client {
-	authentication, err := keychain.RetrieveAuthentication()
-	if err != nil || authentication.IsExpired() {
-		// The session is expired, and we need to create a new session TAN
-		authOptions, err := keychain.RetrieveAuthOptions()
-		if err != nil {
-			fmt.Println("You're not logged in. Please use 'comdirect login' to log in")
-			os.Exit(1)
-		}
-		fmt.Println("Your session expired. Please open the comdirect photoTAN app to validate a new session.")
-		client := comdirect.NewWithAuthOptions(authOptions)
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
-		keychain.DeleteAuthentication()
-		authentication, err = client.Authenticate(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		err = keychain.StoreAuthentication(authentication)
-		if err != nil {
-			log.Fatal(err)
-		}
+	store := openStore()
+	authOptions, err := store.AuthOptions()
+	if err != nil {
+		fmt.Println("You're not logged in. Please use 'comdirect login' to log in")
+		os.Exit(1)
+	}
+	authOptions.TANHandler = newTerminalTANHandler()
+	client := comdirect.NewWithAuthOptions(authOptions,
+		comdirect.WithTokenStore(store.TokenStore()),
+		comdirect.WithAutoRefresh(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if _, err = client.Restore(ctx); err == nil {
 		return client
 	}
-	return comdirect.NewWithAuthentication(authentication)
+	// The session is expired, and we need to create a new session TAN
+	fmt.Println("Your session expired. Please validate a new session with your TAN procedure.")
+	if _, err = client.Authenticate(ctx); err != nil {
+		log.Fatal(err)
+	}
+	return client
 }
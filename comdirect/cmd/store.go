@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/jsattler/go-comdirect/comdirect/keychain"
+	"github.com/jsattler/go-comdirect/comdirect/vault"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"golang.org/x/term"
+)
+
+const (
+	keyringStore = "keyring"
+	vaultStore   = "vault"
+
+	storeEnv              = "COMDIRECT_STORE"
+	vaultEnv              = "COMDIRECT_VAULT"
+	vaultPassphraseEnv    = "COMDIRECT_VAULT_PASSPHRASE"
+	vaultNewPassphraseEnv = "COMDIRECT_VAULT_NEW_PASSPHRASE"
+)
+
+// credentialStore persists the credentials and the session of the CLI.
+type credentialStore interface {
+	StoreAuthOptions(options *comdirect.AuthOptions) error
+	AuthOptions() (*comdirect.AuthOptions, error)
+	DeleteAuthOptions() error
+	TokenStore() comdirect.TokenStore
+}
+
+// keychainStore adapts the keychain package to a credentialStore.
+type keychainStore struct{}
+
+func (keychainStore) StoreAuthOptions(options *comdirect.AuthOptions) error {
+	return keychain.StoreAuthOptions(options)
+}
+
+func (keychainStore) AuthOptions() (*comdirect.AuthOptions, error) {
+	return keychain.RetrieveAuthOptions()
+}
+
+func (keychainStore) DeleteAuthOptions() error {
+	keychain.DeleteAuthOptions()
+	return nil
+}
+
+func (keychainStore) TokenStore() comdirect.TokenStore {
+	return keychain.TokenStore()
+}
+
+// vaultCredentialStore adapts a vault.Vault to a credentialStore.
+type vaultCredentialStore struct {
+	*vault.Vault
+}
+
+func (s vaultCredentialStore) TokenStore() comdirect.TokenStore {
+	return s.Vault
+}
+
+// openStore returns the credentialStore selected with the --store flag.
+func openStore() credentialStore {
+	switch storeFlag {
+	case keyringStore:
+		return keychainStore{}
+	case vaultStore:
+		v := openVault()
+		if !v.Exists() {
+			fmt.Printf("The vault %s does not exist. Please use 'comdirect vault init' to create it\n", v.Path())
+			os.Exit(1)
+		}
+		return vaultCredentialStore{v}
+	default:
+		log.Fatalf("unknown store %q, use %s or %s", storeFlag, keyringStore, vaultStore)
+		return nil
+	}
+}
+
+// openVault returns the vault.Vault at the --vault path. The passphrase is read from
+// COMDIRECT_VAULT_PASSPHRASE or prompted for.
+func openVault() *vault.Vault {
+	return newVault(readPassphrase(vaultPassphraseEnv, "Vault passphrase: "))
+}
+
+func newVault(passphrase []byte) *vault.Vault {
+	return vault.New(vaultFlag, passphrase)
+}
+
+func readPassphrase(env string, prompt string) []byte {
+	if passphrase, ok := os.LookupEnv(env); ok {
+		return []byte(passphrase)
+	}
+	fmt.Print(prompt)
+	passphrase, err := term.ReadPassword(syscall.Stdin)
+	fmt.Println()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return passphrase
+}
+
+func defaultStore() string {
+	if store := os.Getenv(storeEnv); store != "" {
+		return store
+	}
+	return keyringStore
+}
+
+func defaultVaultPath() string {
+	if path := os.Getenv(vaultEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "comdirect-vault.json"
+	}
+	return filepath.Join(dir, "comdirect", "vault.json")
+}
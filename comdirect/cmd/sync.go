@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/txsync"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncHeader = []string{"EVENT", "ACCOUNT", "BOOKING DATE", "STATUS", "COUNTERPARTY", "VALUE", "UNIT"}
+	syncCmd    = &cobra.Command{
+		Use:   "sync [ACCOUNT_ID...]",
+		Short: "list the account transactions that were added, changed or removed since the last sync",
+		Long: "list the account transactions that were added, changed or removed since the last sync.\n" +
+			"All accounts are synchronized if no account ID is given. The state of the last sync is kept in the state file.",
+		Run: syncTransactions,
+	}
+	stateFlag   string
+	overlapFlag int
+)
+
+func syncTransactions(cmd *cobra.Command, args []string) {
+	var options []txsync.Option
+	if fromFlag != "" {
+		from, err := comdirect.ParseDate(fromFlag)
+		if err != nil {
+			log.Fatalf("Failed to parse date from command line: %s", err)
+		}
+		options = append(options, txsync.WithInitialDate(from))
+	}
+	options = append(options, txsync.WithOverlap(overlapFlag))
+
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+
+	accountIDs := args
+	if len(accountIDs) == 0 {
+		balances, err := client.Balances(ctx)
+		if err != nil {
+			log.Fatalf("Failed to retrieve accounts: %s", err)
+		}
+		for _, b := range balances.Values {
+			accountIDs = append(accountIDs, b.AccountId)
+		}
+	}
+
+	events, err := txsync.New(client, txsync.NewFileStore(stateFlag), options...).Sync(ctx, accountIDs...)
+	if err != nil {
+		log.Fatalf("Failed to sync transactions: %s", err)
+	}
+
+	switch formatFlag {
+	case "json":
+		printJSON(events)
+	case "csv":
+		printSyncCSV(events)
+	default:
+		printSyncTable(events)
+	}
+}
+
+func syncRow(e txsync.Event) []string {
+	t := e.Transaction
+	counterparty := t.Creditor.HolderName
+	if counterparty == "" {
+		counterparty = t.Remitter.HolderName
+	}
+	return []string{string(e.Type), e.AccountID, t.BookingDate.String(), t.BookingStatus, counterparty, formatAmountValue(t.Amount), t.Amount.Unit}
+}
+
+func printSyncCSV(events []txsync.Event) {
+	table := csv.NewWriter(os.Stdout)
+	table.Write(syncHeader)
+	for _, e := range events {
+		table.Write(syncRow(e))
+	}
+	table.Flush()
+}
+
+func printSyncTable(events []txsync.Event) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(syncHeader)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	for _, e := range events {
+		table.Append(syncRow(e))
+	}
+	table.Render()
+}
+
+func defaultStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "comdirect-sync.json"
+	}
+	return filepath.Join(dir, "comdirect", "sync.json")
+}
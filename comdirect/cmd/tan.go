@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+const maxImageWidth = 64
+
+// terminalTANHandler implements comdirect.TANHandler by rendering photoTAN images
+// in the terminal or saving them to a file and prompting for the TAN on stdin.
+type terminalTANHandler struct {
+	preferred comdirect.TANType
+	imagePath string
+	in        *bufio.Reader
+	out       io.Writer
+}
+
+func newTerminalTANHandler() *terminalTANHandler {
+	return &terminalTANHandler{
+		preferred: parseTANType(tanTypeFlag),
+		imagePath: tanImageFlag,
+		in:        bufio.NewReader(os.Stdin),
+		out:       os.Stdout,
+	}
+}
+
+func parseTANType(s string) comdirect.TANType {
+	switch strings.ToLower(s) {
+	case "":
+		return ""
+	case "push":
+		return comdirect.TANTypePush
+	case "photo":
+		return comdirect.TANTypePhoto
+	case "sms", "mobile":
+		return comdirect.TANTypeMobile
+	default:
+		return comdirect.TANType(strings.ToUpper(s))
+	}
+}
+
+func (h *terminalTANHandler) SelectTANType(available []comdirect.TANType) comdirect.TANType {
+	return h.preferred
+}
+
+func (h *terminalTANHandler) HandleTAN(ctx context.Context, challenge *comdirect.TANChallenge) (string, error) {
+	switch challenge.Type {
+	case comdirect.TANTypePhoto:
+		if err := h.showPhotoTAN(challenge.Image); err != nil {
+			return "", err
+		}
+	case comdirect.TANTypeMobile:
+		fmt.Fprintf(h.out, "A TAN was sent to %s\n", challenge.Challenge)
+	default:
+		fmt.Fprintf(h.out, "Challenge (%s): %s\n", challenge.Type, challenge.Challenge)
+	}
+
+	fmt.Fprint(h.out, "TAN: ")
+	tan, err := h.in.ReadString('\n')
+	if err != nil && tan == "" {
+		return "", err
+	}
+	return strings.TrimSpace(tan), nil
+}
+
+func (h *terminalTANHandler) showPhotoTAN(data []byte) error {
+	if h.imagePath != "" {
+		if err := os.WriteFile(h.imagePath, data, 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(h.out, "Scan the photoTAN image saved to %s with the comdirect photoTAN app\n", h.imagePath)
+		return nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode photoTAN image: %w", err)
+	}
+	fmt.Fprintln(h.out, "Scan the photoTAN image with the comdirect photoTAN app")
+	renderImage(h.out, img)
+	return nil
+}
+
+// renderImage prints an image using 24-bit ANSI colors and upper half blocks,
+// so that every character cell shows two vertically stacked pixels.
+func renderImage(w io.Writer, img image.Image) {
+	bounds := img.Bounds()
+	step := (bounds.Dx() + maxImageWidth - 1) / maxImageWidth
+	if step < 1 {
+		step = 1
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 * step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			tr, tg, tb, _ := img.At(x, y).RGBA()
+			br, bg, bb := tr, tg, tb
+			if y+step < bounds.Max.Y {
+				br, bg, bb, _ = img.At(x, y+step).RGBA()
+			}
+			fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr>>8, tg>>8, tb>>8, br>>8, bg>>8, bb>>8)
+		}
+		fmt.Fprintln(w, "\x1b[0m")
+	}
+}
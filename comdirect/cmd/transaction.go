@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"time"
+	"slices"
+	"strings"
 
+	"github.com/jsattler/go-comdirect/pkg/categorize"
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/export"
+	"github.com/jsattler/go-comdirect/pkg/iban"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
@@ -27,80 +30,209 @@ var (
 func transaction(cmd *cobra.Command, args []string) {
 	var transactions = &comdirect.AccountTransactions{}
 	var err error
+	if ibanFlag != "" {
+		if err = iban.Validate(ibanFlag); err != nil {
+			log.Fatalf("Invalid IBAN filter: %s", err)
+		}
+	}
+	query, err := transactionQuery()
+	if err != nil {
+		log.Fatalf("Invalid transaction filter: %s", err)
+	}
 	client := initClient()
-	ctx, cancel := contextWithTimeout()
-	defer cancel()
 
-	if sinceFlag == "" {
-		options := comdirect.EmptyOptions()
+	switch formatFlag {
+	case "camt053", "mt940", "ofx", "qif", "ledger", "hledger", "beancount":
+		printStatement(client, args[0])
+		return
+	}
+
+	transactions = fetchTransactions(client, args[0], query)
+	categorizer := loadCategorizer(cmd)
+
+	switch formatFlag {
+	case "json":
+		if categorizer != nil {
+			printJSON(categorizedTransactions{Paging: transactions.Paging, Values: categorizer.CategorizeAll(transactions.Values)})
+		} else {
+			printJSON(transactions)
+		}
+	case "markdown":
+		printTransactionTable(transactions, categorizer)
+	case "csv":
+		printTransactionCSV(transactions, categorizer)
+	default:
+		printTransactionTable(transactions, categorizer)
+	}
+}
+
+// fetchTransactions retrieves the transactions matching the query and the --iban flag. All pages are
+// retrieved if --since or --from is set, otherwise the page of --index and --count.
+func fetchTransactions(client *comdirect.Client, accountID string, query *comdirect.TransactionQuery) *comdirect.AccountTransactions {
+	var transactions *comdirect.AccountTransactions
+	if sinceFlag == "" && fromFlag == "" {
+		ctx, cancel := contextWithTimeout()
+		defer cancel()
+		options := query.Options()
 		options.Add(comdirect.PagingCountQueryKey, countFlag)
 		options.Add(comdirect.PagingFirstQueryKey, indexFlag)
-		transactions, err = client.Transactions(ctx, args[0], options)
-		if err != nil {
+		var err error
+		if transactions, err = client.Transactions(ctx, accountID, options); err != nil {
 			log.Fatalf("Failed to retrieve transactions: %s", err)
 		}
 	} else {
-		transactions = getTransactionsSince(sinceFlag, client, args[0])
+		transactions = getAllTransactions(client, accountID, query)
 	}
+	if ibanFlag != "" {
+		transactions.Values = filterTransactionsByIBAN(transactions.Values, ibanFlag)
+	}
+	return transactions
+}
 
-	switch formatFlag {
-	case "json":
-		printJSON(transactions)
-	case "markdown":
-		printTransactionTable(transactions)
-	case "csv":
-		printTransactionCSV(transactions)
+// transactionQuery creates the query for the --status, --from, --to and --since flags.
+// --since is a shorthand for booked transactions since the given date.
+func transactionQuery() (*comdirect.TransactionQuery, error) {
+	query := comdirect.NewTransactionQuery()
+	from := fromFlag
+	if sinceFlag != "" {
+		if from == "" {
+			from = sinceFlag
+		}
+		if statusFlag == "" {
+			query.BookingStatus(comdirect.BookingStatusBooked)
+		}
+	}
+
+	switch strings.ToUpper(statusFlag) {
+	case "":
+	case "BOOKED":
+		query.BookingStatus(comdirect.BookingStatusBooked)
+	case "NOTBOOKED", "PENDING":
+		query.BookingStatus(comdirect.BookingStatusNotBooked)
+	case "BOTH", "ALL":
+		query.BookingStatus(comdirect.BookingStatusBoth)
 	default:
-		printTransactionTable(transactions)
+		return nil, fmt.Errorf("unknown booking status %q, expected booked, notbooked or both", statusFlag)
 	}
+
+	fromDate, err := comdirect.ParseDate(from)
+	if err != nil {
+		return nil, err
+	}
+	toDate, err := comdirect.ParseDate(toFlag)
+	if err != nil {
+		return nil, err
+	}
+	if !fromDate.IsZero() && !toDate.IsZero() && toDate.Before(fromDate) {
+		return nil, fmt.Errorf("--to %s is before --from %s", toDate, fromDate)
+	}
+	return query.From(fromDate).To(toDate), nil
 }
 
-func getTransactionsSince(since string, client *comdirect.Client, accountID string) *comdirect.AccountTransactions {
-	const dateLayout = "2006-01-02"
+// getAllTransactions retrieves all transactions matching the query page by page.
+// The transactions are filtered by comdirect, so that only matching pages are requested.
+func getAllTransactions(client *comdirect.Client, accountID string, query *comdirect.TransactionQuery) *comdirect.AccountTransactions {
 	var transactions = &comdirect.AccountTransactions{}
 	ctx, cancel := contextWithTimeout()
 	defer cancel()
 
-	s, err := time.Parse(dateLayout, since)
+	options := query.Options()
+	options.Add(comdirect.PagingCountQueryKey, countFlag)
+	for t, err := range client.AllTransactions(ctx, accountID, options) {
+		if err != nil {
+			log.Fatalf("Failed to retrieve transactions: %s", err)
+		}
+		transactions.Values = append(transactions.Values, t)
+	}
+	transactions.Paging.Matches = len(transactions.Values)
+	return transactions
+}
+
+// printStatement prints the booked transactions as CAMT.053, MT940, OFX or QIF statement or as ledger,
+// hledger or beancount journal. The statement ends with the current balance, so all transactions since --from are
+// requested and limited to --to afterwards.
+func printStatement(client *comdirect.Client, accountID string) {
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	from := fromFlag
+	if from == "" {
+		from = sinceFlag
+	}
+	fromDate, err := comdirect.ParseDate(from)
 	if err != nil {
 		log.Fatalf("Failed to parse date from command line: %s", err)
 	}
-
-	page := 1
-	pageCount, err := strconv.Atoi(countFlag)
+	toDate, err := comdirect.ParseDate(toFlag)
 	if err != nil {
-		log.Fatalf("Can't convert string to int: %s", err)
+		log.Fatalf("Failed to parse date from command line: %s", err)
 	}
-	for {
-		options := comdirect.EmptyOptions()
-		options.Add(comdirect.PagingCountQueryKey, fmt.Sprint(pageCount*page))
-		options.Add(comdirect.PagingFirstQueryKey, fmt.Sprint(0))
 
-		transactions, err = client.Transactions(ctx, accountID, options)
-		if err != nil {
-			log.Fatalf("Error retrieving transactions: %e", err)
-		}
-
-		if len(transactions.Values) == 0 {
-			break
+	query := comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusBooked)
+	var transactions *comdirect.AccountTransactions
+	if fromDate.IsZero() {
+		options := query.Options()
+		options.Add(comdirect.PagingCountQueryKey, countFlag)
+		if transactions, err = client.Transactions(ctx, accountID, options); err != nil {
+			log.Fatalf("Failed to retrieve transactions: %s", err)
 		}
+	} else {
+		transactions = getAllTransactions(client, accountID, query.From(fromDate))
+	}
+	balance, err := client.Balance(ctx, accountID)
+	if err != nil {
+		log.Fatalf("Failed to retrieve balance: %s", err)
+	}
 
-		lastDate, err := time.Parse(dateLayout, transactions.Values[len(transactions.Values)-1].BookingDate)
-		if err != nil {
-			lastDate = time.Now()
+	statement, err := export.NewStatement(*balance, transactions.Values)
+	if err == nil && !toDate.IsZero() {
+		statement, err = statement.Until(toDate)
+	}
+	if err != nil {
+		log.Fatalf("Failed to create statement: %s", err)
+	}
+	switch formatFlag {
+	case "camt053":
+		err = export.WriteCAMT053(os.Stdout, statement)
+	case "mt940":
+		err = export.WriteMT940(os.Stdout, statement)
+	case "ofx":
+		err = export.WriteOFX(os.Stdout, statement)
+	case "qif":
+		err = export.WriteQIF(os.Stdout, statement)
+	default:
+		err = newJournal().WriteStatement(os.Stdout, statement)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 
+// newJournal creates a journal in the format of --format with the rules of --rules.
+func newJournal() *export.Journal {
+	var rules *export.Rules
+	if rulesFlag != "" {
+		var err error
+		if rules, err = export.LoadRules(rulesFlag); err != nil {
+			log.Fatalf("Failed to load rules: %s", err)
 		}
-		if transactions.Paging.Matches == len(transactions.Values) || lastDate.Before(s) {
-			break
-		}
-		page++
 	}
-
-	transactions, err = transactions.FilterSince(s)
+	journal, err := export.NewJournal(export.JournalFormat(formatFlag), rules)
 	if err != nil {
-		log.Fatalf("Error filtering transactions by date: %e", err)
+		log.Fatal(err)
 	}
-	return transactions
+	return journal
+}
+
+// filterTransactionsByIBAN returns the transactions with a creditor with the given IBAN.
+func filterTransactionsByIBAN(transactions []comdirect.AccountTransaction, s string) []comdirect.AccountTransaction {
+	want := iban.Normalize(s)
+	var filtered []comdirect.AccountTransaction
+	for _, t := range transactions {
+		if iban.Normalize(t.Creditor.Iban) == want {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
 }
 
 func printJSON(v interface{}) {
@@ -111,35 +243,45 @@ func printJSON(v interface{}) {
 	fmt.Println(string(b))
 }
 
-func printTransactionCSV(transactions *comdirect.AccountTransactions) {
+func printTransactionCSV(transactions *comdirect.AccountTransactions, categorizer *categorize.Categorizer) {
 	table := csv.NewWriter(os.Stdout)
-	table.Write(transactionHeader)
+	table.Write(transactionColumns(categorizer))
 	for _, t := range transactions.Values {
-		holderName := t.Remitter.HolderName
-		if len(holderName) > 30 {
-			holderName = holderName[:30]
-		} else if holderName == "" {
-			holderName = "N/A"
-		}
-		table.Write([]string{holderName, t.Creditor.HolderName, t.BookingDate, t.BookingStatus, t.TransactionType.Text, formatAmountValue(t.Amount), t.Amount.Unit})
+		table.Write(transactionRow(t, categorizer))
 	}
 	table.Flush()
 }
-func printTransactionTable(transactions *comdirect.AccountTransactions) {
+func printTransactionTable(transactions *comdirect.AccountTransactions, categorizer *categorize.Categorizer) {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(transactionHeader)
+	table.SetHeader(transactionColumns(categorizer))
 	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT})
 	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
 	table.SetCenterSeparator("|")
 	table.SetCaption(true, fmt.Sprintf("%d out of %d", len(transactions.Values), transactions.Paging.Matches))
 	for _, t := range transactions.Values {
-		holderName := t.Remitter.HolderName
-		if len(holderName) > 30 {
-			holderName = holderName[:30]
-		} else if holderName == "" {
-			holderName = "N/A"
-		}
-		table.Append([]string{holderName, t.Creditor.HolderName, t.BookingDate, t.BookingStatus, t.TransactionType.Text, formatAmountValue(t.Amount), t.Amount.Unit})
+		table.Append(transactionRow(t, categorizer))
 	}
 	table.Render()
 }
+
+// transactionColumns returns the transactionHeader and a CATEGORY column if transactions are categorized.
+func transactionColumns(categorizer *categorize.Categorizer) []string {
+	if categorizer == nil {
+		return transactionHeader
+	}
+	return append(slices.Clone(transactionHeader), "CATEGORY")
+}
+
+func transactionRow(t comdirect.AccountTransaction, categorizer *categorize.Categorizer) []string {
+	holderName := t.Remitter.HolderName
+	if len(holderName) > 30 {
+		holderName = holderName[:30]
+	} else if holderName == "" {
+		holderName = "N/A"
+	}
+	row := []string{holderName, t.Creditor.HolderName, t.BookingDate.String(), t.BookingStatus, t.TransactionType.Text, formatAmountValue(t.Amount), t.Amount.Unit}
+	if categorizer != nil {
+		row = append(row, categorizer.Categorize(t).Category)
+	}
+	return row
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	creditorNameFlag   string
+	creditorIBANFlag   string
+	creditorBICFlag    string
+	amountFlag         string
+	remittanceInfoFlag string
+	referenceFlag      string
+	instantFlag        bool
+
+	transferCmd = &cobra.Command{
+		Use:   "transfer ACCOUNT_ID",
+		Short: "send a SEPA credit transfer from an account",
+		Args:  cobra.ExactArgs(1),
+		Run:   transfer,
+	}
+)
+
+func transfer(cmd *cobra.Command, args []string) {
+	amount, err := comdirect.NewAmountValue(amountFlag, "EUR")
+	if err != nil {
+		log.Fatal(err)
+	}
+	creditor := comdirect.Creditor{HolderName: creditorNameFlag, Iban: creditorIBANFlag, Bic: creditorBICFlag}
+	request, err := comdirect.NewTransferRequest(creditor, amount, remittanceInfoFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	request.EndToEndReference = referenceFlag
+	if instantFlag {
+		request.Type = comdirect.TransferTypeInstant
+	}
+	if err = request.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	client := initClient()
+	ctx, cancel := contextWithTimeout()
+	defer cancel()
+	executed, err := client.Transfer(ctx, args[0], request)
+	if err != nil {
+		log.Fatalf("Failed to execute transfer: %s", err)
+	}
+
+	switch formatFlag {
+	case "json":
+		printJSON(executed)
+	default:
+		printTransferTable(executed)
+	}
+}
+
+func printTransferTable(transfer *comdirect.Transfer) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "STATUS", "CREDITOR", "IBAN", "VALUE", "UNIT"})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	table.Append([]string{
+		transfer.TransferID,
+		transfer.Status,
+		transfer.Creditor.HolderName,
+		iban.Format(transfer.Creditor.Iban),
+		transfer.Amount.Value.String(),
+		transfer.Amount.Unit,
+	})
+	table.Render()
+}
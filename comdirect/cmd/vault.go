@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	vaultCmd = &cobra.Command{
+		Use:   "vault",
+		Short: "manage the encrypted credential vault",
+		Long: "The vault stores credentials and the session in a passphrase-encrypted file instead of the OS keyring.\n" +
+			"Use it with --store=vault or COMDIRECT_STORE=vault, and set COMDIRECT_VAULT_PASSPHRASE for non-interactive usage.",
+	}
+
+	vaultInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "create a new vault",
+		Args:  cobra.NoArgs,
+		Run:   vaultInit,
+	}
+
+	vaultRotateCmd = &cobra.Command{
+		Use:   "rotate",
+		Short: "re-encrypt the vault with a new passphrase",
+		Args:  cobra.NoArgs,
+		Run:   vaultRotate,
+	}
+
+	vaultExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "print the decrypted content of the vault as JSON",
+		Args:  cobra.NoArgs,
+		Run:   vaultExport,
+	}
+)
+
+func vaultInit(cmd *cobra.Command, args []string) {
+	passphrase := readNewPassphrase(vaultPassphraseEnv)
+	v := newVault(passphrase)
+	if err := v.Init(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Successfully created vault %s\n", v.Path())
+}
+
+func vaultRotate(cmd *cobra.Command, args []string) {
+	v := openVault()
+	if err := v.Rotate(readNewPassphrase(vaultNewPassphraseEnv)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Successfully rotated the passphrase of vault %s\n", v.Path())
+}
+
+func vaultExport(cmd *cobra.Command, args []string) {
+	if err := openVault().Export(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readNewPassphrase reads a passphrase from env or prompts for it twice.
+func readNewPassphrase(env string) []byte {
+	if passphrase, ok := os.LookupEnv(env); ok {
+		return []byte(passphrase)
+	}
+	passphrase := readPassphrase(env, "New vault passphrase: ")
+	if len(passphrase) == 0 {
+		log.Fatal("the passphrase must not be empty")
+	}
+	if !bytes.Equal(passphrase, readPassphrase(env, "Repeat passphrase: ")) {
+		log.Fatal("the passphrases do not match")
+	}
+	return passphrase
+}
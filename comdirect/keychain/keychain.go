@@ -1,11 +1,9 @@
 package keychain
 
 import (
-	"encoding/json"
 	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/tokenstore"
 	"github.com/zalando/go-keyring"
-	"log"
-	"time"
 )
 
 const servicePrefix = "github.com.jsattler.go-comdirect."
@@ -24,28 +22,6 @@ func StoreAuthOptions(options *comdirect.AuthOptions) error {
 	return keyring.Set(servicePrefix+"clientSecret", user, options.ClientSecret)
 }
 
-func StoreAuthentication(authentication *comdirect.Authentication) error {
-
-	accessToken := authentication.AccessToken()
-	loginTime := authentication.ExpiryTime()
-	sessionID := authentication.SessionID()
-
-	byteJson, err := json.Marshal(accessToken)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	if err := keyring.Set(servicePrefix+"accessToken", user, string(byteJson)); err != nil {
-		return err
-	}
-
-	if err := keyring.Set(servicePrefix+"loginTime", user, loginTime.Format(time.RFC3339)); err != nil {
-		return err
-	}
-
-	return keyring.Set(servicePrefix+"sessionID", user, sessionID)
-}
-
 func RetrieveAuthOptions() (*comdirect.AuthOptions, error) {
 	username, err := keyring.Get(servicePrefix+"username", user)
 	if err != nil {
@@ -73,43 +49,9 @@ func RetrieveAuthOptions() (*comdirect.AuthOptions, error) {
 
 }
 
-func RetrieveAuthentication() (*comdirect.Authentication, error) {
-	loginTimeString, err := keyring.Get(servicePrefix+"loginTime", user)
-
-	if err != nil {
-		return nil, err
-	}
-
-	sessionID, err := keyring.Get(servicePrefix+"sessionID", user)
-	if err != nil {
-		return nil, err
-	}
-
-	accessTokenString, err := keyring.Get(servicePrefix+"accessToken", user)
-	if err != nil {
-		return nil, err
-	}
-
-	loginTime, err := time.Parse(time.RFC3339, loginTimeString)
-	if err != nil {
-		return nil, err
-	}
-
-	accessToken := comdirect.AccessToken{}
-
-	err = json.Unmarshal([]byte(accessTokenString), &accessToken)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return comdirect.NewAuthentication(accessToken, sessionID, loginTime), nil
-}
-
-func DeleteAuthentication() {
-	_ = keyring.Delete(servicePrefix+"loginTime", user)
-	_ = keyring.Delete(servicePrefix+"sessionID", user)
-	_ = keyring.Delete(servicePrefix+"accessToken", user)
+// TokenStore returns the comdirect.TokenStore that keeps the Authentication in the keyring.
+func TokenStore() comdirect.TokenStore {
+	return tokenstore.NewKeyring(servicePrefix+"authentication", user)
 }
 
 func DeleteAuthOptions() {
@@ -0,0 +1,224 @@
+// Package vault stores the credentials and the session of the comdirect CLI in a file encrypted
+// with a passphrase. It is an alternative to the keychain package on systems without an OS keyring,
+// e.g. headless containers and CI runners.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/jsattler/go-comdirect/internal/atomicfile"
+	"github.com/jsattler/go-comdirect/internal/secretbox"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+var (
+	// ErrNotInitialized is returned if the vault file does not exist.
+	ErrNotInitialized = errors.New("vault: not initialized")
+	// ErrExists is returned by Init if the vault file already exists.
+	ErrExists = errors.New("vault: already initialized")
+	// ErrNoAuthOptions is returned by AuthOptions if the vault holds no credentials.
+	ErrNoAuthOptions = errors.New("vault: no credentials stored")
+	// ErrWrongPassphrase is returned if the vault cannot be decrypted with the passphrase.
+	ErrWrongPassphrase = secretbox.ErrDecrypt
+)
+
+// Vault is a passphrase-encrypted file holding comdirect.AuthOptions and the current comdirect.Authentication.
+// Vault implements comdirect.TokenStore.
+type Vault struct {
+	mu         sync.Mutex
+	path       string
+	passphrase []byte
+}
+
+// contents is the plaintext stored in the vault file.
+type contents struct {
+	Credentials    *credentials              `json:"credentials,omitempty"`
+	Authentication *comdirect.Authentication `json:"authentication,omitempty"`
+}
+
+type credentials struct {
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// New returns a Vault for the file at path, encrypted with passphrase.
+func New(path string, passphrase []byte) *Vault {
+	return &Vault{path: path, passphrase: passphrase}
+}
+
+// Path returns the path of the vault file.
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Exists reports whether the vault file exists.
+func (v *Vault) Exists() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// Init creates an empty vault file. It returns ErrExists if the file already exists.
+func (v *Vault) Init() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.Exists() {
+		return ErrExists
+	}
+	return v.write(&contents{})
+}
+
+// AuthOptions returns the stored credentials.
+func (v *Vault) AuthOptions() (*comdirect.AuthOptions, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, err := v.read()
+	if err != nil {
+		return nil, err
+	}
+	if c.Credentials == nil {
+		return nil, ErrNoAuthOptions
+	}
+	return &comdirect.AuthOptions{
+		Username:     c.Credentials.Username,
+		Password:     c.Credentials.Password,
+		ClientId:     c.Credentials.ClientID,
+		ClientSecret: c.Credentials.ClientSecret,
+	}, nil
+}
+
+// StoreAuthOptions stores the credentials of options and replaces existing ones.
+func (v *Vault) StoreAuthOptions(options *comdirect.AuthOptions) error {
+	return v.update(func(c *contents) {
+		c.Credentials = &credentials{
+			Username:     options.Username,
+			Password:     options.Password,
+			ClientID:     options.ClientId,
+			ClientSecret: options.ClientSecret,
+		}
+	})
+}
+
+// DeleteAuthOptions removes the stored credentials.
+func (v *Vault) DeleteAuthOptions() error {
+	return v.update(func(c *contents) {
+		c.Credentials = nil
+	})
+}
+
+// Load returns the stored Authentication or comdirect.ErrNoAuthentication if there is none.
+func (v *Vault) Load(ctx context.Context) (*comdirect.Authentication, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, err := v.read()
+	if errors.Is(err, ErrNotInitialized) {
+		return nil, comdirect.ErrNoAuthentication
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Authentication == nil {
+		return nil, comdirect.ErrNoAuthentication
+	}
+	return c.Authentication, nil
+}
+
+// Save stores the Authentication and replaces an existing one.
+func (v *Vault) Save(ctx context.Context, auth *comdirect.Authentication) error {
+	return v.update(func(c *contents) {
+		c.Authentication = auth
+	})
+}
+
+// Delete removes the stored Authentication, the credentials are kept.
+func (v *Vault) Delete(ctx context.Context) error {
+	err := v.update(func(c *contents) {
+		c.Authentication = nil
+	})
+	if errors.Is(err, ErrNotInitialized) {
+		return nil
+	}
+	return err
+}
+
+// Rotate re-encrypts the vault with a new passphrase.
+func (v *Vault) Rotate(passphrase []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, err := v.read()
+	if err != nil {
+		return err
+	}
+	old := v.passphrase
+	v.passphrase = passphrase
+	if err = v.write(c); err != nil {
+		v.passphrase = old
+		return err
+	}
+	return nil
+}
+
+// Export writes the decrypted contents of the vault as JSON to w.
+func (v *Vault) Export(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, err := v.read()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// update reads the vault, applies fn and writes the result back.
+func (v *Vault) update(fn func(c *contents)) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, err := v.read()
+	if err != nil {
+		return err
+	}
+	fn(c)
+	return v.write(c)
+}
+
+// read decrypts the vault file. The caller must hold v.mu.
+func (v *Vault) read() (*contents, error) {
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotInitialized
+	}
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := secretbox.Open(data, v.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var c contents
+	if err = json.Unmarshal(plaintext, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// write encrypts c and atomically replaces the vault file. The caller must hold v.mu.
+func (v *Vault) write(c *contents) error {
+	plaintext, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	data, err := secretbox.Seal(plaintext, v.passphrase)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(v.path, data)
+}
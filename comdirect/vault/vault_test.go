@@ -0,0 +1,110 @@
+package vault_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/comdirect/vault"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v := vault.New(path, []byte("passphrase"))
+
+	if _, err := v.AuthOptions(); !errors.Is(err, vault.ErrNotInitialized) {
+		t.Fatalf("expected ErrNotInitialized, got: %v", err)
+	}
+	if err := v.Init(); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+	if err := v.Init(); !errors.Is(err, vault.ErrExists) {
+		t.Errorf("expected ErrExists, got: %v", err)
+	}
+	if _, err := v.AuthOptions(); !errors.Is(err, vault.ErrNoAuthOptions) {
+		t.Errorf("expected ErrNoAuthOptions, got: %v", err)
+	}
+
+	options := &comdirect.AuthOptions{Username: "user", Password: "pin", ClientId: "id", ClientSecret: "secret"}
+	if err := v.StoreAuthOptions(options); err != nil {
+		t.Fatalf("storing auth options failed: %s", err)
+	}
+	auth := comdirect.NewAuthentication(comdirect.AccessToken{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 599},
+		"session", time.Date(2024, 3, 28, 10, 30, 0, 0, time.UTC))
+	if err := v.Save(ctx, auth); err != nil {
+		t.Fatalf("saving authentication failed: %s", err)
+	}
+
+	reopened := vault.New(path, []byte("passphrase"))
+	stored, err := reopened.AuthOptions()
+	if err != nil || *stored != *options {
+		t.Errorf("expected %+v, got %+v (%v)", options, stored, err)
+	}
+	loaded, err := reopened.Load(ctx)
+	if err != nil || loaded.AccessToken() != auth.AccessToken() || !loaded.ExpiryTime().Equal(auth.ExpiryTime()) {
+		t.Errorf("expected %+v, got %+v (%v)", auth, loaded, err)
+	}
+
+	if err = reopened.Delete(ctx); err != nil {
+		t.Fatalf("deleting authentication failed: %s", err)
+	}
+	if _, err = v.Load(ctx); !errors.Is(err, comdirect.ErrNoAuthentication) {
+		t.Errorf("expected ErrNoAuthentication, got: %v", err)
+	}
+	if _, err = v.AuthOptions(); err != nil {
+		t.Errorf("expected credentials to be kept: %s", err)
+	}
+}
+
+func TestVault_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v := vault.New(path, []byte("old"))
+	if err := v.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.StoreAuthOptions(&comdirect.AuthOptions{Username: "user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Rotate([]byte("new")); err != nil {
+		t.Fatalf("rotate failed: %s", err)
+	}
+	if _, err := vault.New(path, []byte("old")).AuthOptions(); !errors.Is(err, vault.ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase for old passphrase, got: %v", err)
+	}
+	options, err := vault.New(path, []byte("new")).AuthOptions()
+	if err != nil || options.Username != "user" {
+		t.Errorf("expected vault to open with new passphrase: %+v (%v)", options, err)
+	}
+}
+
+func TestVault_Export(t *testing.T) {
+	v := vault.New(filepath.Join(t.TempDir(), "vault.json"), []byte("passphrase"))
+	if err := v.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.StoreAuthOptions(&comdirect.AuthOptions{Username: "user", ClientId: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := v.Export(&buf); err != nil {
+		t.Fatalf("export failed: %s", err)
+	}
+	var exported struct {
+		Credentials struct {
+			Username string `json:"username"`
+			ClientID string `json:"clientId"`
+		} `json:"credentials"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported.Credentials.Username != "user" || exported.Credentials.ClientID != "id" {
+		t.Errorf("unexpected export: %s", buf.String())
+	}
+}
@@ -0,0 +1,102 @@
+// Package secretbox encrypts small payloads with a passphrase using argon2id and AES-256-GCM.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	version = 1
+	kdf     = "argon2id"
+
+	saltSize = 16
+	keySize  = 32
+
+	defaultTime    = 1
+	defaultMemory  = 64 * 1024
+	defaultThreads = 4
+	maxMemory      = 1024 * 1024
+)
+
+// ErrDecrypt is returned by Open if the passphrase is wrong or the data was tampered with.
+var ErrDecrypt = errors.New("secretbox: wrong passphrase or corrupted data")
+
+// box is the JSON envelope produced by Seal. The argon2id parameters are stored
+// alongside the ciphertext, so that they can be changed without breaking existing data.
+type box struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts plaintext with a key derived from passphrase and returns a JSON envelope.
+func Seal(plaintext []byte, passphrase []byte) ([]byte, error) {
+	b := box{
+		Version: version,
+		KDF:     kdf,
+		Time:    defaultTime,
+		Memory:  defaultMemory,
+		Threads: defaultThreads,
+		Salt:    make([]byte, saltSize),
+	}
+	if _, err := rand.Read(b.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := b.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	b.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(b.Nonce); err != nil {
+		return nil, err
+	}
+	b.Ciphertext = aead.Seal(nil, b.Nonce, plaintext, nil)
+	return json.Marshal(b)
+}
+
+// Open decrypts a JSON envelope created by Seal.
+func Open(data []byte, passphrase []byte) ([]byte, error) {
+	var b box
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("secretbox: invalid format: %w", err)
+	}
+	if b.Version != version || b.KDF != kdf {
+		return nil, fmt.Errorf("secretbox: unsupported version %d with kdf %q", b.Version, b.KDF)
+	}
+	aead, err := b.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, b.Nonce, b.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func (b *box) aead(passphrase []byte) (cipher.AEAD, error) {
+	if b.Time == 0 || b.Memory == 0 || b.Memory > maxMemory || b.Threads == 0 {
+		return nil, errors.New("secretbox: invalid key derivation parameters")
+	}
+	key := argon2.IDKey(passphrase, b.Salt, b.Time, b.Memory, b.Threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
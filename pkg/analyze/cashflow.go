@@ -0,0 +1,355 @@
+// Package analyze computes cash-flow statistics of comdirect account transactions: monthly income and expenses,
+// the top payees, recurring payments and the balance over time.
+package analyze
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/categorize"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+// DefaultTopPayees is the default number of payees in Report.TopPayees.
+const DefaultTopPayees = 10
+
+// minRecurring is the minimum number of payments to detect a recurring payment.
+const minRecurring = 3
+
+// ErrNoTransactions is returned by Cashflow if there are no booked transactions in the period.
+var ErrNoTransactions = errors.New("analyze: no booked transactions")
+
+// Report is the cash flow of an account in a period.
+type Report struct {
+	AccountID string         `json:"accountId"`
+	From      comdirect.Date `json:"from"`
+	To        comdirect.Date `json:"to"`
+	// OpeningBalance and ClosingBalance are the balances before the first and after the last day of the period.
+	OpeningBalance comdirect.AmountValue `json:"openingBalance"`
+	ClosingBalance comdirect.AmountValue `json:"closingBalance"`
+	Income         comdirect.AmountValue `json:"income"`
+	Expenses       comdirect.AmountValue `json:"expenses"`
+	// Months are the calendar months of the period, oldest first.
+	Months []Month `json:"months"`
+	// TopPayees are the payees with the highest expenses, highest first.
+	TopPayees []Payee `json:"topPayees"`
+	// Recurring are the payments and receipts that recur at regular intervals.
+	Recurring []Recurring `json:"recurring"`
+	// Balances are the balances at the end of each day with transactions, oldest first.
+	Balances []Balance `json:"balances"`
+}
+
+// Month is the cash flow of a calendar month.
+type Month struct {
+	Month          string                `json:"month"`
+	Count          int                   `json:"count"`
+	Income         comdirect.AmountValue `json:"income"`
+	Expenses       comdirect.AmountValue `json:"expenses"`
+	Net            comdirect.AmountValue `json:"net"`
+	OpeningBalance comdirect.AmountValue `json:"openingBalance"`
+	ClosingBalance comdirect.AmountValue `json:"closingBalance"`
+}
+
+// Payee is the sum of the payments to a payee.
+type Payee struct {
+	Name   string                `json:"name"`
+	Count  int                   `json:"count"`
+	Amount comdirect.AmountValue `json:"amount"`
+}
+
+// Recurring is a payment or receipt with the same counterparty at regular intervals, e.g. rent, salary or
+// a direct debit of a subscription.
+type Recurring struct {
+	Payee      string `json:"payee"`
+	IBAN       string `json:"iban,omitempty"`
+	CreditorID string `json:"creditorId,omitempty"`
+	MandateID  string `json:"mandateId,omitempty"`
+	Count      int    `json:"count"`
+	// Interval is the median number of days between the payments, Period its name, e.g. monthly.
+	Interval int    `json:"interval"`
+	Period   string `json:"period"`
+	// Amount is the amount of the last payment, Total the sum of all payments.
+	Amount comdirect.AmountValue `json:"amount"`
+	Total  comdirect.AmountValue `json:"total"`
+	// Last is the date of the last payment, Next the expected date of the next one.
+	Last comdirect.Date `json:"last"`
+	Next comdirect.Date `json:"next"`
+}
+
+// Balance is the balance at the end of a day.
+type Balance struct {
+	Date    comdirect.Date        `json:"date"`
+	Balance comdirect.AmountValue `json:"balance"`
+}
+
+// Option configures Cashflow.
+type Option func(*config)
+
+type config struct {
+	from, to  comdirect.Date
+	topPayees int
+}
+
+// WithPeriod limits the report to the transactions booked from the first to the last date, both inclusive.
+// A zero date leaves the period open on that side.
+func WithPeriod(from comdirect.Date, to comdirect.Date) Option {
+	return func(c *config) {
+		c.from, c.to = from, to
+	}
+}
+
+// WithTopPayees sets the number of payees in Report.TopPayees. Defaults to DefaultTopPayees.
+func WithTopPayees(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.topPayees = n
+		}
+	}
+}
+
+// Cashflow analyzes the booked transactions of an account. The balances are reconstructed backwards from the
+// current balance, so the transactions must include all transactions booked since the start of the period,
+// including those after its end. Transactions that are not booked yet are skipped.
+func Cashflow(balance comdirect.AccountBalance, transactions []comdirect.AccountTransaction, options ...Option) (*Report, error) {
+	c := config{topPayees: DefaultTopPayees}
+	for _, option := range options {
+		option(&c)
+	}
+
+	var booked []comdirect.AccountTransaction
+	for _, t := range transactions {
+		if t.BookingStatus != "BOOKED" || t.BookingDate.IsZero() || !c.from.IsZero() && t.BookingDate.Before(c.from) {
+			continue
+		}
+		if t.Amount.Unit != balance.Balance.Unit {
+			return nil, fmt.Errorf("analyze: transaction %s in %s, expected %s", t.Reference, t.Amount.Unit, balance.Balance.Unit)
+		}
+		booked = append(booked, t)
+	}
+	slices.SortStableFunc(booked, func(a, b comdirect.AccountTransaction) int {
+		return a.BookingDate.Time().Compare(b.BookingDate.Time())
+	})
+
+	// balances[i] is the balance after booked[i], the last one is the current balance
+	unit := balance.Balance.Unit
+	balances := make([]comdirect.Decimal, len(booked))
+	current := balance.Balance.Value
+	for i := len(booked) - 1; i >= 0; i-- {
+		balances[i] = current
+		current = current.Sub(booked[i].Amount.Value)
+	}
+	opening := current
+
+	// transactions after the period are only needed to reconstruct the balances
+	end := len(booked)
+	if !c.to.IsZero() {
+		end, _ = slices.BinarySearchFunc(booked, c.to.AddDays(1), func(t comdirect.AccountTransaction, d comdirect.Date) int {
+			return t.BookingDate.Time().Compare(d.Time())
+		})
+	}
+	booked, balances = booked[:end], balances[:end]
+	if len(booked) == 0 {
+		return nil, ErrNoTransactions
+	}
+
+	report := &Report{
+		AccountID:      balance.AccountId,
+		From:           cmp.Or(c.from, booked[0].BookingDate),
+		To:             cmp.Or(c.to, booked[len(booked)-1].BookingDate),
+		OpeningBalance: amount(opening, unit),
+		ClosingBalance: amount(balances[len(balances)-1], unit),
+	}
+	var income, expenses comdirect.Decimal
+	for i, t := range booked {
+		if t.Amount.Value.Sign() > 0 {
+			income = income.Add(t.Amount.Value)
+		} else {
+			expenses = expenses.Add(t.Amount.Value)
+		}
+		if i == len(booked)-1 || !booked[i+1].BookingDate.Equal(t.BookingDate) {
+			report.Balances = append(report.Balances, Balance{Date: t.BookingDate, Balance: amount(balances[i], unit)})
+		}
+	}
+	report.Income, report.Expenses = amount(income, unit), amount(expenses, unit)
+	report.Months = months(booked, opening, unit)
+	report.TopPayees = topPayees(booked, c.topPayees, unit)
+	report.Recurring = recurring(booked, unit)
+	return report, nil
+}
+
+// months returns the cash flow of each month from the first to the last transaction.
+func months(booked []comdirect.AccountTransaction, opening comdirect.Decimal, unit string) []Month {
+	first := booked[0].BookingDate.Time()
+	last := booked[len(booked)-1].BookingDate.Time()
+	var result []Month
+	i := 0
+	for month := first.AddDate(0, 0, 1-first.Day()); !month.After(last); month = month.AddDate(0, 1, 0) {
+		key := month.Format(categorize.MonthLayout)
+		var income, expenses comdirect.Decimal
+		count := 0
+		for ; i < len(booked) && booked[i].BookingDate.Time().Format(categorize.MonthLayout) == key; i++ {
+			if v := booked[i].Amount.Value; v.Sign() > 0 {
+				income = income.Add(v)
+			} else {
+				expenses = expenses.Add(v)
+			}
+			count++
+		}
+		net := income.Add(expenses)
+		result = append(result, Month{
+			Month:          key,
+			Count:          count,
+			Income:         amount(income, unit),
+			Expenses:       amount(expenses, unit),
+			Net:            amount(net, unit),
+			OpeningBalance: amount(opening, unit),
+			ClosingBalance: amount(opening.Add(net), unit),
+		})
+		opening = opening.Add(net)
+	}
+	return result
+}
+
+// topPayees returns the n payees with the highest expenses.
+func topPayees(booked []comdirect.AccountTransaction, n int, unit string) []Payee {
+	sums := make(map[string]*Payee)
+	for _, t := range booked {
+		if t.Amount.Value.Sign() >= 0 {
+			continue
+		}
+		name := cmp.Or(categorize.Payee(t), t.TransactionType.Text)
+		payee, ok := sums[name]
+		if !ok {
+			payee = &Payee{Name: name, Amount: amount(comdirect.Decimal{}, unit)}
+			sums[name] = payee
+		}
+		payee.Count++
+		payee.Amount.Value = payee.Amount.Value.Add(t.Amount.Value)
+	}
+	payees := make([]Payee, 0, len(sums))
+	for _, payee := range sums {
+		payees = append(payees, *payee)
+	}
+	slices.SortFunc(payees, func(a, b Payee) int {
+		return cmp.Or(a.Amount.Value.Cmp(b.Amount.Value), cmp.Compare(a.Name, b.Name))
+	})
+	return payees[:min(n, len(payees))]
+}
+
+// recurring detects payments with the same counterparty at regular intervals. Direct debits are grouped by
+// creditor and mandate ID, transfers by IBAN or the name of the counterparty.
+func recurring(booked []comdirect.AccountTransaction, unit string) []Recurring {
+	groups := make(map[string][]comdirect.AccountTransaction)
+	var keys []string
+	for _, t := range booked {
+		key := recurringKey(t)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], t)
+	}
+
+	var result []Recurring
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < minRecurring {
+			continue
+		}
+		intervals := make([]int, 0, len(group)-1)
+		for i := 1; i < len(group); i++ {
+			intervals = append(intervals, days(group[i-1].BookingDate, group[i].BookingDate))
+		}
+		interval, ok := regularInterval(intervals)
+		if !ok {
+			continue
+		}
+		var total comdirect.Decimal
+		for _, t := range group {
+			total = total.Add(t.Amount.Value)
+		}
+		last := group[len(group)-1]
+		result = append(result, Recurring{
+			Payee:      cmp.Or(categorize.Payee(last), last.TransactionType.Text),
+			IBAN:       last.Creditor.Iban,
+			CreditorID: last.DirectDebitCreditorID,
+			MandateID:  last.DirectDebitMandateID,
+			Count:      len(group),
+			Interval:   interval,
+			Period:     period(interval),
+			Amount:     last.Amount,
+			Total:      amount(total, unit),
+			Last:       last.BookingDate,
+			Next:       last.BookingDate.AddDays(interval),
+		})
+	}
+	slices.SortStableFunc(result, func(a, b Recurring) int {
+		return a.Amount.Value.Cmp(b.Amount.Value)
+	})
+	return result
+}
+
+func recurringKey(t comdirect.AccountTransaction) string {
+	direction := "debit"
+	if t.Amount.Value.Sign() > 0 {
+		direction = "credit"
+	}
+	switch {
+	case t.DirectDebitMandateID != "":
+		return direction + "|mandate|" + t.DirectDebitCreditorID + "|" + t.DirectDebitMandateID
+	case t.Creditor.Iban != "":
+		return direction + "|iban|" + iban.Normalize(t.Creditor.Iban)
+	}
+	return direction + "|payee|" + strings.ToLower(categorize.Payee(t))
+}
+
+// regularInterval returns the median of the intervals in days, if all intervals deviate from it by at most
+// three days or 10 percent.
+func regularInterval(intervals []int) (int, bool) {
+	sorted := slices.Clone(intervals)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+	if median < 5 {
+		return 0, false
+	}
+	tolerance := max(3, median/10)
+	for _, interval := range intervals {
+		if interval < median-tolerance || interval > median+tolerance {
+			return 0, false
+		}
+	}
+	return median, true
+}
+
+// period returns the name of an interval in days, e.g. monthly.
+func period(interval int) string {
+	switch {
+	case interval >= 6 && interval <= 8:
+		return "weekly"
+	case interval >= 13 && interval <= 15:
+		return "biweekly"
+	case interval >= 27 && interval <= 33:
+		return "monthly"
+	case interval >= 85 && interval <= 97:
+		return "quarterly"
+	case interval >= 175 && interval <= 190:
+		return "half-yearly"
+	case interval >= 355 && interval <= 375:
+		return "yearly"
+	}
+	return fmt.Sprintf("every %d days", interval)
+}
+
+// days returns the number of calendar days from a to b.
+func days(a comdirect.Date, b comdirect.Date) int {
+	ta, tb := a.Time(), b.Time()
+	// dates are in Location, so round to whole days across daylight saving time changes
+	return int((tb.Sub(ta).Hours() + 12) / 24)
+}
+
+func amount(d comdirect.Decimal, unit string) comdirect.AmountValue {
+	return comdirect.AmountValue{Value: d, Unit: unit}
+}
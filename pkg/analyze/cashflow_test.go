@@ -0,0 +1,80 @@
+package analyze_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/analyze"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestCashflow(t *testing.T) {
+	fixtures := comdirecttest.DefaultFixtures()
+	report, err := analyze.Cashflow(fixtures.Balances[0], fixtures.Transactions[comdirecttest.AccountID], analyze.WithTopPayees(2))
+	if err != nil {
+		t.Fatalf("failed to analyze transactions: %s", err)
+	}
+
+	if report.OpeningBalance.String() != "741.25 EUR" || report.ClosingBalance.String() != "2500.25 EUR" {
+		t.Errorf("unexpected balances %s and %s", report.OpeningBalance, report.ClosingBalance)
+	}
+	if report.Income.String() != "10800 EUR" || report.Expenses.String() != "-9041.00 EUR" {
+		t.Errorf("unexpected income %s and expenses %s", report.Income, report.Expenses)
+	}
+	if len(report.Months) != 3 || report.Months[0].Month != "2024-01" || report.Months[2].Month != "2024-03" {
+		t.Fatalf("expected the months 2024-01 to 2024-03, got %+v", report.Months)
+	}
+	march := report.Months[2]
+	if march.Count != 10 || march.Net.String() != "833.60 EUR" || march.ClosingBalance.String() != "2500.25 EUR" {
+		t.Errorf("unexpected cash flow in 2024-03: %+v", march)
+	}
+	if !report.Months[1].ClosingBalance.Value.Equal(march.OpeningBalance.Value) {
+		t.Errorf("expected the opening balance of a month to be the closing balance of the previous month")
+	}
+
+	if len(report.TopPayees) != 2 || report.TopPayees[0].Name != "Hausverwaltung Müller" || report.TopPayees[0].Amount.String() != "-8500 EUR" {
+		t.Errorf("unexpected top payees: %+v", report.TopPayees)
+	}
+
+	if len(report.Recurring) != 3 {
+		t.Fatalf("expected 3 recurring payments, got %+v", report.Recurring)
+	}
+	rent := report.Recurring[0]
+	if rent.Payee != "Hausverwaltung Müller" || rent.Count != 10 || rent.Interval != 9 || rent.Period != "every 9 days" {
+		t.Errorf("unexpected recurring payment: %+v", rent)
+	}
+	if !rent.Next.Equal(comdirect.NewDate(2024, time.April, 3)) {
+		t.Errorf("expected next payment on 2024-04-03, got %s", rent.Next)
+	}
+	if groceries := report.Recurring[1]; groceries.MandateID != "M-0001" {
+		t.Errorf("expected direct debit with mandate, got %+v", groceries)
+	}
+
+	last := report.Balances[len(report.Balances)-1]
+	if len(report.Balances) != 29 || !last.Date.Equal(comdirect.NewDate(2024, time.March, 28)) || last.Balance.String() != "2500.25 EUR" {
+		t.Errorf("unexpected balances, last %+v", last)
+	}
+}
+
+func TestCashflow_Period(t *testing.T) {
+	fixtures := comdirecttest.DefaultFixtures()
+	report, err := analyze.Cashflow(fixtures.Balances[0], fixtures.Transactions[comdirecttest.AccountID],
+		analyze.WithPeriod(comdirect.NewDate(2024, time.March, 1), comdirect.NewDate(2024, time.March, 26)))
+	if err != nil {
+		t.Fatalf("failed to analyze transactions: %s", err)
+	}
+	if len(report.Months) != 1 || report.Months[0].Count != 9 {
+		t.Errorf("expected 9 transactions in 2024-03, got %+v", report.Months)
+	}
+	if report.ClosingBalance.String() != "2554.35 EUR" {
+		t.Errorf("expected closing balance 2554.35 EUR on 2024-03-26, got %s", report.ClosingBalance)
+	}
+
+	_, err = analyze.Cashflow(fixtures.Balances[0], fixtures.Transactions[comdirecttest.AccountID],
+		analyze.WithPeriod(comdirect.NewDate(2025, time.January, 1), comdirect.Date{}))
+	if !errors.Is(err, analyze.ErrNoTransactions) {
+		t.Errorf("expected ErrNoTransactions, got: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+// Package archive persists comdirect data in a local SQLite database, so that the history of accounts and
+// depots survives the retention limits of comdirect. The archive holds daily snapshots of account balances
+// and depot positions, booked account transactions, depot transactions, orders and postbox document metadata.
+//
+// Every table stores the complete value as JSON in the column data, next to the columns used for filtering.
+// The schema is migrated automatically when the archive is opened.
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // registers the pure Go SQLite driver "sqlite"
+)
+
+// Archive is a SQLite database holding comdirect data. It is safe for concurrent use.
+type Archive struct {
+	db *sql.DB
+}
+
+// Open opens the archive at path, creates it if it does not exist and migrates it to the latest schema.
+func Open(ctx context.Context, path string) (*Archive, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, a single connection avoids SQLITE_BUSY errors within the process.
+	db.SetMaxOpenConns(1)
+	a := &Archive{db: db}
+	if err = a.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("archive: failed to migrate %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// Close closes the database.
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Version returns the schema version of the archive.
+func (a *Archive) Version(ctx context.Context) (int, error) {
+	var version int
+	err := a.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	return version, err
+}
@@ -0,0 +1,151 @@
+package archive_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/archive"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestArchive_Update(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	a := openTestArchive(t)
+	ctx := context.Background()
+	client, err := server.AuthenticatedClient(ctx)
+	if err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+
+	result, err := a.Update(ctx, client)
+	if err != nil {
+		t.Fatalf("update failed: %s", err)
+	}
+	expected := archive.UpdateResult{Balances: 1, Transactions: 29, Positions: 1, DepotTransactions: 1, Documents: 1}
+	if *result != expected {
+		t.Errorf("expected %+v, got %+v", expected, *result)
+	}
+
+	result, err = a.Update(ctx, client)
+	if err != nil {
+		t.Fatalf("second update failed: %s", err)
+	}
+	if result.Balances != 0 || result.Transactions != 0 || result.DepotTransactions != 0 || result.Documents != 0 {
+		t.Errorf("expected no new rows, got %+v", *result)
+	}
+
+	transactions, err := a.Transactions(ctx, archive.TransactionFilter{
+		AccountID: comdirecttest.AccountID,
+		From:      comdirect.NewDate(2024, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("failed to query transactions: %s", err)
+	}
+	if len(transactions) != 10 || !transactions[0].BookingDate.Equal(comdirect.NewDate(2024, time.March, 28)) {
+		t.Errorf("expected 10 transactions since March, newest first, got %d", len(transactions))
+	}
+	if transactions[0].Amount.Value.String() != "-54.10" {
+		t.Errorf("expected archived transaction to round-trip, got: %+v", transactions[0])
+	}
+
+	snapshot, err := a.Positions(ctx, comdirecttest.DepotID, comdirect.Date{})
+	if err != nil {
+		t.Fatalf("failed to query positions: %s", err)
+	}
+	if snapshot == nil || !snapshot.Date.Equal(comdirect.DateOf(time.Now())) || len(snapshot.Positions) != 1 {
+		t.Errorf("unexpected position snapshot: %+v", snapshot)
+	}
+	if snapshot, _ = a.Positions(ctx, comdirecttest.DepotID, comdirect.NewDate(2000, time.January, 1)); snapshot != nil {
+		t.Errorf("expected no position snapshot in 2000, got: %+v", snapshot)
+	}
+
+	balances, err := a.Balances(ctx, comdirecttest.AccountID, comdirect.Date{}, comdirect.Date{})
+	if err != nil {
+		t.Fatalf("failed to query balances: %s", err)
+	}
+	if len(balances) != 1 || balances[0].Balance.Balance.Value.String() != "2500.25" {
+		t.Errorf("unexpected balance snapshots: %+v", balances)
+	}
+}
+
+func TestArchive_SaveTransactions(t *testing.T) {
+	a := openTestArchive(t)
+	ctx := context.Background()
+	booked := comdirect.AccountTransaction{
+		Reference:     "REF-1",
+		BookingStatus: "BOOKED",
+		BookingDate:   comdirect.NewDate(2024, time.March, 1),
+		Amount:        comdirect.AmountValue{Value: comdirect.MustParseDecimal("-1.50"), Unit: "EUR"},
+	}
+	pending := comdirect.AccountTransaction{BookingStatus: "NOTBOOKED", Amount: booked.Amount}
+	withoutReference := booked
+	withoutReference.Reference = ""
+
+	n, err := a.SaveTransactions(ctx, comdirecttest.AccountID, []comdirect.AccountTransaction{booked, pending, withoutReference})
+	if err != nil {
+		t.Fatalf("failed to save transactions: %s", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 booked transactions to be saved, got %d", n)
+	}
+	withoutReference.NewTransaction = true
+	if n, _ = a.SaveTransactions(ctx, comdirecttest.AccountID, []comdirect.AccountTransaction{booked, withoutReference}); n != 0 {
+		t.Errorf("expected archived transactions to be skipped, got %d", n)
+	}
+	last, err := a.LastBookingDate(ctx, comdirecttest.AccountID)
+	if err != nil || !last.Equal(booked.BookingDate) {
+		t.Errorf("unexpected last booking date %s: %v", last, err)
+	}
+}
+
+func TestArchive_Query(t *testing.T) {
+	a := openTestArchive(t)
+	ctx := context.Background()
+	if _, err := a.SaveDocuments(ctx, []comdirect.Document{{DocumentID: "1", Name: "Finanzreport"}}); err != nil {
+		t.Fatalf("failed to save documents: %s", err)
+	}
+
+	result, err := a.Query(ctx, "SELECT document_id, name FROM documents WHERE name = ?", "Finanzreport")
+	if err != nil {
+		t.Fatalf("query failed: %s", err)
+	}
+	if len(result.Columns) != 2 || len(result.Rows) != 1 || result.Rows[0][1] != "Finanzreport" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err = a.Query(ctx, "DELETE FROM documents"); err == nil {
+		t.Error("expected modifying query to be rejected")
+	}
+	if _, err = a.SaveDocuments(ctx, []comdirect.Document{{DocumentID: "2"}}); err != nil {
+		t.Errorf("expected archive to be writable after a query, got: %v", err)
+	}
+}
+
+func TestOpen_Migrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.db")
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		a, err := archive.Open(ctx, path)
+		if err != nil {
+			t.Fatalf("failed to open archive: %s", err)
+		}
+		if version, err := a.Version(ctx); err != nil || version != 1 {
+			t.Errorf("expected schema version 1, got %d: %v", version, err)
+		}
+		_ = a.Close()
+	}
+}
+
+func openTestArchive(t *testing.T) *archive.Archive {
+	t.Helper()
+	a, err := archive.Open(context.Background(), filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatalf("failed to open archive: %s", err)
+	}
+	t.Cleanup(func() { _ = a.Close() })
+	return a
+}
@@ -0,0 +1,106 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+)
+
+// migrations are the schema changes of the archive. The schema version stored in PRAGMA user_version is the
+// number of applied migrations. Migrations must never be changed once released, add a new one instead.
+var migrations = []string{
+	`CREATE TABLE balances (
+		account_id            TEXT NOT NULL,
+		date                  TEXT NOT NULL,
+		balance               TEXT NOT NULL,
+		available_cash_amount TEXT NOT NULL,
+		unit                  TEXT NOT NULL,
+		data                  TEXT NOT NULL,
+		PRIMARY KEY (account_id, date)
+	);
+	CREATE TABLE transactions (
+		account_id      TEXT NOT NULL,
+		id              TEXT NOT NULL,
+		reference       TEXT NOT NULL,
+		booking_date    TEXT NOT NULL,
+		valuta_date     TEXT NOT NULL,
+		amount          TEXT NOT NULL,
+		unit            TEXT NOT NULL,
+		counterparty    TEXT NOT NULL,
+		iban            TEXT NOT NULL,
+		remittance_info TEXT NOT NULL,
+		type            TEXT NOT NULL,
+		data            TEXT NOT NULL,
+		PRIMARY KEY (account_id, id)
+	);
+	CREATE INDEX transactions_booking_date ON transactions (account_id, booking_date);
+	CREATE TABLE positions (
+		depot_id       TEXT NOT NULL,
+		date           TEXT NOT NULL,
+		position_id    TEXT NOT NULL,
+		wkn            TEXT NOT NULL,
+		quantity       TEXT NOT NULL,
+		current_value  TEXT NOT NULL,
+		purchase_value TEXT NOT NULL,
+		unit           TEXT NOT NULL,
+		data           TEXT NOT NULL,
+		PRIMARY KEY (depot_id, date, position_id)
+	);
+	CREATE TABLE depot_transactions (
+		depot_id          TEXT NOT NULL,
+		transaction_id    TEXT NOT NULL,
+		wkn               TEXT NOT NULL,
+		direction         TEXT NOT NULL,
+		type              TEXT NOT NULL,
+		transaction_value TEXT NOT NULL,
+		unit              TEXT NOT NULL,
+		data              TEXT NOT NULL,
+		PRIMARY KEY (depot_id, transaction_id)
+	);
+	CREATE TABLE orders (
+		depot_id           TEXT NOT NULL,
+		order_id           TEXT NOT NULL,
+		creation_timestamp TEXT NOT NULL,
+		status             TEXT NOT NULL,
+		side               TEXT NOT NULL,
+		instrument_id      TEXT NOT NULL,
+		data               TEXT NOT NULL,
+		PRIMARY KEY (depot_id, order_id)
+	);
+	CREATE TABLE documents (
+		document_id   TEXT NOT NULL PRIMARY KEY,
+		name          TEXT NOT NULL,
+		date_creation TEXT NOT NULL,
+		mime_type     TEXT NOT NULL,
+		data          TEXT NOT NULL
+	);`,
+}
+
+// migrate applies all pending migrations, each in its own transaction.
+func (a *Archive) migrate(ctx context.Context) error {
+	version, err := a.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than the supported version %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := a.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not support parameters, the version is an integer.
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
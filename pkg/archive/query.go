@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// TransactionFilter selects archived account transactions. Zero values match all transactions.
+type TransactionFilter struct {
+	AccountID string
+	From      comdirect.Date
+	To        comdirect.Date
+}
+
+// BalanceSnapshot is the balance of an account on a day.
+type BalanceSnapshot struct {
+	Date    comdirect.Date
+	Balance comdirect.AccountBalance
+}
+
+// PositionSnapshot are the positions of a depot on a day.
+type PositionSnapshot struct {
+	Date      comdirect.Date
+	Positions []comdirect.DepotPosition
+}
+
+// Result is the result of a Query with all values formatted as strings.
+type Result struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Transactions returns the archived transactions matching filter, ordered by booking date, newest first.
+func (a *Archive) Transactions(ctx context.Context, filter TransactionFilter) ([]comdirect.AccountTransaction, error) {
+	query := `SELECT data FROM transactions WHERE 1 = 1`
+	var args []any
+	if filter.AccountID != "" {
+		query += ` AND account_id = ?`
+		args = append(args, filter.AccountID)
+	}
+	if !filter.From.IsZero() {
+		query += ` AND booking_date >= ?`
+		args = append(args, filter.From.String())
+	}
+	if !filter.To.IsZero() {
+		query += ` AND booking_date <= ?`
+		args = append(args, filter.To.String())
+	}
+	query += ` ORDER BY booking_date DESC, reference DESC`
+	return queryJSON[comdirect.AccountTransaction](ctx, a.db, query, args...)
+}
+
+// LastBookingDate returns the latest booking date of the archived transactions of an account,
+// or the zero Date if there are none.
+func (a *Archive) LastBookingDate(ctx context.Context, accountID string) (comdirect.Date, error) {
+	var last sql.NullString
+	err := a.db.QueryRowContext(ctx, `SELECT MAX(booking_date) FROM transactions WHERE account_id = ?`, accountID).Scan(&last)
+	if err != nil {
+		return comdirect.Date{}, err
+	}
+	return comdirect.ParseDate(last.String)
+}
+
+// Balances returns the balance snapshots of an account between from and to, oldest first.
+// A zero from or to is not limiting.
+func (a *Archive) Balances(ctx context.Context, accountID string, from comdirect.Date, to comdirect.Date) ([]BalanceSnapshot, error) {
+	rows, err := a.db.QueryContext(ctx, `SELECT date, data FROM balances
+		WHERE account_id = ? AND (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date`, accountID, from.String(), from.String(), to.String(), to.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var snapshots []BalanceSnapshot
+	for rows.Next() {
+		var date string
+		var data []byte
+		if err = rows.Scan(&date, &data); err != nil {
+			return nil, err
+		}
+		snapshot := BalanceSnapshot{}
+		if snapshot.Date, err = comdirect.ParseDate(date); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &snapshot.Balance); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// Positions returns the latest snapshot of the positions of a depot on or before date, or nil if there is none.
+// A zero date returns the latest snapshot.
+func (a *Archive) Positions(ctx context.Context, depotID string, date comdirect.Date) (*PositionSnapshot, error) {
+	var latest sql.NullString
+	err := a.db.QueryRowContext(ctx, `SELECT MAX(date) FROM positions WHERE depot_id = ? AND (? = '' OR date <= ?)`,
+		depotID, date.String(), date.String()).Scan(&latest)
+	if err != nil || !latest.Valid {
+		return nil, err
+	}
+	snapshot := &PositionSnapshot{}
+	if snapshot.Date, err = comdirect.ParseDate(latest.String); err != nil {
+		return nil, err
+	}
+	snapshot.Positions, err = queryJSON[comdirect.DepotPosition](ctx, a.db,
+		`SELECT data FROM positions WHERE depot_id = ? AND date = ? ORDER BY position_id`, depotID, latest.String)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// DepotTransactions returns the archived transactions of a depot.
+func (a *Archive) DepotTransactions(ctx context.Context, depotID string) ([]comdirect.DepotTransaction, error) {
+	return queryJSON[comdirect.DepotTransaction](ctx, a.db,
+		`SELECT data FROM depot_transactions WHERE depot_id = ? ORDER BY transaction_id`, depotID)
+}
+
+// Orders returns the archived orders of a depot, newest first.
+func (a *Archive) Orders(ctx context.Context, depotID string) ([]comdirect.Order, error) {
+	return queryJSON[comdirect.Order](ctx, a.db,
+		`SELECT data FROM orders WHERE depot_id = ? ORDER BY creation_timestamp DESC`, depotID)
+}
+
+// Documents returns the archived postbox document metadata, newest first.
+func (a *Archive) Documents(ctx context.Context) ([]comdirect.Document, error) {
+	return queryJSON[comdirect.Document](ctx, a.db, `SELECT data FROM documents ORDER BY date_creation DESC, document_id`)
+}
+
+// Query runs a read-only SQL query against the archive, e.g. to aggregate transactions.
+// Statements that modify the archive are rejected.
+func (a *Archive) Query(ctx context.Context, query string, args ...any) (*Result, error) {
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, `PRAGMA query_only = ON`); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(context.Background(), `PRAGMA query_only = OFF`)
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := &Result{}
+	if result.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		values := make([]any, len(result.Columns))
+		pointers := make([]any, len(values))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = format(v)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// queryJSON runs a query returning a single data column and decodes every row as T.
+func queryJSON[T any](ctx context.Context, db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []T
+	for rows.Next() {
+		var data []byte
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var v T
+		if err = json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,204 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// SaveBalances stores the balances as snapshot of the given date, replaces an existing snapshot of that date and
+// returns the number of new or changed balances.
+func (a *Archive) SaveBalances(ctx context.Context, date comdirect.Date, balances []comdirect.AccountBalance) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		count := 0
+		for _, b := range balances {
+			data, err := json.Marshal(b)
+			if err != nil {
+				return 0, err
+			}
+			n, err := exec(ctx, tx, `INSERT INTO balances (account_id, date, balance, available_cash_amount, unit, data)
+				VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (account_id, date) DO UPDATE SET
+					balance = excluded.balance, available_cash_amount = excluded.available_cash_amount,
+					unit = excluded.unit, data = excluded.data
+				WHERE data != excluded.data`,
+				b.AccountId, date.String(), b.Balance.Value.String(), b.AvailableCashAmount.Value.String(), b.Balance.Unit, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// SaveTransactions stores the booked transactions of an account and returns the number of new transactions.
+// Transactions that are not booked yet are skipped, as they may still change. Booked transactions are identified
+// by their reference, transactions that were already archived are kept unchanged.
+func (a *Archive) SaveTransactions(ctx context.Context, accountID string, transactions []comdirect.AccountTransaction) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		count := 0
+		for _, t := range transactions {
+			if t.BookingStatus != "BOOKED" || t.BookingDate.IsZero() {
+				continue
+			}
+			data, err := json.Marshal(t)
+			if err != nil {
+				return 0, err
+			}
+			counterparty, iban := t.Creditor.HolderName, t.Creditor.Iban
+			if counterparty == "" {
+				counterparty = t.Remitter.HolderName
+			}
+			n, err := exec(ctx, tx, `INSERT INTO transactions (account_id, id, reference, booking_date, valuta_date, amount, unit,
+					counterparty, iban, remittance_info, type, data)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (account_id, id) DO NOTHING`,
+				accountID, transactionID(t), t.Reference, t.BookingDate.String(), t.ValutaDate.String(), t.Amount.Value.String(),
+				t.Amount.Unit, counterparty, iban, t.RemittanceInfo, t.TransactionType.Key, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// SavePositions stores the positions of a depot as snapshot of the given date, replaces an existing snapshot of
+// that date and returns the number of stored positions.
+func (a *Archive) SavePositions(ctx context.Context, depotID string, date comdirect.Date, positions []comdirect.DepotPosition) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM positions WHERE depot_id = ? AND date = ?`, depotID, date.String()); err != nil {
+			return 0, err
+		}
+		count := 0
+		for _, p := range positions {
+			data, err := json.Marshal(p)
+			if err != nil {
+				return 0, err
+			}
+			n, err := exec(ctx, tx, `INSERT INTO positions (depot_id, date, position_id, wkn, quantity, current_value, purchase_value, unit, data)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				depotID, date.String(), p.PositionId, p.Wkn, p.Quantity.Value.String(), p.CurrentValue.Value.String(),
+				p.PurchaseValue.Value.String(), p.CurrentValue.Unit, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// SaveDepotTransactions stores the transactions of a depot and returns the number of new transactions.
+func (a *Archive) SaveDepotTransactions(ctx context.Context, depotID string, transactions []comdirect.DepotTransaction) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		count := 0
+		for _, t := range transactions {
+			data, err := json.Marshal(t)
+			if err != nil {
+				return 0, err
+			}
+			n, err := exec(ctx, tx, `INSERT INTO depot_transactions (depot_id, transaction_id, wkn, direction, type, transaction_value, unit, data)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (depot_id, transaction_id) DO NOTHING`,
+				depotID, t.TransactionID, t.Instrument.WKN, t.TransactionDirection, t.TransactionType,
+				t.TransactionValue.Value.String(), t.TransactionValue.Unit, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// SaveOrders stores the orders and returns the number of new or changed orders, e.g. orders that were executed.
+func (a *Archive) SaveOrders(ctx context.Context, orders []comdirect.Order) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		count := 0
+		for _, o := range orders {
+			data, err := json.Marshal(o)
+			if err != nil {
+				return 0, err
+			}
+			n, err := exec(ctx, tx, `INSERT INTO orders (depot_id, order_id, creation_timestamp, status, side, instrument_id, data)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (depot_id, order_id) DO UPDATE SET
+					status = excluded.status, data = excluded.data
+				WHERE data != excluded.data`,
+				o.DepotID, o.OrderID, o.CreationTimestamp.String(), o.OrderStatus, o.Side, o.InstrumentID, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// SaveDocuments stores the metadata of postbox documents and returns the number of new or changed documents.
+// The content of the documents is not archived.
+func (a *Archive) SaveDocuments(ctx context.Context, documents []comdirect.Document) (int, error) {
+	return a.write(ctx, func(tx *sql.Tx) (int, error) {
+		count := 0
+		for _, d := range documents {
+			data, err := json.Marshal(d)
+			if err != nil {
+				return 0, err
+			}
+			n, err := exec(ctx, tx, `INSERT INTO documents (document_id, name, date_creation, mime_type, data)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (document_id) DO UPDATE SET
+					name = excluded.name, data = excluded.data
+				WHERE data != excluded.data`,
+				d.DocumentID, d.Name, d.DateCreation.String(), d.MimeType, data)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	})
+}
+
+// write runs fn in a transaction and commits it if fn succeeds.
+func (a *Archive) write(ctx context.Context, fn func(tx *sql.Tx) (int, error)) (int, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	count, err := fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// exec executes a statement and returns the number of affected rows.
+func exec(ctx context.Context, tx *sql.Tx, query string, args ...any) (int, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// transactionID identifies a booked transaction by its reference, or by a hash of its content if it has none.
+// The NewTransaction flag is not part of the hash, as it changes with every login to the comdirect website.
+func transactionID(t comdirect.AccountTransaction) string {
+	if t.Reference != "" {
+		return t.Reference
+	}
+	t.NewTransaction = false
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
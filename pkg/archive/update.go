@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// DefaultOverlap is the number of days before the last archived booking date that are requested again by Update,
+// so that transactions booked with an earlier booking date are not missed.
+const DefaultOverlap = 7
+
+// UpdateResult holds the number of new or changed rows per table of an Update.
+type UpdateResult struct {
+	Balances          int `json:"balances"`
+	Transactions      int `json:"transactions"`
+	Positions         int `json:"positions"`
+	DepotTransactions int `json:"depotTransactions"`
+	Orders            int `json:"orders"`
+	Documents         int `json:"documents"`
+}
+
+// Update fetches the current data of all accounts, depots and the postbox and stores it in the archive.
+// Balances and positions are stored as snapshot of today. Only transactions booked since the last archived
+// booking date, minus DefaultOverlap days, are requested.
+func (a *Archive) Update(ctx context.Context, client *comdirect.Client) (*UpdateResult, error) {
+	result := &UpdateResult{}
+	today := comdirect.DateOf(time.Now())
+
+	balances, err := client.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if result.Balances, err = a.SaveBalances(ctx, today, balances.Values); err != nil {
+		return nil, err
+	}
+	for _, b := range balances.Values {
+		n, err := a.updateTransactions(ctx, client, b.AccountId)
+		if err != nil {
+			return nil, err
+		}
+		result.Transactions += n
+	}
+
+	for depot, err := range client.AllDepots(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if err = a.updateDepot(ctx, client, depot.DepotId, today, result); err != nil {
+			return nil, err
+		}
+	}
+
+	var documents []comdirect.Document
+	for document, err := range client.AllDocuments(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	if result.Documents, err = a.SaveDocuments(ctx, documents); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *Archive) updateTransactions(ctx context.Context, client *comdirect.Client, accountID string) (int, error) {
+	last, err := a.LastBookingDate(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	query := comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusBooked)
+	if !last.IsZero() {
+		query.From(last.AddDays(-DefaultOverlap))
+	}
+	var transactions []comdirect.AccountTransaction
+	for t, err := range client.AllTransactions(ctx, accountID, query.Options()) {
+		if err != nil {
+			return 0, err
+		}
+		transactions = append(transactions, t)
+	}
+	return a.SaveTransactions(ctx, accountID, transactions)
+}
+
+func (a *Archive) updateDepot(ctx context.Context, client *comdirect.Client, depotID string, today comdirect.Date, result *UpdateResult) error {
+	var positions []comdirect.DepotPosition
+	for p, err := range client.AllDepotPositions(ctx, depotID) {
+		if err != nil {
+			return err
+		}
+		positions = append(positions, p)
+	}
+	n, err := a.SavePositions(ctx, depotID, today, positions)
+	if err != nil {
+		return err
+	}
+	result.Positions += n
+
+	var transactions []comdirect.DepotTransaction
+	for t, err := range client.AllDepotTransactions(ctx, depotID) {
+		if err != nil {
+			return err
+		}
+		transactions = append(transactions, t)
+	}
+	if n, err = a.SaveDepotTransactions(ctx, depotID, transactions); err != nil {
+		return err
+	}
+	result.DepotTransactions += n
+
+	var orders []comdirect.Order
+	for o, err := range client.AllOrders(ctx, depotID) {
+		if err != nil {
+			return err
+		}
+		orders = append(orders, o)
+	}
+	if n, err = a.SaveOrders(ctx, orders); err != nil {
+		return err
+	}
+	result.Orders += n
+	return nil
+}
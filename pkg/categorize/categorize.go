@@ -0,0 +1,232 @@
+// Package categorize assigns categories and tags to comdirect account transactions based on rules,
+// e.g. to break down the monthly spending.
+//
+// Rules are usually loaded from a YAML file:
+//
+//	default: Other
+//	rules:
+//	  - payee: rewe|edeka|supermarkt
+//	    category: Groceries
+//	  - creditorId: DE98ZZZ09999999999
+//	    category: Insurance
+//	    tags: [contract]
+//	  - direction: debit
+//	    min: 500
+//	    tags: [large]
+package categorize
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/export"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCategory is the category of transactions that match no rule with a category.
+const DefaultCategory = "Uncategorized"
+
+// ErrInvalidRules is returned if the rules cannot be used, e.g. because of an invalid regular expression.
+var ErrInvalidRules = errors.New("categorize: invalid rules")
+
+// Direction is the direction of the amount of a transaction.
+type Direction string
+
+const (
+	// Debit matches payments, that is transactions with a negative amount.
+	Debit Direction = "debit"
+	// Credit matches receipts, that is transactions with a positive amount.
+	Credit Direction = "credit"
+)
+
+// Rule assigns a category and tags to the transactions matching all of its non-empty conditions.
+type Rule struct {
+	// Category is the category of matching transactions. Rules without category only add tags.
+	Category string `yaml:"category"`
+	// Tags are added to matching transactions.
+	Tags []string `yaml:"tags"`
+
+	// Payee is a regular expression matched case-insensitively against the name of the counterparty.
+	Payee string `yaml:"payee"`
+	// Remittance is a regular expression matched case-insensitively against the remittance info.
+	Remittance string `yaml:"remittance"`
+	// IBAN is the IBAN of the creditor.
+	IBAN string `yaml:"iban"`
+	// CreditorID is the SEPA creditor ID of a direct debit.
+	CreditorID string `yaml:"creditorId"`
+	// MandateID is the mandate ID of a direct debit.
+	MandateID string `yaml:"mandateId"`
+	// Type is the key of the transaction type, e.g. DIRECT_DEBIT.
+	Type string `yaml:"type"`
+	// Direction restricts the rule to debits or credits.
+	Direction Direction `yaml:"direction"`
+	// Min and Max are the inclusive bounds of the absolute amount of the transaction.
+	Min *comdirect.Decimal `yaml:"min"`
+	Max *comdirect.Decimal `yaml:"max"`
+
+	payee      *regexp.Regexp
+	remittance *regexp.Regexp
+}
+
+// Rules are the rules of a Categorizer.
+type Rules struct {
+	// Default is the category of transactions that match no rule with a category, defaults to DefaultCategory.
+	Default string `yaml:"default"`
+	// Rules are matched in order. The first matching rule with a category determines the category,
+	// the tags of all matching rules are added.
+	Rules []Rule `yaml:"rules"`
+}
+
+// Transaction is an AccountTransaction annotated with its category and tags.
+type Transaction struct {
+	comdirect.AccountTransaction
+	Category string   `json:"category"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Categorizer assigns categories and tags to transactions. It is safe for concurrent use.
+type Categorizer struct {
+	defaultCategory string
+	rules           []Rule
+}
+
+// Load creates a Categorizer from the YAML rules file at path.
+func Load(path string) (*Categorizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse creates a Categorizer from rules in YAML read from r. Unknown keys are rejected to detect typos.
+func Parse(r io.Reader) (*Categorizer, error) {
+	var rules Rules
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+	return New(rules)
+}
+
+// New creates a Categorizer from the rules.
+func New(rules Rules) (*Categorizer, error) {
+	c := &Categorizer{defaultCategory: cmp.Or(rules.Default, DefaultCategory), rules: slices.Clone(rules.Rules)}
+	for i := range c.rules {
+		if err := c.rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %w", ErrInvalidRules, i+1, err)
+		}
+	}
+	return c, nil
+}
+
+// Categorize returns the transaction annotated with its category and tags.
+func (c *Categorizer) Categorize(t comdirect.AccountTransaction) Transaction {
+	categorized := Transaction{AccountTransaction: t}
+	for _, rule := range c.rules {
+		if !rule.matches(t) {
+			continue
+		}
+		if categorized.Category == "" {
+			categorized.Category = rule.Category
+		}
+		for _, tag := range rule.Tags {
+			if !slices.Contains(categorized.Tags, tag) {
+				categorized.Tags = append(categorized.Tags, tag)
+			}
+		}
+	}
+	if categorized.Category == "" {
+		categorized.Category = c.defaultCategory
+	}
+	return categorized
+}
+
+// CategorizeAll categorizes the transactions and keeps their order.
+func (c *Categorizer) CategorizeAll(transactions []comdirect.AccountTransaction) []Transaction {
+	categorized := make([]Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		categorized = append(categorized, c.Categorize(t))
+	}
+	return categorized
+}
+
+func (r *Rule) compile() error {
+	if r.Category == "" && len(r.Tags) == 0 {
+		return errors.New("no category or tags")
+	}
+	if r.Payee == "" && r.Remittance == "" && r.IBAN == "" && r.CreditorID == "" && r.MandateID == "" &&
+		r.Type == "" && r.Direction == "" && r.Min == nil && r.Max == nil {
+		return errors.New("no condition")
+	}
+	switch r.Direction {
+	case "", Debit, Credit:
+	default:
+		return fmt.Errorf("unknown direction %q, expected debit or credit", r.Direction)
+	}
+	if r.IBAN != "" {
+		if err := iban.Validate(r.IBAN); err != nil {
+			return err
+		}
+		r.IBAN = iban.Normalize(r.IBAN)
+	}
+	if r.Min != nil && r.Max != nil && r.Min.Cmp(*r.Max) > 0 {
+		return fmt.Errorf("min %s is greater than max %s", r.Min, r.Max)
+	}
+	var err error
+	if r.payee, err = compileRegexp(r.Payee); err != nil {
+		return err
+	}
+	r.remittance, err = compileRegexp(r.Remittance)
+	return err
+}
+
+func (r *Rule) matches(t comdirect.AccountTransaction) bool {
+	amount := t.Amount.Value
+	switch {
+	case r.Direction == Debit && amount.Sign() >= 0, r.Direction == Credit && amount.Sign() <= 0:
+		return false
+	case r.Min != nil && amount.Abs().Cmp(*r.Min) < 0, r.Max != nil && amount.Abs().Cmp(*r.Max) > 0:
+		return false
+	case r.Type != "" && !strings.EqualFold(r.Type, t.TransactionType.Key):
+		return false
+	case r.IBAN != "" && r.IBAN != iban.Normalize(t.Creditor.Iban):
+		return false
+	case r.CreditorID != "" && !strings.EqualFold(r.CreditorID, t.DirectDebitCreditorID):
+		return false
+	case r.MandateID != "" && r.MandateID != t.DirectDebitMandateID:
+		return false
+	case r.payee != nil && !r.payee.MatchString(Payee(t)):
+		return false
+	case r.remittance != nil && !r.remittance.MatchString(strings.Join(export.RemittanceLines(t.RemittanceInfo), " ")):
+		return false
+	}
+	return true
+}
+
+// Payee returns the name of the counterparty of a transaction: the creditor of payments and the remitter
+// of receipts.
+func Payee(t comdirect.AccountTransaction) string {
+	if t.Amount.Value.Sign() < 0 || t.Remitter.HolderName == "" {
+		return cmp.Or(t.Creditor.HolderName, t.Deptor)
+	}
+	return t.Remitter.HolderName
+}
+
+// compileRegexp compiles a case-insensitive regular expression, an empty expression results in nil.
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + expr)
+}
@@ -0,0 +1,113 @@
+package categorize_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/categorize"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+const testRules = `
+default: Other
+rules:
+  - creditorId: de98zzz09999999999
+    category: Groceries
+    tags: [direct-debit]
+  - iban: DE02 5001 0517 0137 0750 30
+    remittance: miete
+    category: Rent
+    tags: [fixed]
+  - payee: arbeitgeber
+    direction: credit
+    category: Salary
+  - direction: debit
+    min: 500
+    tags: [large]
+  - type: CARD_TRANSACTION
+    max: 50
+    category: Shopping
+`
+
+func TestCategorizer_Categorize(t *testing.T) {
+	categorizer, err := categorize.Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("failed to parse rules: %s", err)
+	}
+	transactions := comdirecttest.DefaultFixtures().Transactions[comdirecttest.AccountID]
+
+	tests := []struct {
+		transaction comdirect.AccountTransaction
+		category    string
+		tags        []string
+	}{
+		{transactions[0], "Shopping", nil},
+		{transactions[1], "Groceries", []string{"direct-debit"}},
+		{transactions[2], "Rent", []string{"fixed", "large"}},
+		{transactions[3], "Salary", nil},
+		{comdirect.AccountTransaction{Amount: comdirect.AmountValue{Value: comdirect.NewDecimal(-1, 0), Unit: "EUR"}}, "Other", nil},
+	}
+	for _, test := range tests {
+		categorized := categorizer.Categorize(test.transaction)
+		if categorized.Category != test.category || !slices.Equal(categorized.Tags, test.tags) {
+			t.Errorf("expected %s %v for %q, got %s %v", test.category, test.tags, test.transaction.Reference, categorized.Category, categorized.Tags)
+		}
+		if categorized.Reference != test.transaction.Reference {
+			t.Errorf("expected the categorized transaction to keep its fields")
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":       "rules:\n  - payees: x\n    category: A\n",
+		"no category":       "rules:\n  - payee: x\n",
+		"no condition":      "rules:\n  - category: A\n",
+		"invalid regexp":    "rules:\n  - remittance: '['\n    category: A\n",
+		"invalid IBAN":      "rules:\n  - iban: DE00\n    category: A\n",
+		"invalid direction": "rules:\n  - direction: both\n    category: A\n",
+		"invalid amount":    "rules:\n  - min: ten\n    category: A\n",
+		"invalid range":     "rules:\n  - min: 10\n    max: 5\n    category: A\n",
+	}
+	for name, rules := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := categorize.Parse(strings.NewReader(rules)); !errors.Is(err, categorize.ErrInvalidRules) {
+				t.Errorf("expected ErrInvalidRules, got: %v", err)
+			}
+		})
+	}
+
+	categorizer, err := categorize.Parse(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("failed to parse empty rules: %s", err)
+	}
+	if category := categorizer.Categorize(comdirect.AccountTransaction{}).Category; category != categorize.DefaultCategory {
+		t.Errorf("expected %s, got %s", categorize.DefaultCategory, category)
+	}
+}
+
+func TestTotals(t *testing.T) {
+	categorizer, err := categorize.Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("failed to parse rules: %s", err)
+	}
+	transactions := categorizer.CategorizeAll(comdirecttest.DefaultFixtures().Transactions[comdirecttest.AccountID])
+	totals := categorize.Totals(transactions)
+
+	var march []string
+	for _, total := range totals {
+		if total.Month == "2024-03" {
+			march = append(march, total.Category+" "+total.Amount.String())
+		}
+	}
+	expected := []string{"Rent -2550 EUR", "Groceries -216.40 EUR", "Salary 3600 EUR"}
+	if !slices.Equal(march, expected) {
+		t.Errorf("expected totals %q for 2024-03, got %q", expected, march)
+	}
+	if totals[0].Month != "2024-01" {
+		t.Errorf("expected totals ordered by month, got %s first", totals[0].Month)
+	}
+}
@@ -0,0 +1,57 @@
+package categorize
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// MonthLayout is the format of the month of a Total, e.g. 2024-03.
+const MonthLayout = "2006-01"
+
+// Total is the sum of the transactions of a category in a month.
+type Total struct {
+	Month    string `json:"month"`
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	// Amount is the sum of the amounts, negative for spending.
+	Amount comdirect.AmountValue `json:"amount"`
+}
+
+// Totals sums up the booked transactions per month and category. The totals are ordered by month and
+// amount, so that the largest spending of a month comes first. Transactions in different currencies
+// are summed up separately.
+func Totals(transactions []Transaction) []Total {
+	type key struct {
+		month, category, unit string
+	}
+	sums := make(map[key]*Total)
+	for _, t := range transactions {
+		if t.BookingDate.IsZero() {
+			continue
+		}
+		k := key{month: t.BookingDate.Time().Format(MonthLayout), category: t.Category, unit: t.Amount.Unit}
+		total, ok := sums[k]
+		if !ok {
+			total = &Total{Month: k.month, Category: k.category, Amount: comdirect.AmountValue{Unit: k.unit}}
+			sums[k] = total
+		}
+		total.Count++
+		total.Amount.Value = total.Amount.Value.Add(t.Amount.Value)
+	}
+
+	totals := make([]Total, 0, len(sums))
+	for _, total := range sums {
+		totals = append(totals, *total)
+	}
+	slices.SortFunc(totals, func(a, b Total) int {
+		return cmp.Or(
+			cmp.Compare(a.Month, b.Month),
+			a.Amount.Value.Cmp(b.Amount.Value),
+			cmp.Compare(a.Category, b.Category),
+			cmp.Compare(a.Amount.Unit, b.Amount.Unit),
+		)
+	})
+	return totals
+}
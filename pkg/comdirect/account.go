@@ -2,9 +2,7 @@ package comdirect
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 )
@@ -46,12 +44,12 @@ type AccountTransactions struct {
 type AccountTransaction struct {
 	Reference             string          `json:"reference"`
 	BookingStatus         string          `json:"bookingStatus"`
-	BookingDate           string          `json:"bookingDate"`
+	BookingDate           Date            `json:"bookingDate"`
 	Amount                AmountValue     `json:"amount"`
 	Remitter              Remitter        `json:"remitter"`
 	Deptor                string          `json:"deptor"`
 	Creditor              Creditor        `json:"creditor"`
-	ValutaDate            string          `json:"valutaDate"`
+	ValutaDate            Date            `json:"valutaDate"`
 	DirectDebitCreditorID string          `json:"directDebitCreditorId"`
 	DirectDebitMandateID  string          `json:"directDebitMandateId"`
 	EndToEndReference     string          `json:"endToEndReference"`
@@ -76,18 +74,19 @@ type Creditor struct {
 }
 
 func (c *Client) Balances(ctx context.Context) (*AccountBalances, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL("/banking/clients/user/v2/accounts/balances"),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    c.http.apiURL("/banking/clients/user/v2/accounts/balances"),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 
@@ -97,20 +96,22 @@ func (c *Client) Balances(ctx context.Context) (*AccountBalances, error) {
 }
 
 func (c *Client) Balance(ctx context.Context, accountId string) (*AccountBalance, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/banking/v2/accounts/%s/balances", accountId)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    c.http.apiURL(fmt.Sprintf("/banking/v2/accounts/%s/balances", accountId)),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
+	req = req.WithContext(ctx)
 	accountBalance := &AccountBalance{}
 	_, err = c.http.exchange(req, accountBalance)
 
@@ -118,20 +119,22 @@ func (c *Client) Balance(ctx context.Context, accountId string) (*AccountBalance
 }
 
 func (c *Client) Transactions(ctx context.Context, accountId string, options ...Options) (*AccountTransactions, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	url := apiURL(fmt.Sprintf("/banking/v1/accounts/%s/transactions", accountId))
+	url := c.http.apiURL(fmt.Sprintf("/banking/v1/accounts/%s/transactions", accountId))
 	encodeOptions(url, options)
 	req := &http.Request{
 		Method: http.MethodGet,
 		URL:    url,
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
+	req = req.WithContext(ctx)
 
 	tr := &AccountTransactions{}
 	_, err = c.http.exchange(req, tr)
@@ -139,22 +142,22 @@ func (c *Client) Transactions(ctx context.Context, accountId string, options ...
 	return tr, err
 }
 
+// FilterSince returns the booked transactions up to the first one booked before date.
+// The transactions are expected to be ordered by booking date, newest first, as returned by comdirect.
 func (a *AccountTransactions) FilterSince(date time.Time) (*AccountTransactions, error) {
+	since := DateOf(date)
 	filteredTransactions := &AccountTransactions{Paging: a.Paging}
 	for _, v := range a.Values {
 		if v.BookingStatus == "NOTBOOKED" {
 			continue
 		}
-		const transactionDateLayout = "2006-01-02"
-		d, err := time.Parse(transactionDateLayout, v.BookingDate)
-		if err != nil {
-			log.Fatalf("Failed to parse date from transaction: %s", err)
+		if v.BookingDate.IsZero() {
+			return nil, fmt.Errorf("booked transaction %q has no booking date", v.Reference)
 		}
-		if d.Before(date) {
+		if v.BookingDate.Before(since) {
 			break
-		} else {
-			filteredTransactions.Values = append(filteredTransactions.Values, v)
 		}
+		filteredTransactions.Values = append(filteredTransactions.Values, v)
 	}
 	return filteredTransactions, nil
 }
@@ -1,81 +1,57 @@
-package comdirect
+package comdirect_test
 
 import (
-	"context"
-	"fmt"
-	"os"
 	"testing"
-	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
 )
 
 func TestClient_Balances(t *testing.T) {
-	client := clientFromEnv()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	auth, err := client.Authenticate(ctx)
-	if err != nil {
-		t.Errorf("failed to authenticate: %s", err)
-	}
-	fmt.Printf("%+v\n", auth)
-	balances, err := client.Balances(ctx)
-	if err != nil {
-		t.Errorf("failed to exchange account balances %s", err)
-	}
-
-	fmt.Printf("%+v\n", balances)
 
-	auth, err = client.Refresh()
+	balances, err := client.Balances(ctx)
 	if err != nil {
-		return
+		t.Fatalf("failed to exchange account balances %s", err)
 	}
-	fmt.Printf("%+v\n", auth)
-
-	err = client.Revoke()
-	if err != nil {
-		return
+	if len(balances.Values) != 1 || balances.Values[0].AccountId != comdirecttest.AccountID {
+		t.Errorf("unexpected account balances: %+v", balances)
 	}
-	fmt.Println("successfully revoked access token")
 }
 
 func TestClient_Balance(t *testing.T) {
-	client := clientFromEnv()
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	_, err := client.Balance(ctx, os.Getenv("COMDIRECT_ACCOUNT_ID"))
 
+	balance, err := client.Balance(ctx, comdirecttest.AccountID)
 	if err != nil {
-		t.Errorf("failed to exchange account balance %s", err)
-		return
+		t.Fatalf("failed to exchange account balance %s", err)
+	}
+	if balance.Balance.Value.String() != "2500.25" || balance.Balance.Unit != "EUR" {
+		t.Errorf("unexpected balance: %+v", balance.Balance)
 	}
 }
 
 func TestClient_Transactions(t *testing.T) {
-	client := clientFromEnv()
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	since := time.Now().Add(time.Hour * -1 * 24 * 30)
-	transactions, err := client.Transactions(ctx, os.Getenv("COMDIRECT_ACCOUNT_ID"), since)
 
+	options := comdirect.EmptyOptions()
+	options.Add(comdirect.PagingFirstQueryKey, "5")
+	options.Add(comdirect.PagingCountQueryKey, "10")
+	transactions, err := client.Transactions(ctx, comdirecttest.AccountID, options)
 	if err != nil {
-		t.Errorf("failed to exchange account balance %s", err)
-		return
+		t.Fatalf("failed to exchange account transactions %s", err)
 	}
 
-	for _, tr := range transactions.Values {
-		fmt.Printf("%v\n", tr)
+	if transactions.Paging.Matches != 30 || transactions.Paging.Index != 5 {
+		t.Errorf("unexpected paging: %+v", transactions.Paging)
 	}
-}
-
-func clientFromEnv() *Client {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
+	if len(transactions.Values) != 10 {
+		t.Errorf("expected 10 transactions, got %d", len(transactions.Values))
 	}
-	return NewWithAuthOptions(options)
-}
-
-func contextTimeout10Seconds() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), time.Second*10)
 }
@@ -0,0 +1,85 @@
+package comdirect
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCurrencyMismatch is returned by AmountValue operations on amounts with different units.
+var ErrCurrencyMismatch = errors.New("comdirect: currency mismatch")
+
+// AmountValue is an amount of money in the currency Unit, e.g. EUR,
+// or a quantity with the Unit XXX for pieces.
+type AmountValue struct {
+	Value Decimal `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// NewAmountValue returns an AmountValue of the value s in the given unit, e.g. NewAmountValue("12.50", "EUR").
+func NewAmountValue(s string, unit string) (AmountValue, error) {
+	value, err := ParseDecimal(s)
+	if err != nil {
+		return AmountValue{}, err
+	}
+	return AmountValue{Value: value, Unit: unit}, nil
+}
+
+// Add returns a + b. The zero AmountValue without unit can be added to any AmountValue,
+// which allows to sum up amounts starting from AmountValue{}.
+func (a AmountValue) Add(b AmountValue) (AmountValue, error) {
+	unit, err := commonUnit(a, b)
+	if err != nil {
+		return AmountValue{}, err
+	}
+	return AmountValue{Value: a.Value.Add(b.Value), Unit: unit}, nil
+}
+
+// Sub returns a - b.
+func (a AmountValue) Sub(b AmountValue) (AmountValue, error) {
+	unit, err := commonUnit(a, b)
+	if err != nil {
+		return AmountValue{}, err
+	}
+	return AmountValue{Value: a.Value.Sub(b.Value), Unit: unit}, nil
+}
+
+// Neg returns -a.
+func (a AmountValue) Neg() AmountValue {
+	return AmountValue{Value: a.Value.Neg(), Unit: a.Unit}
+}
+
+// Cmp compares a and b and returns -1 if a < b, 0 if a == b and +1 if a > b.
+func (a AmountValue) Cmp(b AmountValue) (int, error) {
+	if _, err := commonUnit(a, b); err != nil {
+		return 0, err
+	}
+	return a.Value.Cmp(b.Value), nil
+}
+
+// IsZero reports whether the value of a is zero.
+func (a AmountValue) IsZero() bool {
+	return a.Value.IsZero()
+}
+
+// String returns the value and unit of a, e.g. "1234.56 EUR".
+func (a AmountValue) String() string {
+	return a.Format(Locale{DecimalSeparator: "."})
+}
+
+// Format returns the value of a formatted with the given Locale followed by its unit, e.g. "1.234,56 EUR".
+func (a AmountValue) Format(locale Locale) string {
+	if a.Unit == "" {
+		return a.Value.Format(locale)
+	}
+	return a.Value.Format(locale) + " " + a.Unit
+}
+
+func commonUnit(a AmountValue, b AmountValue) (string, error) {
+	switch {
+	case a.Unit == b.Unit || b.Unit == "" && b.IsZero():
+		return a.Unit, nil
+	case a.Unit == "" && a.IsZero():
+		return b.Unit, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Unit, b.Unit)
+}
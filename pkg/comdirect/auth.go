@@ -13,15 +13,16 @@ import (
 	"time"
 
 	"github.com/jsattler/go-comdirect/internal/mediatype"
-	"golang.org/x/time/rate"
 )
 
 // Authenticator is responsible for authenticating against the comdirect REST API.
 // It uses the given AuthOptions for authentication and returns an AccessToken in case
-// the authentication flow was successful. Authenticator is using golang's default http.Client.
+// the authentication flow was successful. Authenticator is using golang's default http.Client
+// unless configured otherwise with an Option.
 type Authenticator struct {
-	authOptions *AuthOptions
-	http        *HTTPClient
+	authOptions  *AuthOptions
+	http         *HTTPClient
+	pollInterval time.Duration
 }
 
 // authContext encapsulates the state that is passed through the comdirect authentication flow.
@@ -30,6 +31,7 @@ type authContext struct {
 	requestInfo  requestInfo
 	session      session
 	onceAuthInfo onceAuthenticationInfo
+	tan          string
 }
 
 // Authentication represents an authentication object for the comdirect REST API.
@@ -45,6 +47,9 @@ type AuthOptions struct {
 	Password     string
 	ClientId     string
 	ClientSecret string
+	// TANHandler is used for TAN types that require the user to enter a TAN, e.g. photoTAN or mobileTAN.
+	// Without a TANHandler only push TAN challenges are supported.
+	TANHandler TANHandler `json:"-"`
 }
 
 // AccessToken represents an OAuth2 token that is returned from the comdirect REST API.
@@ -82,6 +87,7 @@ type session struct {
 type onceAuthenticationInfo struct {
 	Id             string   `json:"id"`
 	Typ            string   `json:"typ"`
+	Challenge      string   `json:"challenge"`
 	AvailableTypes []string `json:"availableTypes"`
 	Link           link     `json:"link"`
 }
@@ -98,11 +104,16 @@ type authStatus struct {
 }
 
 // NewAuthenticator creates a new Authenticator by passing AuthOptions
-// and an http.Client with a timeout of DefaultHttpTimeout.
-func NewAuthenticator(options *AuthOptions) *Authenticator {
+// and optional Option values. By default, an http.Client with a timeout of DefaultHttpTimeout is used.
+func NewAuthenticator(options *AuthOptions, opts ...Option) *Authenticator {
+	return newAuthenticator(options, applyOptions(opts...))
+}
+
+func newAuthenticator(options *AuthOptions, o *options) *Authenticator {
 	return &Authenticator{
-		authOptions: options,
-		http:        &HTTPClient{http.Client{Timeout: DefaultHttpTimeout}, *rate.NewLimiter(10, 10)},
+		authOptions:  options,
+		http:         newHTTPClient(o),
+		pollInterval: o.pollInterval,
 	}
 }
 
@@ -126,6 +137,35 @@ func (a *Authentication) ExpiryTime() time.Time {
 	return a.time
 }
 
+// authenticationJSON is the serialized form of an Authentication.
+type authenticationJSON struct {
+	AccessToken AccessToken `json:"accessToken"`
+	SessionID   string      `json:"sessionId"`
+	LoginTime   time.Time   `json:"loginTime"`
+}
+
+// MarshalJSON encodes the Authentication including its refresh token and login time,
+// so that it can be persisted by a TokenStore.
+func (a *Authentication) MarshalJSON() ([]byte, error) {
+	return json.Marshal(authenticationJSON{
+		AccessToken: a.accessToken,
+		SessionID:   a.sessionID,
+		LoginTime:   a.time,
+	})
+}
+
+// UnmarshalJSON decodes an Authentication encoded by MarshalJSON.
+func (a *Authentication) UnmarshalJSON(data []byte) error {
+	var v authenticationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	a.accessToken = v.AccessToken
+	a.sessionID = v.SessionID
+	a.time = v.LoginTime
+	return nil
+}
+
 // Authenticate authenticates against the comdirect REST API.
 func (a *Authenticator) Authenticate(ctx context.Context) (*Authentication, error) {
 
@@ -139,12 +179,17 @@ func (a *Authenticator) Authenticate(ctx context.Context) (*Authentication, erro
 		return nil, err
 	}
 
-	authCtx, err = a.validateSessionTan(ctx, authCtx)
+	authCtx, err = a.validateSessionTan(ctx, authCtx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	authCtx, err = a.selectTANType(ctx, authCtx)
 	if err != nil {
 		return nil, err
 	}
 
-	authCtx, err = a.checkAuthenticationStatus(ctx, authCtx)
+	authCtx, err = a.solveTANChallenge(ctx, authCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +211,12 @@ func (a *Authenticator) Authenticate(ctx context.Context) (*Authentication, erro
 	}, err
 }
 
+// Refresh uses the refresh token of the given Authentication to request a new access token.
 func (a *Authenticator) Refresh(auth Authentication) (Authentication, error) {
+	return a.refresh(context.Background(), auth)
+}
+
+func (a *Authenticator) refresh(ctx context.Context, auth Authentication) (Authentication, error) {
 	encoded := url.Values{
 		"grant_type":    {RefreshTokenGrantType},
 		"client_id":     {a.authOptions.ClientId},
@@ -177,46 +227,60 @@ func (a *Authenticator) Refresh(auth Authentication) (Authentication, error) {
 	body := ioutil.NopCloser(strings.NewReader(encoded))
 	req := &http.Request{
 		Method: http.MethodPost,
-		URL:    &url.URL{Host: Host, Scheme: HttpsScheme, Path: OAuthTokenPath},
+		URL:    a.http.comdirectURL(OAuthTokenPath),
 		Header: http.Header{
 			http.CanonicalHeaderKey(AcceptHeaderKey):      {mediatype.ApplicationJson},
 			http.CanonicalHeaderKey(ContentTypeHeaderKey): {mediatype.XWWWFormUrlEncoded},
 		},
 		Body: body,
 	}
+	req = req.WithContext(ctx)
 
 	var accessToken AccessToken
-	_, err := a.http.exchange(req, &accessToken)
+	if _, err := a.http.exchange(req, &accessToken); err != nil {
+		return auth, err
+	}
 
 	auth.accessToken = accessToken
 	auth.time = time.Now()
-	return auth, err
+	return auth, nil
 }
 
 func (a *Authenticator) Revoke(auth Authentication) error {
 
 	req := &http.Request{
 		Method: http.MethodDelete,
-		URL:    &url.URL{Host: Host, Scheme: HttpsScheme, Path: OAuthTokenPath},
+		URL:    a.http.comdirectURL(OAuthTokenPath),
 		Header: http.Header{
 			http.CanonicalHeaderKey(AcceptHeaderKey):        {mediatype.ApplicationJson},
 			http.CanonicalHeaderKey(ContentTypeHeaderKey):   {mediatype.XWWWFormUrlEncoded},
 			http.CanonicalHeaderKey(AuthorizationHeaderKey): {BearerPrefix + auth.accessToken.AccessToken},
 		},
 	}
-	response, err := a.http.Do(req)
+	response, err := a.http.do(req)
 	if err != nil {
 		return err
 	}
-	if response.StatusCode != 204 {
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusNoContent {
 		return errors.New("could not revoke access token")
 	}
 	return nil
 }
 
 func (a *Authentication) IsExpired() bool {
+	return a.ExpiresAt().Before(time.Now())
+}
+
+// ExpiresAt returns the time at which the access token expires.
+func (a *Authentication) ExpiresAt() time.Time {
 	expiresIn := time.Duration(a.accessToken.ExpiresIn)
-	return a.time.Add(expiresIn * time.Second).Before(time.Now())
+	return a.time.Add(expiresIn * time.Second)
+}
+
+// expiresWithin reports whether the access token expires within the given duration.
+func (a *Authentication) expiresWithin(d time.Duration) bool {
+	return !a.ExpiresAt().After(time.Now().Add(d))
 }
 
 // Step 2.1
@@ -233,7 +297,7 @@ func (a *Authenticator) passwordGrant(ctx context.Context, options *AuthOptions)
 	body := ioutil.NopCloser(strings.NewReader(urlEncoded))
 	req := &http.Request{
 		Method: http.MethodPost,
-		URL:    &url.URL{Host: Host, Scheme: HttpsScheme, Path: OAuthTokenPath},
+		URL:    a.http.comdirectURL(OAuthTokenPath),
 		Header: http.Header{
 			http.CanonicalHeaderKey(AcceptHeaderKey):      {mediatype.ApplicationJson},
 			http.CanonicalHeaderKey(ContentTypeHeaderKey): {mediatype.XWWWFormUrlEncoded},
@@ -265,7 +329,7 @@ func (a *Authenticator) fetchSessionStatus(ctx context.Context, authCtx authCont
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    comdirectURL("/api/session/clients/user/v1/sessions"),
+		URL:    a.http.comdirectURL("/api/session/clients/user/v1/sessions"),
 		Header: http.Header{
 			AuthorizationHeaderKey:   {BearerPrefix + authCtx.accessToken.AccessToken},
 			AcceptHeaderKey:          {mediatype.ApplicationJson},
@@ -290,7 +354,9 @@ func (a *Authenticator) fetchSessionStatus(ctx context.Context, authCtx authCont
 }
 
 // Step: 2.3
-func (a *Authenticator) validateSessionTan(ctx context.Context, authCtx authContext) (authContext, error) {
+// validateSessionTan requests a TAN challenge for the session. If tanType is empty, comdirect
+// chooses the TAN type based on the user's settings.
+func (a *Authenticator) validateSessionTan(ctx context.Context, authCtx authContext, tanType TANType) (authContext, error) {
 	authCtx.session.SessionTanActive = true
 	authCtx.session.Activated2FA = true
 	jsonSession, err := json.Marshal(authCtx.session)
@@ -308,7 +374,7 @@ func (a *Authenticator) validateSessionTan(ctx context.Context, authCtx authCont
 	body := ioutil.NopCloser(strings.NewReader(string(jsonSession)))
 	req := &http.Request{
 		Method: http.MethodPost,
-		URL:    comdirectURL(path),
+		URL:    a.http.comdirectURL(path),
 		Header: http.Header{
 			AuthorizationHeaderKey:   {BearerPrefix + authCtx.accessToken.AccessToken},
 			AcceptHeaderKey:          {mediatype.ApplicationJson},
@@ -317,6 +383,7 @@ func (a *Authenticator) validateSessionTan(ctx context.Context, authCtx authCont
 		},
 		Body: body,
 	}
+	setTANType(req, tanType)
 	req = req.WithContext(ctx)
 
 	res, err := a.http.exchange(req, &authCtx.session)
@@ -356,7 +423,7 @@ func (a *Authenticator) activateSessionTan(ctx context.Context, authCtx authCont
 
 	req := &http.Request{
 		Method: http.MethodPatch,
-		URL:    comdirectURL(path),
+		URL:    a.http.comdirectURL(path),
 		Header: http.Header{
 			AuthorizationHeaderKey:          {BearerPrefix + authCtx.accessToken.AccessToken},
 			AcceptHeaderKey:                 {mediatype.ApplicationJson},
@@ -366,6 +433,9 @@ func (a *Authenticator) activateSessionTan(ctx context.Context, authCtx authCont
 		},
 		Body: ioutil.NopCloser(strings.NewReader(string(JSONSession))),
 	}
+	if authCtx.tan != "" {
+		req.Header.Set(OnceAuthenticationHeaderKey, authCtx.tan)
+	}
 	req = req.WithContext(ctx)
 
 	_, err = a.http.exchange(req, &authCtx.session)
@@ -389,7 +459,7 @@ func (a *Authenticator) secondaryFlow(ctx context.Context, authCtx authContext)
 
 	req := &http.Request{
 		Method: http.MethodPost,
-		URL:    &url.URL{Host: Host, Scheme: HttpsScheme, Path: OAuthTokenPath},
+		URL:    a.http.comdirectURL(OAuthTokenPath),
 		Header: http.Header{
 			http.CanonicalHeaderKey(AcceptHeaderKey):      {mediatype.ApplicationJson},
 			http.CanonicalHeaderKey(ContentTypeHeaderKey): {mediatype.XWWWFormUrlEncoded},
@@ -398,58 +468,68 @@ func (a *Authenticator) secondaryFlow(ctx context.Context, authCtx authContext)
 	}
 	req = req.WithContext(ctx)
 
-	res, err := a.http.Do(req)
+	res, err := a.http.do(req)
 
 	if err != nil {
 		return authCtx, err
 	}
 
+	defer res.Body.Close()
 	if err = json.NewDecoder(res.Body).Decode(&authCtx.accessToken); err != nil {
 		return authCtx, err
 	}
 
-	return authCtx, res.Body.Close()
+	return authCtx, nil
 }
 
-func (a *Authenticator) checkAuthenticationStatus(ctx context.Context, authCtx authContext) (authContext, error) {
-	authCtx.requestInfo.ClientRequestID.RequestID = generateRequestID()
-	requestInfoJson, err := json.Marshal(authCtx.requestInfo)
+// selectTANType asks the TANHandler for the preferred TAN type and requests a new
+// TAN challenge if it differs from the one chosen by comdirect.
+func (a *Authenticator) selectTANType(ctx context.Context, authCtx authContext) (authContext, error) {
+	handler := a.authOptions.TANHandler
+	if handler == nil {
+		return authCtx, nil
+	}
+	available := tanTypes(authCtx.onceAuthInfo.AvailableTypes)
+	preferred := handler.SelectTANType(available)
+	if preferred == "" || preferred == TANType(authCtx.onceAuthInfo.Typ) {
+		return authCtx, nil
+	}
+	if !containsTANType(available, preferred) {
+		return authCtx, fmt.Errorf("TAN type %s is not available; available types are %v", preferred, available)
+	}
+	return a.validateSessionTan(ctx, authCtx, preferred)
+}
+
+// solveTANChallenge waits for the approval of push TAN challenges or asks
+// the TANHandler for the TAN of all other challenges.
+func (a *Authenticator) solveTANChallenge(ctx context.Context, authCtx authContext) (authContext, error) {
+	if TANType(authCtx.onceAuthInfo.Typ) == TANTypePush {
+		return a.checkAuthenticationStatus(ctx, authCtx)
+	}
+	handler := a.authOptions.TANHandler
+	if handler == nil {
+		return authCtx, fmt.Errorf("TAN type %s requires a TANHandler", authCtx.onceAuthInfo.Typ)
+	}
+	challenge, err := newTANChallenge(authCtx.onceAuthInfo)
 	if err != nil {
 		return authCtx, err
 	}
-
-	req := &http.Request{
-		Method: http.MethodGet,
-		URL:    comdirectURL(authCtx.onceAuthInfo.Link.Href),
-		Header: http.Header{
-			AuthorizationHeaderKey:   {BearerPrefix + authCtx.accessToken.AccessToken},
-			AcceptHeaderKey:          {mediatype.ApplicationJson},
-			ContentTypeHeaderKey:     {mediatype.ApplicationJson},
-			HttpRequestInfoHeaderKey: {string(requestInfoJson)},
-		},
+	authCtx.tan, err = handler.HandleTAN(ctx, challenge)
+	if err != nil {
+		return authCtx, err
 	}
-	req = req.WithContext(ctx)
-
-	for {
-		select {
-		// Poll authentication status every 3 seconds
-		case <-time.After(3 * time.Second):
-			response, err := a.http.Do(req)
-			if err != nil {
-				return authCtx, err
-			}
-			var authStatus authStatus
-			if err = json.NewDecoder(response.Body).Decode(&authStatus); err != nil {
-				return authCtx, err
-			}
-
-			if authStatus.Status == "AUTHENTICATED" {
-				return authCtx, nil
-			}
-		// When timeout is reached return with error
-		case <-ctx.Done():
-			return authCtx, ctx.Err()
-		}
+	if authCtx.tan == "" {
+		return authCtx, errors.New("TAN cannot be empty")
 	}
+	return authCtx, nil
+}
 
+func (a *Authenticator) checkAuthenticationStatus(ctx context.Context, authCtx authContext) (authContext, error) {
+	authCtx.requestInfo.ClientRequestID.RequestID = generateRequestID()
+	requestInfoJson, err := json.Marshal(authCtx.requestInfo)
+	if err != nil {
+		return authCtx, err
+	}
+	header := defaultHeaders(authCtx.accessToken.AccessToken, string(requestInfoJson))
+	return authCtx, a.http.awaitApproval(ctx, authCtx.onceAuthInfo.Link.Href, header, a.pollInterval)
 }
@@ -1,67 +1,89 @@
-package comdirect
+package comdirect_test
 
 import (
-	"context"
-	"os"
 	"testing"
 	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
 )
 
 func TestNewAuthenticator(t *testing.T) {
-	options := &AuthOptions{
+	options := &comdirect.AuthOptions{
 		Username:     "",
 		Password:     "",
 		ClientId:     "",
 		ClientSecret: "",
 	}
-	authenticator := NewAuthenticator(options)
-	if authenticator.authOptions != options {
-		t.Errorf("actual AuthOptions differ from expected: %v", authenticator.authOptions)
+	authenticator := comdirect.NewAuthenticator(options)
+	if authenticator.AuthOptions() != options {
+		t.Errorf("actual AuthOptions differ from expected: %v", authenticator.AuthOptions())
 	}
 }
 
 func TestNewAuthenticator2(t *testing.T) {
-	options := &AuthOptions{
+	options := &comdirect.AuthOptions{
 		Username:     "",
 		Password:     "",
 		ClientId:     "",
 		ClientSecret: "",
 	}
-	authenticator := NewAuthenticator(options)
-	if authenticator.authOptions != options {
-		t.Errorf("actual AuthOptions differ from expected: %v", authenticator.authOptions)
+	authenticator := comdirect.NewAuthenticator(options, comdirect.WithBaseURL("http://localhost"))
+	if authenticator.AuthOptions() != options {
+		t.Errorf("actual AuthOptions differ from expected: %v", authenticator.AuthOptions())
 	}
 }
 
 func TestAuthenticator_Authenticate(t *testing.T) {
-	authenticator := AuthenticatorFromEnv()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	authenticator := comdirect.NewAuthenticator(server.AuthOptions(), server.ClientOptions()...)
+	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	_, err := authenticator.Authenticate(ctx)
+	auth, err := authenticator.Authenticate(ctx)
 	if err != nil {
-		t.Errorf("authentication failed %s", err)
+		t.Fatalf("authentication failed %s", err)
+	}
+	if auth.IsExpired() {
+		t.Error("expected authentication not to be expired")
 	}
 }
 
-func AuthenticatorFromEnv() *Authenticator {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
+func TestAuthenticator_Authenticate_ApprovedLater(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	server.SetAutoApprove(false)
+	authenticator := comdirect.NewAuthenticator(server.AuthOptions(), server.ClientOptions()...)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		_, err := authenticator.Authenticate(ctx)
+		done <- err
+	}()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("authentication failed %s", err)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+			server.Approve()
+		}
 	}
-	return NewAuthenticator(options)
 }
 
 func TestGenerateSessionId(t *testing.T) {
-	sessionID := generateSessionID()
+	sessionID := comdirect.GenerateSessionID()
 	if len(sessionID) != 32 {
 		t.Errorf("length of session id not equal to 32: %d", len(sessionID))
 	}
 }
 
 func TestGenerateRequestId(t *testing.T) {
-	requestID := generateRequestID()
+	requestID := comdirect.GenerateRequestID()
 	if len(requestID) != 9 {
 		t.Errorf("length of request ID is not equal to 9: %d", len(requestID))
 	}
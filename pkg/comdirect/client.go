@@ -3,10 +3,9 @@ package comdirect
 import (
 	"context"
 	"errors"
-	"net/http"
+	"fmt"
+	"sync"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
 const (
@@ -46,17 +45,23 @@ const (
 	TypeQueryKey                 = "type"
 	BookingStatusQueryKey        = "bookingStatus"
 	MaxBookingDateQueryKey       = "max-bookingDate"
+	MinBookingDateQueryKey       = "min-bookingDate"
+	TransactionDirectionQueryKey = "transactionDirection"
 )
 
-type Client struct {
-	authenticator  *Authenticator
-	http           *HTTPClient
-	authentication *Authentication
-}
+// ErrNotAuthenticated is returned by Client methods if no valid Authentication is available.
+var ErrNotAuthenticated = errors.New("authentication is expired or not initialized")
 
-type AmountValue struct {
-	Value string `json:"value"`
-	Unit  string `json:"unit"`
+type Client struct {
+	authenticator    *Authenticator
+	http             *HTTPClient
+	mu               sync.Mutex
+	authentication   *Authentication
+	autoRefresh      bool
+	refreshSkew      time.Duration
+	onTokenRefreshed func(*Authentication)
+	tokenStore       TokenStore
+	pollInterval     time.Duration
 }
 
 type Paging struct {
@@ -92,27 +97,44 @@ func (o *Options) Values() Values {
 	return o.values
 }
 
-// NewWithAuthenticator creates a new Client with a given Authenticator
-func NewWithAuthenticator(authenticator *Authenticator) *Client {
-	return &Client{
-		authenticator: authenticator,
-		http:          &HTTPClient{http.Client{Timeout: DefaultHttpTimeout}, *rate.NewLimiter(10, 10)},
-	}
+// NewWithAuthenticator creates a new Client with a given Authenticator.
+// The Option values only apply to the Client, the Authenticator keeps its own settings.
+func NewWithAuthenticator(authenticator *Authenticator, opts ...Option) *Client {
+	o := applyOptions(opts...)
+	return newClient(authenticator, nil, newHTTPClient(o), o)
+}
+
+// NewWithAuthOptions creates a new Client with given AuthOptions.
+// The Client and its Authenticator share the HTTP settings of the given Option values.
+func NewWithAuthOptions(options *AuthOptions, opts ...Option) *Client {
+	o := applyOptions(opts...)
+	authenticator := newAuthenticator(options, o)
+	return newClient(authenticator, nil, authenticator.http, o)
 }
 
-// NewWithAuthOptions creates a new Client with given AuthOptions
-func NewWithAuthOptions(options *AuthOptions) *Client {
-	return NewWithAuthenticator(NewAuthenticator(options))
+// NewWithAuthentication creates a new Client from an existing Authentication.
+// Without an Authenticator the Client cannot refresh the Authentication.
+func NewWithAuthentication(authentication *Authentication, opts ...Option) *Client {
+	o := applyOptions(opts...)
+	return newClient(nil, authentication, newHTTPClient(o), o)
 }
 
-func NewWithAuthentication(authentication *Authentication) *Client {
+func newClient(authenticator *Authenticator, authentication *Authentication, h *HTTPClient, o *options) *Client {
 	return &Client{
-		authentication: authentication,
-		http:           &HTTPClient{http.Client{Timeout: DefaultHttpTimeout}, *rate.NewLimiter(10, 10)},
+		authenticator:    authenticator,
+		http:             h,
+		authentication:   authentication,
+		autoRefresh:      o.autoRefresh,
+		refreshSkew:      o.refreshSkew,
+		onTokenRefreshed: o.onTokenRefreshed,
+		tokenStore:       o.tokenStore,
+		pollInterval:     o.pollInterval,
 	}
 }
 
 // Authenticate uses the underlying Authenticator to authenticate against the comdirect REST API.
+// If the Client has a TokenStore, the new Authentication is saved. The Authentication is returned
+// together with the error if only saving failed.
 func (c *Client) Authenticate(ctx context.Context) (*Authentication, error) {
 	if c.authenticator == nil {
 		return nil, errors.New("authenticator cannot be nil")
@@ -121,23 +143,63 @@ func (c *Client) Authenticate(ctx context.Context) (*Authentication, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.authentication = authentication
-	return c.authentication, nil
+	return c.authentication, c.save(ctx)
+}
+
+// Restore makes the Client use the Authentication of its TokenStore, unless it already has one.
+// An expired access token is refreshed if possible. Restore returns ErrNotAuthenticated if no valid
+// Authentication could be restored, in which case Authenticate has to be called.
+func (c *Client) Restore(ctx context.Context) (*Authentication, error) {
+	c.mu.Lock()
+	auth, refreshed, err := c.restore(ctx)
+	c.mu.Unlock()
+	if refreshed {
+		c.notifyRefreshed(auth)
+	}
+	return auth, err
+}
+
+// restore implements Restore and reports whether the access token was refreshed. The caller must hold c.mu.
+func (c *Client) restore(ctx context.Context) (*Authentication, bool, error) {
+	auth, err := c.loadAuthentication(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	canRefresh := c.authenticator != nil && auth.accessToken.RefreshToken != ""
+	if canRefresh && auth.expiresWithin(c.refreshSkew) {
+		if auth, err = c.refresh(ctx); err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrNotAuthenticated, err)
+		}
+		return auth, true, nil
+	}
+	if auth.IsExpired() {
+		return nil, false, ErrNotAuthenticated
+	}
+	return auth, false, nil
 }
 
 func (c *Client) SetAuthentication(auth *Authentication) error {
 	if auth == nil {
 		return errors.New("authentication cannot be nil")
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.authentication = auth
 	return nil
 }
 
 func (c *Client) GetAuthentication() *Authentication {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.authentication
 }
 
 func (c *Client) IsAuthenticated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.authentication != nil && c.authentication.accessToken.AccessToken != "" && !c.authentication.IsExpired()
 }
 
@@ -146,11 +208,19 @@ func (c *Client) Revoke() error {
 	if c.authenticator == nil {
 		return errors.New("authenticator cannot be nil")
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authentication == nil {
+		return ErrNotAuthenticated
+	}
 	err := c.authenticator.Revoke(*c.authentication)
 	if err != nil {
 		return err
 	}
 	c.authentication = nil
+	if c.tokenStore != nil {
+		return c.tokenStore.Delete(context.Background())
+	}
 	return nil
 }
 
@@ -159,10 +229,134 @@ func (c *Client) Refresh() (*Authentication, error) {
 	if c.authenticator == nil {
 		return nil, errors.New("authenticator cannot be nil")
 	}
-	authentication, err := c.authenticator.Refresh(*c.authentication)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authentication == nil {
+		return nil, ErrNotAuthenticated
+	}
+	return c.refresh(context.Background())
+}
+
+// KeepAlive refreshes the access token shortly before it expires until the context is done,
+// which keeps the session alive for long-running processes. It returns the context's error
+// or the error of a failed refresh.
+func (c *Client) KeepAlive(ctx context.Context) error {
+	if c.authenticator == nil {
+		return errors.New("authenticator cannot be nil")
+	}
+	for {
+		auth := c.GetAuthentication()
+		if auth == nil {
+			return ErrNotAuthenticated
+		}
+		wait := time.Until(auth.ExpiresAt()) - c.keepAliveSkew()
+		select {
+		case <-time.After(wait):
+			c.mu.Lock()
+			// Another goroutine may have refreshed the token in the meantime.
+			var refreshed *Authentication
+			var err error
+			if c.authentication == auth {
+				refreshed, err = c.refresh(ctx)
+			}
+			c.mu.Unlock()
+			if err != nil {
+				return err
+			}
+			if refreshed != nil {
+				c.notifyRefreshed(refreshed)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) keepAliveSkew() time.Duration {
+	if c.refreshSkew > 0 {
+		return c.refreshSkew
+	}
+	return DefaultRefreshSkew
+}
+
+// validAuthentication returns the current Authentication. With auto refresh enabled, the access token
+// is refreshed if it expires within the configured skew. Concurrent refreshes are serialized.
+func (c *Client) validAuthentication(ctx context.Context) (*Authentication, error) {
+	c.mu.Lock()
+	auth, refreshed, err := c.currentAuthentication(ctx)
+	c.mu.Unlock()
+	if refreshed {
+		c.notifyRefreshed(auth)
+	}
+	return auth, err
+}
+
+// currentAuthentication implements validAuthentication and reports whether the access token was refreshed.
+// The caller must hold c.mu.
+func (c *Client) currentAuthentication(ctx context.Context) (*Authentication, bool, error) {
+	auth, err := c.loadAuthentication(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	canRefresh := c.autoRefresh && c.authenticator != nil && auth.accessToken.RefreshToken != ""
+	if canRefresh && auth.expiresWithin(c.refreshSkew) {
+		auth, err = c.refresh(ctx)
+		return auth, err == nil, err
+	}
+	if auth.IsExpired() {
+		return nil, false, ErrNotAuthenticated
+	}
+	return auth, false, nil
+}
+
+// loadAuthentication returns the current Authentication and loads it from the TokenStore
+// if the Client has none yet. The caller must hold c.mu.
+func (c *Client) loadAuthentication(ctx context.Context) (*Authentication, error) {
+	if c.authentication == nil && c.tokenStore != nil {
+		auth, err := c.tokenStore.Load(ctx)
+		if errors.Is(err, ErrNoAuthentication) {
+			return nil, ErrNotAuthenticated
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load authentication: %w", err)
+		}
+		c.authentication = auth
+	}
+	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" {
+		return nil, ErrNotAuthenticated
+	}
+	return c.authentication, nil
+}
+
+// refresh refreshes the current Authentication and saves it to the TokenStore. The caller must hold c.mu
+// and notify the OnTokenRefreshed callback with notifyRefreshed after releasing it.
+func (c *Client) refresh(ctx context.Context) (*Authentication, error) {
+	authentication, err := c.authenticator.refresh(ctx, *c.authentication)
 	if err != nil {
 		return nil, err
 	}
 	c.authentication = &authentication
+	if err = c.save(ctx); err != nil {
+		return nil, err
+	}
 	return c.authentication, nil
 }
+
+// notifyRefreshed calls the OnTokenRefreshed callback, if any. The caller must not hold c.mu,
+// so that the callback can use the Client.
+func (c *Client) notifyRefreshed(auth *Authentication) {
+	if c.onTokenRefreshed != nil {
+		c.onTokenRefreshed(auth)
+	}
+}
+
+// save saves the current Authentication to the TokenStore, if any. The caller must hold c.mu.
+func (c *Client) save(ctx context.Context) error {
+	if c.tokenStore == nil {
+		return nil
+	}
+	if err := c.tokenStore.Save(ctx, c.authentication); err != nil {
+		return fmt.Errorf("failed to save authentication: %w", err)
+	}
+	return nil
+}
@@ -1,23 +1,249 @@
-package comdirect
+package comdirect_test
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
 
 func TestNewWithAuthenticator(t *testing.T) {
-	// TODO
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	authenticator := comdirect.NewAuthenticator(server.AuthOptions(), server.ClientOptions()...)
+	client := comdirect.NewWithAuthenticator(authenticator, server.ClientOptions()...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	if !client.IsAuthenticated() {
+		t.Error("expected client to be authenticated")
+	}
 }
 
 func TestNewWithAuthOptions(t *testing.T) {
-	// TODO
+	client := comdirect.NewWithAuthOptions(&comdirect.AuthOptions{})
+	if client.IsAuthenticated() {
+		t.Error("expected new client to be unauthenticated")
+	}
 }
 
 func TestClient_Authenticate(t *testing.T) {
-	// TODO
+	client, _ := newTestClient(t)
+	auth := client.GetAuthentication()
+	if auth.AccessToken().AccessToken == "" || auth.AccessToken().RefreshToken == "" {
+		t.Errorf("expected access and refresh token: %+v", auth.AccessToken())
+	}
+	if len(auth.SessionID()) != 32 {
+		t.Errorf("expected session ID of length 32: %s", auth.SessionID())
+	}
+}
+
+func TestClient_Authenticate_InvalidCredentials(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	options := server.AuthOptions()
+	options.Password = "wrong"
+	client := comdirect.NewWithAuthOptions(options, server.ClientOptions()...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err == nil {
+		t.Error("expected authentication with invalid credentials to fail")
+	}
+}
+
+func TestClient_Authenticate_PendingApproval(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	server.SetAutoApprove(false)
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), server.ClientOptions()...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err == nil {
+		t.Error("expected authentication to time out while TAN is pending")
+	}
 }
 
 func TestClient_Refresh(t *testing.T) {
-	// TODO
+	client, _ := newTestClient(t)
+	before := client.GetAuthentication().AccessToken()
+
+	auth, err := client.Refresh()
+	if err != nil {
+		t.Fatalf("failed to refresh access token: %s", err)
+	}
+	if auth.AccessToken().AccessToken == before.AccessToken {
+		t.Error("expected a new access token after refresh")
+	}
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Balances(ctx); err != nil {
+		t.Errorf("failed to use refreshed access token: %s", err)
+	}
 }
 
 func TestClient_Revoke(t *testing.T) {
-	// TODO
+	client, server := newTestClient(t)
+	auth := client.GetAuthentication()
+
+	if err := client.Revoke(); err != nil {
+		t.Fatalf("failed to revoke access token: %s", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("expected client to be unauthenticated after revoke")
+	}
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	_, err := comdirect.NewWithAuthentication(auth, server.ClientOptions()...).Balances(ctx)
+	if !errors.Is(err, comdirect.ErrUnauthorized) {
+		t.Errorf("expected revoked access token to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_AutoRefresh(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	server.SetTokenLifetime(20 * time.Second)
+
+	var mu sync.Mutex
+	var refreshed []*comdirect.Authentication
+	opts := append(server.ClientOptions(),
+		comdirect.WithAutoRefresh(time.Minute),
+		comdirect.WithOnTokenRefreshed(func(auth *comdirect.Authentication) {
+			mu.Lock()
+			defer mu.Unlock()
+			refreshed = append(refreshed, auth)
+		}))
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	initial, err := client.Authenticate(ctx)
+	if err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	server.SetTokenLifetime(10 * time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Balances(ctx); err != nil {
+				t.Errorf("failed to exchange account balances: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(refreshed) != 1 {
+		t.Fatalf("expected exactly one refresh, got %d", len(refreshed))
+	}
+	if refreshed[0] != client.GetAuthentication() || refreshed[0].AccessToken().AccessToken == initial.AccessToken().AccessToken {
+		t.Error("expected callback to receive the new Authentication")
+	}
+}
+
+func TestClient_OnTokenRefreshed_UsesClient(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	server.SetTokenLifetime(20 * time.Second)
+
+	var client *comdirect.Client
+	var current *comdirect.Authentication
+	opts := append(server.ClientOptions(),
+		comdirect.WithAutoRefresh(time.Minute),
+		comdirect.WithOnTokenRefreshed(func(auth *comdirect.Authentication) {
+			// must not deadlock, the callback is called without holding the lock of the client
+			current = client.GetAuthentication()
+		}))
+	client = comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	server.SetTokenLifetime(10 * time.Minute)
+	if _, err := client.Balances(ctx); err != nil {
+		t.Fatalf("failed to exchange account balances: %s", err)
+	}
+	if current == nil || current != client.GetAuthentication() {
+		t.Error("expected callback to see the refreshed Authentication of the client")
+	}
+}
+
+func TestClient_ExpiredWithoutAutoRefresh(t *testing.T) {
+	auth := comdirect.NewAuthentication(comdirect.AccessToken{AccessToken: "token", ExpiresIn: 600}, "session", time.Now().Add(-time.Hour))
+	client := comdirect.NewWithAuthentication(auth, comdirect.WithAutoRefresh(0))
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Balances(ctx); !errors.Is(err, comdirect.ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated, got: %v", err)
+	}
+}
+
+func TestClient_KeepAlive(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	server.SetTokenLifetime(2 * time.Second)
+
+	refreshed := make(chan *comdirect.Authentication, 1)
+	opts := append(server.ClientOptions(),
+		comdirect.WithAutoRefresh(time.Second),
+		comdirect.WithOnTokenRefreshed(func(auth *comdirect.Authentication) {
+			refreshed <- auth
+		}))
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	server.SetTokenLifetime(10 * time.Minute)
+
+	done := make(chan error)
+	go func() {
+		done <- client.KeepAlive(ctx)
+	}()
+	select {
+	case <-refreshed:
+	case err := <-done:
+		t.Fatalf("keep alive stopped early: %v", err)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected keep alive to stop with context.Canceled, got: %v", err)
+	}
+}
+
+// newTestClient returns a Client authenticated against a new comdirecttest.Server.
+func newTestClient(t *testing.T) (*comdirect.Client, *comdirecttest.Server) {
+	t.Helper()
+	server := comdirecttest.NewServer()
+	t.Cleanup(server.Close)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	client, err := server.AuthenticatedClient(ctx)
+	if err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	return client, server
+}
+
+func contextTimeout10Seconds() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Second*10)
 }
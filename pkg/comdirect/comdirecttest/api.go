@@ -0,0 +1,216 @@
+package comdirecttest
+
+import (
+	"net/http"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// handleBalances implements GET /api/banking/clients/user/v2/accounts/balances.
+func (s *Server) handleBalances(w http.ResponseWriter, r *http.Request, _ *token) {
+	values, paging := page(r, s.fixtures.Balances)
+	writeJSON(w, http.StatusOK, comdirect.AccountBalances{Paging: paging, Values: values})
+}
+
+// handleBalance implements GET /api/banking/v2/accounts/{accountID}/balances.
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request, _ *token) {
+	balance, ok := s.balance(r.PathValue("accountID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "account.not.found", "Account not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// handleTransactions implements GET /api/banking/v1/accounts/{accountID}/transactions.
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request, _ *token) {
+	accountID := r.PathValue("accountID")
+	if _, ok := s.balance(accountID); !ok {
+		writeError(w, http.StatusNotFound, "account.not.found", "Account not found")
+		return
+	}
+	transactions, err := filterTransactions(r, s.fixtures.Transactions[accountID])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "request.query.invalid", err.Error())
+		return
+	}
+	values, paging := page(r, transactions)
+	writeJSON(w, http.StatusOK, comdirect.AccountTransactions{Paging: paging, Values: values})
+}
+
+// filterTransactions applies the booking status, booking date and direction filters of the request.
+// Transactions without booking date are excluded if the request filters by booking date.
+func filterTransactions(r *http.Request, transactions []comdirect.AccountTransaction) ([]comdirect.AccountTransaction, error) {
+	query := r.URL.Query()
+	from, err := comdirect.ParseDate(query.Get(comdirect.MinBookingDateQueryKey))
+	if err != nil {
+		return nil, err
+	}
+	to, err := comdirect.ParseDate(query.Get(comdirect.MaxBookingDateQueryKey))
+	if err != nil {
+		return nil, err
+	}
+	status := comdirect.BookingStatus(query.Get(comdirect.BookingStatusQueryKey))
+	direction := comdirect.TransactionDirection(query.Get(comdirect.TransactionDirectionQueryKey))
+
+	filtered := []comdirect.AccountTransaction{}
+	for _, t := range transactions {
+		switch {
+		case status != "" && status != comdirect.BookingStatusBoth && comdirect.BookingStatus(t.BookingStatus) != status:
+		case (!from.IsZero() || !to.IsZero()) && t.BookingDate.IsZero():
+		case !from.IsZero() && t.BookingDate.Before(from):
+		case !to.IsZero() && t.BookingDate.After(to):
+		case direction == comdirect.TransactionDirectionCredit && t.Amount.Value.Sign() < 0:
+		case direction == comdirect.TransactionDirectionDebit && t.Amount.Value.Sign() >= 0:
+		default:
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// handleDepots implements GET /api/brokerage/clients/user/v3/depots.
+func (s *Server) handleDepots(w http.ResponseWriter, r *http.Request, _ *token) {
+	values, paging := page(r, s.fixtures.Depots)
+	writeJSON(w, http.StatusOK, comdirect.Depots{Paging: paging, Values: values})
+}
+
+// handlePositions implements GET /api/brokerage/v3/depots/{depotID}/positions.
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request, _ *token) {
+	depot, ok := s.depot(r.PathValue("depotID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "depot.not.found", "Depot not found")
+		return
+	}
+	values, paging := page(r, s.fixtures.Positions[depot.DepotId])
+	writeJSON(w, http.StatusOK, comdirect.DepotPositions{
+		Paging:     paging,
+		Aggregated: comdirect.DepotAggregated{Depot: depot},
+		Values:     values,
+	})
+}
+
+// handlePosition implements GET /api/brokerage/v3/depots/{depotID}/positions/{positionID}.
+func (s *Server) handlePosition(w http.ResponseWriter, r *http.Request, _ *token) {
+	for _, p := range s.fixtures.Positions[r.PathValue("depotID")] {
+		if p.PositionId == r.PathValue("positionID") {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "position.not.found", "Position not found")
+}
+
+// handleDepotTransactions implements GET /api/brokerage/v3/depots/{depotID}/transactions.
+func (s *Server) handleDepotTransactions(w http.ResponseWriter, r *http.Request, _ *token) {
+	depot, ok := s.depot(r.PathValue("depotID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "depot.not.found", "Depot not found")
+		return
+	}
+	values, paging := page(r, s.fixtures.DepotTransactions[depot.DepotId])
+	writeJSON(w, http.StatusOK, comdirect.DepotTransactions{Paging: paging, Values: values})
+}
+
+// handleInstrument implements GET /api/brokerage/v1/instruments/{instrument} for WKN, ISIN, mnemonic or ID.
+func (s *Server) handleInstrument(w http.ResponseWriter, r *http.Request, _ *token) {
+	id := r.PathValue("instrument")
+	values := []comdirect.Instrument{}
+	for _, i := range s.fixtures.Instruments {
+		if i.InstrumentID == id || i.WKN == id || i.ISIN == id || i.Mnemonic == id {
+			values = append(values, i)
+		}
+	}
+	writeJSON(w, http.StatusOK, comdirect.Instruments{Values: values})
+}
+
+// handleDimensions implements GET /api/brokerage/v3/orders/dimensions.
+func (s *Server) handleDimensions(w http.ResponseWriter, r *http.Request, _ *token) {
+	values, paging := page(r, s.fixtures.Dimensions)
+	writeJSON(w, http.StatusOK, comdirect.Dimensions{Paging: paging, Values: values})
+}
+
+// handleOrders implements GET /api/brokerage/depots/{depotID}/v3/orders including the filters
+// for the order status, side, order type, venue and instrument.
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request, _ *token) {
+	depot, ok := s.depot(r.PathValue("depotID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "depot.not.found", "Depot not found")
+		return
+	}
+	query := r.URL.Query()
+	matches := func(value string, key string) bool {
+		return query.Get(key) == "" || query.Get(key) == value
+	}
+	orders := []comdirect.Order{}
+	for _, o := range s.fixtures.Orders[depot.DepotId] {
+		if matches(string(o.OrderStatus), comdirect.OrderStatusQueryKey) && matches(string(o.Side), comdirect.SideQueryKey) &&
+			matches(string(o.OrderType), comdirect.OrderTypeQueryKey) && matches(o.VenueID, comdirect.VenueIDQueryKey) &&
+			matches(o.InstrumentID, comdirect.InstrumentIDQueryKey) {
+			orders = append(orders, o)
+		}
+	}
+	values, paging := page(r, orders)
+	writeJSON(w, http.StatusOK, comdirect.Orders{Paging: paging, Values: values})
+}
+
+// handleOrder implements GET /api/brokerage/v3/orders/{orderID}.
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request, _ *token) {
+	for _, orders := range s.fixtures.Orders {
+		for _, o := range orders {
+			if o.OrderID == r.PathValue("orderID") {
+				writeJSON(w, http.StatusOK, o)
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "order.not.found", "Order not found")
+}
+
+// handleDocuments implements GET /api/messages/clients/user/v2/documents.
+func (s *Server) handleDocuments(w http.ResponseWriter, r *http.Request, _ *token) {
+	values, paging := page(r, s.fixtures.Documents)
+	writeJSON(w, http.StatusOK, comdirect.Documents{Paging: paging, Values: values})
+}
+
+// handleDocument implements GET /api/messages/v2/documents/{documentID} and returns the document content.
+func (s *Server) handleDocument(w http.ResponseWriter, r *http.Request, _ *token) {
+	for _, d := range s.fixtures.Documents {
+		if d.DocumentID == r.PathValue("documentID") {
+			w.Header().Set("Content-Type", d.MimeType)
+			_, _ = w.Write(s.fixtures.DocumentContents[d.DocumentID])
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "document.not.found", "Document not found")
+}
+
+// handleReports implements GET /api/reports/participants/user/v1/allbalances.
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request, _ *token) {
+	values, paging := page(r, s.fixtures.Reports)
+	writeJSON(w, http.StatusOK, comdirect.Reports{
+		Paging:           paging,
+		ReportAggregated: s.fixtures.ReportAggregated,
+		Values:           values,
+	})
+}
+
+// balance looks up an account balance by account ID. The caller must hold s.mu.
+func (s *Server) balance(accountID string) (comdirect.AccountBalance, bool) {
+	for _, b := range s.fixtures.Balances {
+		if b.AccountId == accountID {
+			return b, true
+		}
+	}
+	return comdirect.AccountBalance{}, false
+}
+
+// depot looks up a depot by depot ID. The caller must hold s.mu.
+func (s *Server) depot(depotID string) (comdirect.Depot, bool) {
+	for _, d := range s.fixtures.Depots {
+		if d.DepotId == depotID {
+			return d, true
+		}
+	}
+	return comdirect.Depot{}, false
+}
@@ -0,0 +1,359 @@
+package comdirecttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+type tokenKind int
+
+const (
+	primaryToken tokenKind = iota
+	secondaryToken
+	anyToken
+)
+
+// token is an access token issued by the Server.
+type token struct {
+	accessToken  string
+	refreshToken string
+	kind         tokenKind
+	expiresAt    time.Time
+	session      *session
+}
+
+// session is the TAN session bound to a primary access token.
+type session struct {
+	Identifier       string `json:"identifier"`
+	SessionTanActive bool   `json:"sessionTanActive"`
+	Activated2FA     bool   `json:"activated2FA"`
+}
+
+// challenge is a TAN challenge created by validating a session or a transaction.
+type challenge struct {
+	id       string
+	typ      string
+	session  *session
+	subject  string
+	approved bool
+}
+
+// sessionSubject is the subject of TAN challenges that activate a session.
+const sessionSubject = "session"
+
+type onceAuthenticationInfo struct {
+	ID             string   `json:"id"`
+	Typ            string   `json:"typ"`
+	Challenge      string   `json:"challenge,omitempty"`
+	AvailableTypes []string `json:"availableTypes,omitempty"`
+	Link           *link    `json:"link,omitempty"`
+}
+
+type link struct {
+	Href   string `json:"href"`
+	Rel    string `json:"rel"`
+	Method string `json:"method"`
+}
+
+type authStatus struct {
+	AuthenticationID string `json:"authenticationId"`
+	Status           string `json:"status"`
+}
+
+// handleToken implements the password, cd_secondary and refresh_token grants of POST /oauth/token.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != ClientID || r.PostForm.Get("client_secret") != ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid_client", "Bad client credentials")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var t *token
+	switch r.PostForm.Get("grant_type") {
+	case comdirect.PasswordGrantType:
+		if r.PostForm.Get("username") != Username || r.PostForm.Get("password") != Password {
+			writeError(w, http.StatusUnauthorized, "invalid_grant", "Bad credentials")
+			return
+		}
+		t = s.issueToken(primaryToken, nil)
+	case comdirect.SecondaryGrantType:
+		primary, ok := s.tokens[r.PostForm.Get("token")]
+		if !ok || primary.kind != primaryToken || primary.expired() {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Invalid primary access token")
+			return
+		}
+		if primary.session == nil || !primary.session.SessionTanActive {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Session TAN is not activated")
+			return
+		}
+		t = s.issueToken(secondaryToken, primary.session)
+	case comdirect.RefreshTokenGrantType:
+		old, ok := s.refreshTokens[r.PostForm.Get("refresh_token")]
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid_grant", "Invalid refresh token")
+			return
+		}
+		delete(s.refreshTokens, old.refreshToken)
+		delete(s.tokens, old.accessToken)
+		t = s.issueToken(old.kind, old.session)
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type")
+		return
+	}
+
+	scope := "TWO_FACTOR"
+	if t.kind == secondaryToken {
+		scope = "BANKING_RW BROKERAGE_RW SESSION_RW"
+	}
+	writeJSON(w, http.StatusOK, comdirect.AccessToken{
+		AccessToken:  t.accessToken,
+		TokenType:    "bearer",
+		RefreshToken: t.refreshToken,
+		ExpiresIn:    int(time.Until(t.expiresAt).Seconds()),
+		Scope:        scope,
+		CustomerID:   "1234567890",
+		BPID:         1234567,
+		ContactID:    123456789,
+	})
+}
+
+// handleRevoke implements DELETE /oauth/token.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[bearerToken(r)]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "Invalid access token")
+		return
+	}
+	delete(s.tokens, t.accessToken)
+	delete(s.refreshTokens, t.refreshToken)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSessions implements GET /api/session/clients/user/v1/sessions.
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request, t *token) {
+	if t.session == nil {
+		t.session = &session{Identifier: s.nextID("session-")}
+	}
+	writeJSON(w, http.StatusOK, []session{*t.session})
+}
+
+// handleValidateSession implements POST /api/session/clients/user/v1/sessions/{sessionID}/validate
+// and returns a TAN challenge in the x-once-authentication-info header.
+func (s *Server) handleValidateSession(w http.ResponseWriter, r *http.Request, t *token) {
+	if t.session == nil || t.session.Identifier != r.PathValue("sessionID") {
+		writeError(w, http.StatusNotFound, "session.not.found", "Session not found")
+		return
+	}
+	var body session
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return
+	}
+
+	info, ok := s.newChallenge(w, r, t.session, sessionSubject)
+	if !ok {
+		return
+	}
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusCreated, t.session)
+}
+
+// newChallenge creates a TAN challenge of the type requested in the x-once-authentication-info header,
+// P_TAN_PUSH by default, and returns the response header value. The challenge can only be used for the
+// given subject, e.g. the validated transaction. The caller must hold s.mu.
+func (s *Server) newChallenge(w http.ResponseWriter, r *http.Request, session *session, subject string) (string, bool) {
+	var requested onceAuthenticationInfo
+	if header := r.Header.Get(comdirect.OnceAuthenticationInfoHeaderKey); header != "" {
+		if err := json.Unmarshal([]byte(header), &requested); err != nil {
+			writeError(w, http.StatusBadRequest, "header.invalid", "Invalid x-once-authentication-info header")
+			return "", false
+		}
+	}
+
+	c := &challenge{id: s.nextID("challenge-"), typ: string(comdirect.TANTypePush), session: session, subject: subject}
+	info := onceAuthenticationInfo{
+		ID:             c.id,
+		AvailableTypes: []string{string(comdirect.TANTypePush), string(comdirect.TANTypePhoto), string(comdirect.TANTypeMobile)},
+	}
+	switch comdirect.TANType(requested.Typ) {
+	case "", comdirect.TANTypePush:
+		info.Link = &link{
+			Href:   "/api/session/v1/authentications/" + c.id,
+			Rel:    "self",
+			Method: http.MethodGet,
+		}
+	case comdirect.TANTypePhoto:
+		c.typ = requested.Typ
+		info.Challenge = base64.StdEncoding.EncodeToString(PhotoTANImage())
+	case comdirect.TANTypeMobile:
+		c.typ = requested.Typ
+		info.Challenge = "+49 170 *****23"
+	default:
+		writeError(w, http.StatusUnprocessableEntity, "tan.type.invalid", "Unsupported TAN type")
+		return "", false
+	}
+	info.Typ = c.typ
+	s.challenges[c.id] = c
+
+	header, _ := json.Marshal(info)
+	return string(header), true
+}
+
+// verifyChallenge checks the x-once-authentication-info and x-once-authentication headers against
+// a previously created challenge and removes it on success. The caller must hold s.mu.
+func (s *Server) verifyChallenge(w http.ResponseWriter, r *http.Request, session *session, subject string) bool {
+	var info onceAuthenticationInfo
+	if err := json.Unmarshal([]byte(r.Header.Get(comdirect.OnceAuthenticationInfoHeaderKey)), &info); err != nil {
+		writeError(w, http.StatusBadRequest, "header.invalid", "Invalid x-once-authentication-info header")
+		return false
+	}
+	c, ok := s.challenges[info.ID]
+	if !ok || c.session != session || c.subject != subject {
+		writeError(w, http.StatusUnprocessableEntity, "authentication.invalid", "Unknown TAN challenge")
+		return false
+	}
+	if c.typ != string(comdirect.TANTypePush) {
+		c.approved = r.Header.Get(comdirect.OnceAuthenticationHeaderKey) == TAN
+		if !c.approved {
+			writeError(w, http.StatusUnprocessableEntity, "TAN_UNGUELTIG", "The TAN is invalid")
+			return false
+		}
+	}
+	if !c.approved {
+		writeError(w, http.StatusUnprocessableEntity, "authentication.pending", "TAN challenge was not approved")
+		return false
+	}
+	delete(s.challenges, c.id)
+	return true
+}
+
+// handleAuthenticationStatus implements the status endpoint that is polled for push TAN challenges.
+func (s *Server) handleAuthenticationStatus(w http.ResponseWriter, r *http.Request, t *token) {
+	c, ok := s.challenges[r.PathValue("challengeID")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "authentication.not.found", "Authentication not found")
+		return
+	}
+	if s.autoApprove {
+		c.approved = true
+	}
+	status := "PENDING"
+	if c.approved {
+		status = "AUTHENTICATED"
+	}
+	writeJSON(w, http.StatusOK, authStatus{AuthenticationID: c.id, Status: status})
+}
+
+// handleActivateSession implements PATCH /api/session/clients/user/v1/sessions/{sessionID}.
+func (s *Server) handleActivateSession(w http.ResponseWriter, r *http.Request, t *token) {
+	if t.session == nil || t.session.Identifier != r.PathValue("sessionID") {
+		writeError(w, http.StatusNotFound, "session.not.found", "Session not found")
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, sessionSubject) {
+		return
+	}
+	t.session.SessionTanActive = true
+	t.session.Activated2FA = true
+	writeJSON(w, http.StatusOK, t.session)
+}
+
+// issueToken creates a new access and refresh token. The caller must hold s.mu.
+func (s *Server) issueToken(kind tokenKind, session *session) *token {
+	t := &token{
+		accessToken:  s.nextID("access-"),
+		refreshToken: s.nextID("refresh-"),
+		kind:         kind,
+		expiresAt:    time.Now().Add(s.tokenLifetime),
+		session:      session,
+	}
+	s.tokens[t.accessToken] = t
+	s.refreshTokens[t.refreshToken] = t
+	return t
+}
+
+func (t *token) expired() bool {
+	return !time.Now().Before(t.expiresAt)
+}
+
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get(comdirect.AuthorizationHeaderKey), comdirect.BearerPrefix)
+}
+
+type tokenHandlerFunc func(w http.ResponseWriter, r *http.Request, t *token)
+
+// primary only accepts requests with a valid access token of the password grant.
+func (s *Server) primary(next tokenHandlerFunc) http.HandlerFunc {
+	return s.authorize(primaryToken, next)
+}
+
+// authenticated accepts requests with any valid access token.
+func (s *Server) authenticated(next tokenHandlerFunc) http.HandlerFunc {
+	return s.authorize(anyToken, next)
+}
+
+// secondary only accepts requests with a valid access token of the secondary grant.
+func (s *Server) secondary(next tokenHandlerFunc) http.HandlerFunc {
+	return s.authorize(secondaryToken, next)
+}
+
+func (s *Server) authorize(kind tokenKind, next tokenHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestInfo := r.Header.Get(comdirect.HttpRequestInfoHeaderKey)
+		if requestInfo == "" {
+			writeError(w, http.StatusBadRequest, "header.missing", "x-http-request-info header missing")
+			return
+		}
+		w.Header().Set(comdirect.HttpRequestInfoHeaderKey, requestInfo)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		t, ok := s.tokens[bearerToken(r)]
+		if !ok || kind != anyToken && t.kind != kind || t.expired() {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Access token expired or invalid")
+			return
+		}
+		next(w, r, t)
+	}
+}
+
+// PhotoTANImage returns the PNG image sent with photoTAN challenges, a mosaic of colored squares.
+func PhotoTANImage() []byte {
+	const size, cells = 64, 8
+	palette := []color.RGBA{
+		{R: 0, G: 0, B: 0, A: 255},
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 0, G: 255, B: 0, A: 255},
+		{R: 0, G: 0, B: 255, A: 255},
+		{R: 255, G: 255, B: 255, A: 255},
+	}
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			cx, cy := x*cells/size, y*cells/size
+			img.Set(x, y, palette[(cx*3+cy*5+cx*cy)%len(palette)])
+		}
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
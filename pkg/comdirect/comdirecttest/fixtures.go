@@ -0,0 +1,222 @@
+package comdirecttest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+const (
+	AccountID  = "9A5F6D1E4B8C4A2F8E3D7C6B5A4F3E2D"
+	DepotID    = "5B2C8E1F7A3D4C6B9E8F7A6D5C4B3A2E"
+	PositionID = "24681357"
+	DocumentID = "C7D6E5F4A3B2C1D0E9F8A7B6C5D4E3F2"
+
+	InstrumentID = "A1B2C3D4E5F6A7B8C9D0E1F2A3B4C5D6"
+	VenueID      = "EDF4ED85-A9E6-4E23-8A3C-36ADC2B0A7D6"
+)
+
+// Fixtures holds the data served by the Server. Maps are keyed by account or depot ID.
+type Fixtures struct {
+	Balances          []comdirect.AccountBalance
+	Transactions      map[string][]comdirect.AccountTransaction
+	Depots            []comdirect.Depot
+	Positions         map[string][]comdirect.DepotPosition
+	DepotTransactions map[string][]comdirect.DepotTransaction
+	Instruments       []comdirect.Instrument
+	Dimensions        []comdirect.Dimension
+	Orders            map[string][]comdirect.Order
+	Documents         []comdirect.Document
+	DocumentContents  map[string][]byte
+	Reports           []comdirect.Report
+	ReportAggregated  comdirect.ReportAggregated
+}
+
+// DefaultFixtures returns a giro account with 30 transactions, a depot with a single position
+// and transaction, one postbox document and the corresponding reports.
+func DefaultFixtures() Fixtures {
+	account := comdirect.Account{
+		AccountID:        AccountID,
+		AccountDisplayID: "1234567890",
+		Currency:         "EUR",
+		ClientID:         "0123456789ABCDEF0123456789ABCDEF",
+		AccountType:      comdirect.AccountType{Key: "CA", Text: "Girokonto"},
+		Iban:             "DE89370400440532013000",
+		CreditLimit:      eur("500"),
+	}
+	balance := comdirect.AccountBalance{
+		Account:                account,
+		AccountId:              AccountID,
+		Balance:                eur("2500.25"),
+		BalanceEUR:             eur("2500.25"),
+		AvailableCashAmount:    eur("3000.25"),
+		AvailableCashAmountEUR: eur("3000.25"),
+	}
+	depot := comdirect.Depot{
+		DepotId:                    DepotID,
+		DepotDisplayId:             "987654321",
+		ClientId:                   account.ClientID,
+		DefaultSettlementAccountId: AccountID,
+		SettlementAccountIds:       []string{AccountID},
+		HolderName:                 "Max Mustermann",
+	}
+	instrument := comdirect.Instrument{
+		InstrumentID: InstrumentID,
+		WKN:          "865985",
+		ISIN:         "US0378331005",
+		Mnemonic:     "APC",
+		Name:         "Apple Inc. Registered Shares o.N.",
+		ShortName:    "Apple Inc.",
+		StaticData: comdirect.StaticData{
+			Notation:       "XETRA",
+			Currency:       "EUR",
+			InstrumentType: "SHARE",
+		},
+	}
+	position := comdirect.DepotPosition{
+		DepotId:               DepotID,
+		PositionId:            PositionID,
+		Wkn:                   instrument.WKN,
+		InstrumentID:          instrument.InstrumentID,
+		Instrument:            instrument,
+		CustodyType:           "CUSTODY",
+		Quantity:              comdirect.AmountValue{Value: comdirect.NewDecimal(10, 0), Unit: "XXX"},
+		AvailableQuantity:     comdirect.AmountValue{Value: comdirect.NewDecimal(10, 0), Unit: "XXX"},
+		CurrentPrice:          comdirect.Price{Price: eur("170.5"), PriceDateTime: comdirect.MustParseTimestamp("2024-03-28T17:35:00+01:00")},
+		PrevDayPrice:          comdirect.Price{Price: eur("168.2"), PriceDateTime: comdirect.MustParseTimestamp("2024-03-27T17:35:00+01:00")},
+		CurrentValue:          eur("1705"),
+		PurchaseValue:         eur("1500"),
+		ProfitLossPurchaseAbs: eur("205"),
+		ProfitLossPurchaseRel: "13.667",
+		ProfitLossPrevDayAbs:  eur("23"),
+		ProfitLossPrevDayRel:  "1.367",
+	}
+	document := comdirect.Document{
+		DocumentID:       DocumentID,
+		Name:             "Finanzreport Nr. 03 per 01.03.2024",
+		DateCreation:     comdirect.NewDate(2024, time.March, 1),
+		MimeType:         "application/pdf",
+		Deletable:        false,
+		DocumentMetaData: comdirect.DocumentMetaData{AlreadyRead: false},
+	}
+
+	return Fixtures{
+		Balances:     []comdirect.AccountBalance{balance},
+		Transactions: map[string][]comdirect.AccountTransaction{AccountID: defaultTransactions()},
+		Depots:       []comdirect.Depot{depot},
+		Positions:    map[string][]comdirect.DepotPosition{DepotID: {position}},
+		DepotTransactions: map[string][]comdirect.DepotTransaction{DepotID: {{
+			TransactionID:        "T0000001",
+			BookingStatus:        "BOOKED",
+			BookingDate:          comdirect.NewDate(2024, time.March, 1),
+			BusinessDate:         comdirect.NewDate(2024, time.February, 28),
+			Quantity:             comdirect.AmountValue{Value: comdirect.NewDecimal(10, 0), Unit: "XXX"},
+			InstrumentID:         instrument.InstrumentID,
+			Instrument:           instrument,
+			ExecutionPrice:       eur("150"),
+			TransactionValue:     eur("1500"),
+			TransactionDirection: "IN",
+			TransactionType:      "BUY",
+		}}},
+		Instruments: []comdirect.Instrument{instrument},
+		Dimensions: []comdirect.Dimension{{Venues: []comdirect.Venue{{
+			Name:          "Xetra",
+			VenueID:       VenueID,
+			Country:       "DE",
+			Type:          "EXCHANGE",
+			Currencies:    []string{"EUR"},
+			Sides:         []comdirect.OrderSide{comdirect.OrderSideBuy, comdirect.OrderSideSell},
+			ValidityTypes: []comdirect.ValidityType{comdirect.ValidityTypeGoodForDay, comdirect.ValidityTypeGoodTillDate},
+			OrderTypes: comdirect.OrderTypes{
+				comdirect.OrderTypeQuote:  {},
+				comdirect.OrderTypeMarket: {},
+				comdirect.OrderTypeLimit:  {LimitExtensions: []comdirect.LimitExtension{comdirect.LimitExtensionAllOrNone}},
+			},
+		}}}},
+		Orders:           map[string][]comdirect.Order{DepotID: {}},
+		Documents:        []comdirect.Document{document},
+		DocumentContents: map[string][]byte{DocumentID: []byte("%PDF-1.4\n%comdirecttest\n")},
+		Reports: []comdirect.Report{
+			{
+				ProductID:            AccountID,
+				ProductType:          "ACCOUNT",
+				TargetClientID:       account.ClientID,
+				ClientConnectionType: "CLIENT",
+				Balance: comdirect.ReportBalance{
+					Account:                account,
+					AccountId:              AccountID,
+					Balance:                balance.Balance,
+					BalanceEUR:             balance.BalanceEUR,
+					AvailableCashAmount:    balance.AvailableCashAmount,
+					AvailableCashAmountEUR: balance.AvailableCashAmountEUR,
+				},
+			},
+			{
+				ProductID:            DepotID,
+				ProductType:          "DEPOT",
+				TargetClientID:       account.ClientID,
+				ClientConnectionType: "CLIENT",
+				Balance: comdirect.ReportBalance{
+					Depot:          depot,
+					DepotID:        DepotID,
+					DateLastUpdate: comdirect.MustParseTimestamp("2024-03-28 17:35:00"),
+					PrevDayValue:   eur("1682"),
+				},
+			},
+		},
+		ReportAggregated: comdirect.ReportAggregated{
+			BalanceEUR:             eur("4205.25"),
+			AvailableCashAmountEUR: eur("3000.25"),
+		},
+	}
+}
+
+// defaultTransactions returns one pending and 29 booked transactions ordered by booking date, newest first.
+func defaultTransactions() []comdirect.AccountTransaction {
+	transactions := []comdirect.AccountTransaction{{
+		Reference:       "",
+		BookingStatus:   "NOTBOOKED",
+		Amount:          eur("-42.99"),
+		Creditor:        comdirect.Creditor{HolderName: "Online Shop GmbH"},
+		RemittanceInfo:  "01Bestellung 4711",
+		TransactionType: comdirect.TransactionType{Key: "CARD_TRANSACTION", Text: "Kartenverfügung"},
+	}}
+
+	day := comdirect.NewDate(2024, time.March, 28)
+	for i := 0; i < 29; i++ {
+		date := day.AddDays(-3 * i)
+		t := comdirect.AccountTransaction{
+			Reference:     fmt.Sprintf("3C2K%08d/1", 29-i),
+			BookingStatus: "BOOKED",
+			BookingDate:   date,
+			ValutaDate:    date,
+		}
+		switch i % 3 {
+		case 0:
+			t.Amount = eur("-54.10")
+			t.Creditor = comdirect.Creditor{HolderName: "Supermarkt AG", Iban: "DE02120300000000202051", Bic: "BYLADEM1001"}
+			t.RemittanceInfo = "01Einkauf Filiale 123"
+			t.TransactionType = comdirect.TransactionType{Key: "DIRECT_DEBIT", Text: "Lastschrift / Belastung"}
+			t.DirectDebitCreditorID = "DE98ZZZ09999999999"
+			t.DirectDebitMandateID = "M-0001"
+		case 1:
+			t.Amount = eur("-850")
+			t.Creditor = comdirect.Creditor{HolderName: "Hausverwaltung Müller", Iban: "DE02500105170137075030", Bic: "INGDDEFFXXX"}
+			t.RemittanceInfo = "01Miete"
+			t.TransactionType = comdirect.TransactionType{Key: "TRANSFER", Text: "Übertrag / Überweisung"}
+		case 2:
+			t.Amount = eur("1200")
+			t.Remitter = comdirect.Remitter{HolderName: "Arbeitgeber GmbH"}
+			t.RemittanceInfo = "01Gehalt"
+			t.TransactionType = comdirect.TransactionType{Key: "TRANSFER", Text: "Übertrag / Überweisung"}
+		}
+		t.EndToEndReference = fmt.Sprintf("E2E-%08d", 29-i)
+		transactions = append(transactions, t)
+	}
+	return transactions
+}
+
+func eur(value string) comdirect.AmountValue {
+	return comdirect.AmountValue{Value: comdirect.MustParseDecimal(value), Unit: "EUR"}
+}
@@ -0,0 +1,334 @@
+package comdirecttest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// handlePreValidateOrder implements POST /api/brokerage/v3/orders/prevalidation.
+func (s *Server) handlePreValidateOrder(w http.ResponseWriter, r *http.Request, _ *token) {
+	order, ok := s.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleExAnteOrder implements POST /api/brokerage/v3/orders/costindicationexante. The order costs are
+// a commission of 4.90 EUR plus 0.25 % of the order volume, at least 9.90 EUR and at most 59.90 EUR, the
+// third-party fees are 1.50 EUR and shares have no product costs.
+func (s *Server) handleExAnteOrder(w http.ResponseWriter, r *http.Request, _ *token) {
+	order, ok := s.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+	price, ok := s.orderPrice(order)
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "price.not.available", "No price available for the instrument")
+		return
+	}
+
+	volume := price.Mul(order.Quantity.Value).Round(2)
+	orderCosts := comdirect.MustParseDecimal("4.90").Add(volume.Mul(comdirect.MustParseDecimal("0.0025"))).Round(2)
+	if minimum := comdirect.MustParseDecimal("9.90"); orderCosts.Cmp(minimum) < 0 {
+		orderCosts = minimum
+	}
+	if maximum := comdirect.MustParseDecimal("59.90"); orderCosts.Cmp(maximum) > 0 {
+		orderCosts = maximum
+	}
+	thirdPartyFees := comdirect.MustParseDecimal("1.50")
+	productCosts := comdirect.NewDecimal(0, 2)
+	total := orderCosts.Add(thirdPartyFees).Add(productCosts)
+
+	cost := func(amount comdirect.Decimal) comdirect.Cost {
+		return comdirect.Cost{
+			Amount:   comdirect.AmountValue{Value: amount, Unit: "EUR"},
+			Relative: percentage(amount, volume),
+		}
+	}
+	writeJSON(w, http.StatusOK, comdirect.CostIndications{Values: []comdirect.CostIndication{{
+		DepotID:        order.DepotID,
+		InstrumentID:   order.InstrumentID,
+		Side:           order.Side,
+		Quantity:       order.Quantity,
+		OrderVolume:    comdirect.AmountValue{Value: volume, Unit: "EUR"},
+		OrderCosts:     cost(orderCosts),
+		ProductCosts:   cost(productCosts),
+		ThirdPartyFees: cost(thirdPartyFees),
+		TotalCosts:     cost(total),
+		EffectOnReturn: comdirect.EffectOnReturn{
+			FirstYear:      cost(total),
+			FollowingYears: cost(productCosts),
+			LastYear:       cost(productCosts),
+		},
+	}}})
+}
+
+// handleValidateOrder implements POST /api/brokerage/v3/orders/validation and returns a TAN challenge
+// for the order in the x-once-authentication-info header.
+func (s *Server) handleValidateOrder(w http.ResponseWriter, r *http.Request, t *token) {
+	order, ok := s.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+	if order.OrderType == comdirect.OrderTypeQuote {
+		// quote orders are authorized by their quote ticket
+		if _, ok = s.verifyQuote(w, order); ok {
+			writeJSON(w, http.StatusCreated, order)
+		}
+		return
+	}
+	info, ok := s.newChallenge(w, r, t.session, orderSubject("", order))
+	if !ok {
+		return
+	}
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// handleCreateOrder implements POST /api/brokerage/v3/orders. The order must match a validated order
+// and is added as OPEN order to the orders of the depot. Quote orders are executed immediately.
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request, t *token) {
+	request, ok := s.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+	if request.OrderType == comdirect.OrderTypeQuote {
+		s.handleCreateQuoteOrder(w, request)
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, orderSubject("", request)) {
+		return
+	}
+
+	order := comdirect.Order{
+		DepotID:            request.DepotID,
+		OrderID:            s.nextID("order-"),
+		CreationTimestamp:  comdirect.NewTimestamp(time.Now()),
+		OrderType:          request.OrderType,
+		OrderStatus:        comdirect.OrderStatusOpen,
+		Side:               request.Side,
+		InstrumentID:       request.InstrumentID,
+		VenueID:            request.VenueID,
+		Quantity:           request.Quantity,
+		LimitExtension:     request.LimitExtension,
+		TradingRestriction: request.TradingRestriction,
+		ValidityType:       request.ValidityType,
+		Validity:           request.Validity,
+		OpenQuantity:       request.Quantity,
+	}
+	applyLimits(&order, request)
+	if s.fixtures.Orders == nil {
+		s.fixtures.Orders = map[string][]comdirect.Order{}
+	}
+	s.fixtures.Orders[order.DepotID] = append([]comdirect.Order{order}, s.fixtures.Orders[order.DepotID]...)
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// handleValidateOrderChange implements POST /api/brokerage/v3/orders/{orderID}/validation for both the
+// update and the deletion of an order. An empty body validates the deletion.
+func (s *Server) handleValidateOrderChange(w http.ResponseWriter, r *http.Request, t *token) {
+	order, ok := s.openOrder(w, r.PathValue("orderID"))
+	if !ok {
+		return
+	}
+	change, ok := decodeOrderChange(w, r)
+	if !ok {
+		return
+	}
+	subject := orderDeletionSubject(order.OrderID)
+	if change != (comdirect.OrderRequest{}) {
+		if change.OrderID != order.OrderID || change.Quantity.Value.Sign() <= 0 {
+			writeError(w, http.StatusUnprocessableEntity, "order.invalid", "Invalid order change")
+			return
+		}
+		subject = orderSubject(order.OrderID, change)
+	}
+	info, ok := s.newChallenge(w, r, t.session, subject)
+	if !ok {
+		return
+	}
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// handleUpdateOrder implements PATCH /api/brokerage/v3/orders/{orderID} and applies the validated
+// quantity, limits and validity to the order.
+func (s *Server) handleUpdateOrder(w http.ResponseWriter, r *http.Request, t *token) {
+	order, ok := s.openOrder(w, r.PathValue("orderID"))
+	if !ok {
+		return
+	}
+	change, ok := decodeOrderChange(w, r)
+	if !ok {
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, orderSubject(order.OrderID, change)) {
+		return
+	}
+
+	order.Quantity = change.Quantity
+	order.OpenQuantity = change.Quantity
+	applyLimits(order, change)
+	if change.ValidityType != "" {
+		order.ValidityType = change.ValidityType
+		order.Validity = change.Validity
+	}
+	s.saveOrder(*order)
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleDeleteOrder implements DELETE /api/brokerage/v3/orders/{orderID} and cancels the open quantity.
+func (s *Server) handleDeleteOrder(w http.ResponseWriter, r *http.Request, t *token) {
+	order, ok := s.openOrder(w, r.PathValue("orderID"))
+	if !ok {
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, orderDeletionSubject(order.OrderID)) {
+		return
+	}
+
+	order.OrderStatus = comdirect.OrderStatusCancelled
+	order.CancelledQuantity = order.OpenQuantity
+	order.OpenQuantity = comdirect.AmountValue{Value: comdirect.NewDecimal(0, 0), Unit: order.Quantity.Unit}
+	s.saveOrder(*order)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeOrder decodes and validates the order of the request body. The caller must hold s.mu.
+func (s *Server) decodeOrder(w http.ResponseWriter, r *http.Request) (comdirect.OrderRequest, bool) {
+	var order comdirect.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return order, false
+	}
+	if _, ok := s.depot(order.DepotID); !ok {
+		writeError(w, http.StatusNotFound, "depot.not.found", "Depot not found")
+		return order, false
+	}
+	if err := order.Validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "order.invalid", err.Error())
+		return order, false
+	}
+	if !s.hasInstrument(order.InstrumentID) {
+		writeError(w, http.StatusUnprocessableEntity, "instrument.not.found", "Instrument not found")
+		return order, false
+	}
+	venue, ok := comdirect.FindVenue(s.fixtures.Dimensions, order.VenueID)
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "venue.not.found", "Venue not found")
+		return order, false
+	}
+	if err := venue.ValidateOrder(&order); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "order.not.supported", err.Error())
+		return order, false
+	}
+	return order, true
+}
+
+// applyLimits copies the limit, trigger limit and trailing distances set in request to order.
+func applyLimits(order *comdirect.Order, request comdirect.OrderRequest) {
+	if request.Limit != nil {
+		order.Limit = *request.Limit
+	}
+	if request.TriggerLimit != nil {
+		order.TriggerLimit = *request.TriggerLimit
+	}
+	if request.TrailingLimitDistAbs != nil {
+		order.TrailingLimitDistAbs = *request.TrailingLimitDistAbs
+	}
+	if request.TrailingLimitDistRel != nil {
+		order.TrailingLimitDistRel = *request.TrailingLimitDistRel
+	}
+}
+
+// decodeOrderChange decodes the changes of an order from the request body.
+func decodeOrderChange(w http.ResponseWriter, r *http.Request) (comdirect.OrderRequest, bool) {
+	var change comdirect.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return change, false
+	}
+	return change, true
+}
+
+// openOrder looks up an order that can still be changed or deleted. The caller must hold s.mu.
+func (s *Server) openOrder(w http.ResponseWriter, orderID string) (*comdirect.Order, bool) {
+	for _, orders := range s.fixtures.Orders {
+		for _, o := range orders {
+			if o.OrderID != orderID {
+				continue
+			}
+			if o.OrderStatus != comdirect.OrderStatusOpen {
+				writeError(w, http.StatusUnprocessableEntity, "order.not.open", "Order is not open")
+				return nil, false
+			}
+			return &o, true
+		}
+	}
+	writeError(w, http.StatusNotFound, "order.not.found", "Order not found")
+	return nil, false
+}
+
+// saveOrder replaces the order with the same order ID. The caller must hold s.mu.
+func (s *Server) saveOrder(order comdirect.Order) {
+	orders := s.fixtures.Orders[order.DepotID]
+	for i := range orders {
+		if orders[i].OrderID == order.OrderID {
+			orders[i] = order
+		}
+	}
+}
+
+// orderPrice returns the limit of an order or the current price of the instrument in a depot of the
+// fixtures for market orders. The caller must hold s.mu.
+func (s *Server) orderPrice(order comdirect.OrderRequest) (comdirect.Decimal, bool) {
+	if order.Limit != nil {
+		return order.Limit.Value, true
+	}
+	for _, positions := range s.fixtures.Positions {
+		for _, p := range positions {
+			if p.InstrumentID == order.InstrumentID && !p.CurrentPrice.Price.IsZero() {
+				return p.CurrentPrice.Price.Value, true
+			}
+		}
+	}
+	return comdirect.Decimal{}, false
+}
+
+// percentage returns amount as percentage of total rounded to two decimal places.
+func percentage(amount comdirect.Decimal, total comdirect.Decimal) comdirect.Decimal {
+	a, _ := new(big.Rat).SetString(amount.String())
+	t, _ := new(big.Rat).SetString(total.String())
+	if t.Sign() == 0 {
+		return comdirect.NewDecimal(0, 2)
+	}
+	p := new(big.Rat).Mul(new(big.Rat).Quo(a, t), big.NewRat(100, 1))
+	return comdirect.MustParseDecimal(p.FloatString(2))
+}
+
+// hasInstrument reports whether an instrument with the given ID exists. The caller must hold s.mu.
+func (s *Server) hasInstrument(instrumentID string) bool {
+	for _, i := range s.fixtures.Instruments {
+		if i.InstrumentID == instrumentID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasVenue reports whether a venue with the given ID exists. The caller must hold s.mu.
+// orderSubject binds a TAN challenge to the content of a new order or the change of the order with the given ID.
+func orderSubject(orderID string, order comdirect.OrderRequest) string {
+	data, _ := json.Marshal(order)
+	return "order:" + orderID + ":" + string(data)
+}
+
+// orderDeletionSubject binds a TAN challenge to the deletion of an order.
+func orderDeletionSubject(orderID string) string {
+	return "order-deletion:" + orderID
+}
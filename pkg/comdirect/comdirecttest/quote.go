@@ -0,0 +1,179 @@
+package comdirecttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// handleCreateQuoteTicket implements POST /api/brokerage/v3/quoteticket and returns an inactive quote ticket
+// with a TAN challenge in the x-once-authentication-info header.
+func (s *Server) handleCreateQuoteTicket(w http.ResponseWriter, r *http.Request, t *token) {
+	var ticket comdirect.QuoteTicket
+	if err := json.NewDecoder(r.Body).Decode(&ticket); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return
+	}
+	if _, ok := s.depot(ticket.DepotID); !ok {
+		writeError(w, http.StatusNotFound, "depot.not.found", "Depot not found")
+		return
+	}
+	ticket.QuoteTicketID = s.nextID("quoteticket-")
+	ticket.Status = "PENDING"
+	info, ok := s.newChallenge(w, r, t.session, quoteTicketSubject(ticket.QuoteTicketID))
+	if !ok {
+		return
+	}
+	s.quoteTickets[ticket.QuoteTicketID] = &ticket
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusCreated, ticket)
+}
+
+// handleValidateQuoteTicket implements PATCH /api/brokerage/v3/quoteticket/{ticketID}/validation and returns
+// a new TAN challenge for the inactive quote ticket, e.g. of another TAN type.
+func (s *Server) handleValidateQuoteTicket(w http.ResponseWriter, r *http.Request, t *token) {
+	ticket, ok := s.quoteTickets[r.PathValue("ticketID")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "quoteticket.not.found", "Quote ticket not found")
+		return
+	}
+	if ticket.Status != "PENDING" {
+		writeError(w, http.StatusUnprocessableEntity, "quoteticket.invalid", "Quote ticket is already active")
+		return
+	}
+	info, ok := s.newChallenge(w, r, t.session, quoteTicketSubject(ticket.QuoteTicketID))
+	if !ok {
+		return
+	}
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusOK, ticket)
+}
+
+// handleUpdateQuoteTicket implements PATCH /api/brokerage/v3/quoteticket/{ticketID} and activates the
+// quote ticket with the TAN.
+func (s *Server) handleUpdateQuoteTicket(w http.ResponseWriter, r *http.Request, t *token) {
+	ticket, ok := s.quoteTickets[r.PathValue("ticketID")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "quoteticket.not.found", "Quote ticket not found")
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, quoteTicketSubject(ticket.QuoteTicketID)) {
+		return
+	}
+	ticket.Status = "ACTIVE"
+	writeJSON(w, http.StatusOK, ticket)
+}
+
+// handleQuote implements POST /api/brokerage/v3/quotes. The quote is the current price of the instrument
+// plus a spread of 0.1 % for buy and minus 0.1 % for sell quotes and expires after the quote lifetime.
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request, _ *token) {
+	var request comdirect.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return
+	}
+	if ticket, ok := s.quoteTickets[request.QuoteTicketID]; !ok || ticket.Status != "ACTIVE" {
+		writeError(w, http.StatusUnprocessableEntity, "quoteticket.invalid", "Quote ticket is not active")
+		return
+	}
+	if _, ok := comdirect.FindVenue(s.fixtures.Dimensions, request.VenueID); !ok || request.Quantity.Value.Sign() <= 0 {
+		writeError(w, http.StatusUnprocessableEntity, "quote.invalid", "Invalid quote request")
+		return
+	}
+	price, ok := s.orderPrice(comdirect.OrderRequest{InstrumentID: request.InstrumentID})
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "price.not.available", "No price available for the instrument")
+		return
+	}
+	switch request.Side {
+	case comdirect.OrderSideBuy:
+		price = price.Mul(comdirect.MustParseDecimal("1.001")).Round(2)
+	case comdirect.OrderSideSell:
+		price = price.Mul(comdirect.MustParseDecimal("0.999")).Round(2)
+	default:
+		writeError(w, http.StatusUnprocessableEntity, "quote.invalid", "Invalid side")
+		return
+	}
+
+	now := time.Now()
+	quote := comdirect.Quote{
+		QuoteID:         s.nextID("quote-"),
+		QuoteTicketID:   request.QuoteTicketID,
+		InstrumentID:    request.InstrumentID,
+		VenueID:         request.VenueID,
+		Side:            request.Side,
+		Quantity:        request.Quantity,
+		Price:           comdirect.AmountValue{Value: price, Unit: "EUR"},
+		QuoteTimestamp:  comdirect.NewTimestamp(now),
+		ExpiryTimestamp: comdirect.NewTimestamp(now.Add(s.quoteLifetime)),
+	}
+	s.quotes[quote.QuoteID] = quote
+	writeJSON(w, http.StatusCreated, quote)
+}
+
+// handleCreateQuoteOrder executes a quote order at the price of its quote. The caller must hold s.mu.
+func (s *Server) handleCreateQuoteOrder(w http.ResponseWriter, request comdirect.OrderRequest) {
+	quote, ok := s.verifyQuote(w, request)
+	if !ok {
+		return
+	}
+	delete(s.quotes, quote.QuoteID)
+
+	now := comdirect.NewTimestamp(time.Now())
+	order := comdirect.Order{
+		DepotID:           request.DepotID,
+		OrderID:           s.nextID("order-"),
+		CreationTimestamp: now,
+		OrderType:         request.OrderType,
+		OrderStatus:       comdirect.OrderStatusExecuted,
+		Side:              request.Side,
+		InstrumentID:      request.InstrumentID,
+		QuoteTicketID:     quote.QuoteTicketID,
+		QuoteID:           quote.QuoteID,
+		VenueID:           request.VenueID,
+		Quantity:          request.Quantity,
+		Limit:             quote.Price,
+		ValidityType:      request.ValidityType,
+		ExecutedQuantity:  request.Quantity,
+		OpenQuantity:      comdirect.AmountValue{Value: comdirect.NewDecimal(0, 0), Unit: request.Quantity.Unit},
+		Executions: []comdirect.Execution{{
+			ExecutionID:        s.nextID("execution-"),
+			ExecutionNumber:    1,
+			ExecutedQuantity:   request.Quantity,
+			ExecutionPrice:     quote.Price,
+			ExecutionTimestamp: now,
+		}},
+	}
+	if s.fixtures.Orders == nil {
+		s.fixtures.Orders = map[string][]comdirect.Order{}
+	}
+	s.fixtures.Orders[order.DepotID] = append([]comdirect.Order{order}, s.fixtures.Orders[order.DepotID]...)
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// verifyQuote checks that a quote order matches an unexpired quote of an active quote ticket.
+// The caller must hold s.mu.
+func (s *Server) verifyQuote(w http.ResponseWriter, order comdirect.OrderRequest) (comdirect.Quote, bool) {
+	quote, ok := s.quotes[order.QuoteID]
+	if !ok || quote.QuoteTicketID != order.QuoteTicketID {
+		writeError(w, http.StatusUnprocessableEntity, "quote.not.found", "Quote not found")
+		return quote, false
+	}
+	if !time.Now().Before(quote.ExpiryTimestamp.Time()) {
+		writeError(w, http.StatusUnprocessableEntity, "quote.expired", "Quote expired")
+		return quote, false
+	}
+	if quote.InstrumentID != order.InstrumentID || quote.VenueID != order.VenueID || quote.Side != order.Side ||
+		!quote.Quantity.Value.Equal(order.Quantity.Value) || !quote.Price.Value.Equal(order.Limit.Value) {
+		writeError(w, http.StatusUnprocessableEntity, "quote.invalid", "Order does not match the quote")
+		return quote, false
+	}
+	return quote, true
+}
+
+// quoteTicketSubject binds a TAN challenge to the activation of a quote ticket.
+func quoteTicketSubject(quoteTicketID string) string {
+	return "quoteticket:" + quoteTicketID
+}
@@ -0,0 +1,248 @@
+// Package comdirecttest provides an in-memory simulator of the comdirect REST API for offline testing.
+//
+// The Server implements the OAuth2 password, secondary and refresh token grants, the session
+// validate and activate endpoints including a simulated photoTAN approval, as well as the banking,
+// brokerage including the order lifecycle and quote trading, messages and reports endpoints backed by seedable Fixtures.
+//
+//	server := comdirecttest.NewServer()
+//	defer server.Close()
+//	client := comdirect.NewWithAuthOptions(server.AuthOptions(), server.ClientOptions()...)
+package comdirecttest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+const (
+	Username     = "12345678"
+	Password     = "123456"
+	ClientID     = "User_0123456789ABCDEF"
+	ClientSecret = "secret"
+	TAN          = "123456"
+
+	DefaultTokenLifetime = 599 * time.Second
+	DefaultQuoteLifetime = 10 * time.Second
+	DefaultPagingCount   = 20
+)
+
+// Server is a fake comdirect REST API based on httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	fixtures      Fixtures
+	tokens        map[string]*token
+	refreshTokens map[string]*token
+	challenges    map[string]*challenge
+	quoteTickets  map[string]*comdirect.QuoteTicket
+	quotes        map[string]comdirect.Quote
+	autoApprove   bool
+	tokenLifetime time.Duration
+	quoteLifetime time.Duration
+	sequence      int
+}
+
+// NewServer starts a Server seeded with DefaultFixtures. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		fixtures:      DefaultFixtures(),
+		tokens:        map[string]*token{},
+		refreshTokens: map[string]*token{},
+		challenges:    map[string]*challenge{},
+		quoteTickets:  map[string]*comdirect.QuoteTicket{},
+		quotes:        map[string]comdirect.Quote{},
+		autoApprove:   true,
+		tokenLifetime: DefaultTokenLifetime,
+		quoteLifetime: DefaultQuoteLifetime,
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// AuthOptions returns the comdirect.AuthOptions accepted by the Server.
+func (s *Server) AuthOptions() *comdirect.AuthOptions {
+	return &comdirect.AuthOptions{
+		Username:     Username,
+		Password:     Password,
+		ClientId:     ClientID,
+		ClientSecret: ClientSecret,
+	}
+}
+
+// ClientOptions returns the comdirect.Option values to point a Client or Authenticator at the Server.
+func (s *Server) ClientOptions() []comdirect.Option {
+	return []comdirect.Option{
+		comdirect.WithBaseURL(s.URL),
+		comdirect.WithTANPollInterval(10 * time.Millisecond),
+	}
+}
+
+// AuthenticatedClient returns a Client pointed at the Server with additional opts and authenticates it.
+func (s *Server) AuthenticatedClient(ctx context.Context, opts ...comdirect.Option) (*comdirect.Client, error) {
+	client := comdirect.NewWithAuthOptions(s.AuthOptions(), append(s.ClientOptions(), opts...)...)
+	if _, err := client.Authenticate(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Seed replaces the fixtures served by the Server.
+func (s *Server) Seed(fixtures Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixtures = fixtures
+}
+
+// SetAutoApprove controls whether push TAN challenges are approved on the first status poll.
+// When disabled, challenges stay pending until Approve is called.
+func (s *Server) SetAutoApprove(autoApprove bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoApprove = autoApprove
+}
+
+// Approve approves all pending TAN challenges as if the photoTAN app was used.
+func (s *Server) Approve() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.challenges {
+		c.approved = true
+	}
+}
+
+// SetTokenLifetime sets the lifetime of access tokens issued from now on.
+func (s *Server) SetTokenLifetime(lifetime time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenLifetime = lifetime
+}
+
+// SetQuoteLifetime sets the time quotes requested from now on can be executed.
+func (s *Server) SetQuoteLifetime(lifetime time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quoteLifetime = lifetime
+}
+
+// ExpireTokens expires all access tokens issued so far. Refresh tokens stay valid.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tokens {
+		t.expiresAt = time.Now().Add(-time.Second)
+	}
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /oauth/token", s.handleToken)
+	mux.HandleFunc("DELETE /oauth/token", s.handleRevoke)
+
+	mux.HandleFunc("GET /api/session/clients/user/v1/sessions", s.primary(s.handleSessions))
+	mux.HandleFunc("POST /api/session/clients/user/v1/sessions/{sessionID}/validate", s.primary(s.handleValidateSession))
+	mux.HandleFunc("PATCH /api/session/clients/user/v1/sessions/{sessionID}", s.primary(s.handleActivateSession))
+	mux.HandleFunc("GET /api/session/v1/authentications/{challengeID}", s.authenticated(s.handleAuthenticationStatus))
+
+	mux.HandleFunc("GET /api/banking/clients/user/v2/accounts/balances", s.secondary(s.handleBalances))
+	mux.HandleFunc("GET /api/banking/v2/accounts/{accountID}/balances", s.secondary(s.handleBalance))
+	mux.HandleFunc("GET /api/banking/v1/accounts/{accountID}/transactions", s.secondary(s.handleTransactions))
+	mux.HandleFunc("POST /api/banking/v1/accounts/{accountID}/transfers/validation", s.secondary(s.handleValidateTransfer))
+	mux.HandleFunc("POST /api/banking/v1/accounts/{accountID}/transfers", s.secondary(s.handleTransfer))
+
+	mux.HandleFunc("GET /api/brokerage/clients/user/v3/depots", s.secondary(s.handleDepots))
+	mux.HandleFunc("GET /api/brokerage/v3/depots/{depotID}/positions", s.secondary(s.handlePositions))
+	mux.HandleFunc("GET /api/brokerage/v3/depots/{depotID}/positions/{positionID}", s.secondary(s.handlePosition))
+	mux.HandleFunc("GET /api/brokerage/v3/depots/{depotID}/transactions", s.secondary(s.handleDepotTransactions))
+	mux.HandleFunc("GET /api/brokerage/v1/instruments/{instrument}", s.secondary(s.handleInstrument))
+	mux.HandleFunc("GET /api/brokerage/v3/orders/dimensions", s.secondary(s.handleDimensions))
+	mux.HandleFunc("GET /api/brokerage/depots/{depotID}/v3/orders", s.secondary(s.handleOrders))
+	mux.HandleFunc("GET /api/brokerage/v3/orders/{orderID}", s.secondary(s.handleOrder))
+	mux.HandleFunc("POST /api/brokerage/v3/orders/prevalidation", s.secondary(s.handlePreValidateOrder))
+	mux.HandleFunc("POST /api/brokerage/v3/orders/costindicationexante", s.secondary(s.handleExAnteOrder))
+	mux.HandleFunc("POST /api/brokerage/v3/orders/validation", s.secondary(s.handleValidateOrder))
+	mux.HandleFunc("POST /api/brokerage/v3/orders", s.secondary(s.handleCreateOrder))
+	mux.HandleFunc("POST /api/brokerage/v3/quoteticket", s.secondary(s.handleCreateQuoteTicket))
+	mux.HandleFunc("PATCH /api/brokerage/v3/quoteticket/{ticketID}", s.secondary(s.handleUpdateQuoteTicket))
+	mux.HandleFunc("PATCH /api/brokerage/v3/quoteticket/{ticketID}/validation", s.secondary(s.handleValidateQuoteTicket))
+	mux.HandleFunc("POST /api/brokerage/v3/quotes", s.secondary(s.handleQuote))
+	mux.HandleFunc("POST /api/brokerage/v3/orders/{orderID}/validation", s.secondary(s.handleValidateOrderChange))
+	mux.HandleFunc("PATCH /api/brokerage/v3/orders/{orderID}", s.secondary(s.handleUpdateOrder))
+	mux.HandleFunc("DELETE /api/brokerage/v3/orders/{orderID}", s.secondary(s.handleDeleteOrder))
+
+	mux.HandleFunc("GET /api/messages/clients/user/v2/documents", s.secondary(s.handleDocuments))
+	mux.HandleFunc("GET /api/messages/v2/documents/{documentID}", s.secondary(s.handleDocument))
+
+	mux.HandleFunc("GET /api/reports/participants/user/v1/allbalances", s.secondary(s.handleReports))
+
+	return mux
+}
+
+// nextID returns a unique identifier with the given prefix. The caller must hold s.mu.
+func (s *Server) nextID(prefix string) string {
+	s.sequence++
+	return prefix + strconv.Itoa(s.sequence)
+}
+
+// writeJSON writes v as JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format used by the comdirect REST API.
+func writeError(w http.ResponseWriter, status int, key string, message string) {
+	writeJSON(w, status, errorResponse{
+		Code: key,
+		Messages: []errorMessage{{
+			Severity: "ERROR",
+			Key:      key,
+			Message:  message,
+			Args:     map[string]interface{}{},
+			Origin:   []string{},
+		}},
+	})
+}
+
+type errorResponse struct {
+	Code     string         `json:"code"`
+	Messages []errorMessage `json:"messages"`
+}
+
+type errorMessage struct {
+	Severity string                 `json:"severity"`
+	Key      string                 `json:"key"`
+	Message  string                 `json:"message"`
+	Args     map[string]interface{} `json:"args"`
+	Origin   []string               `json:"origin"`
+}
+
+// page returns the slice of values selected by the paging-first and paging-count query parameters.
+func page[T any](r *http.Request, values []T) ([]T, comdirect.Paging) {
+	first, err := strconv.Atoi(r.URL.Query().Get(comdirect.PagingFirstQueryKey))
+	if err != nil || first < 0 {
+		first = 0
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get(comdirect.PagingCountQueryKey))
+	if err != nil || count < 0 {
+		count = DefaultPagingCount
+	}
+	paging := comdirect.Paging{Index: first, Matches: len(values)}
+	if first >= len(values) {
+		return []T{}, paging
+	}
+	last := first + count
+	if last > len(values) {
+		last = len(values)
+	}
+	return append([]T{}, values[first:last]...), paging
+}
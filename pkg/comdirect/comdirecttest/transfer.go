@@ -0,0 +1,101 @@
+package comdirecttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// handleValidateTransfer implements POST /api/banking/v1/accounts/{accountID}/transfers/validation
+// and returns a TAN challenge for the transfer in the x-once-authentication-info header.
+func (s *Server) handleValidateTransfer(w http.ResponseWriter, r *http.Request, t *token) {
+	accountID := r.PathValue("accountID")
+	transfer, ok := s.decodeTransfer(w, r, accountID)
+	if !ok {
+		return
+	}
+	info, ok := s.newChallenge(w, r, t.session, transferSubject(accountID, transfer))
+	if !ok {
+		return
+	}
+	w.Header().Set(comdirect.OnceAuthenticationInfoHeaderKey, info)
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+// handleTransfer implements POST /api/banking/v1/accounts/{accountID}/transfers. The transfer must match
+// a validated transfer and is booked as a NOTBOOKED transaction that reduces the balance of the account.
+func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request, t *token) {
+	accountID := r.PathValue("accountID")
+	transfer, ok := s.decodeTransfer(w, r, accountID)
+	if !ok {
+		return
+	}
+	if !s.verifyChallenge(w, r, t.session, transferSubject(accountID, transfer)) {
+		return
+	}
+
+	executed := comdirect.Transfer{
+		TransferID:        s.nextID("transfer-"),
+		AccountID:         accountID,
+		Status:            "EXECUTED",
+		CreationTimestamp: comdirect.NewTimestamp(time.Now()),
+		TransferRequest:   transfer,
+	}
+	s.bookTransfer(executed)
+	writeJSON(w, http.StatusCreated, executed)
+}
+
+// decodeTransfer decodes and validates the transfer of the request body. The caller must hold s.mu.
+func (s *Server) decodeTransfer(w http.ResponseWriter, r *http.Request, accountID string) (comdirect.TransferRequest, bool) {
+	var transfer comdirect.TransferRequest
+	balance, ok := s.balance(accountID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "account.not.found", "Account not found")
+		return transfer, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
+		writeError(w, http.StatusBadRequest, "request.body.invalid", err.Error())
+		return transfer, false
+	}
+	if err := transfer.Validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "transfer.invalid", err.Error())
+		return transfer, false
+	}
+	if cmp, err := transfer.Amount.Cmp(balance.AvailableCashAmount); err != nil || cmp > 0 {
+		writeError(w, http.StatusUnprocessableEntity, "account.balance.insufficient", "The available cash amount is insufficient")
+		return transfer, false
+	}
+	return transfer, true
+}
+
+// bookTransfer adds a transaction for the transfer and updates the balance. The caller must hold s.mu.
+func (s *Server) bookTransfer(transfer comdirect.Transfer) {
+	transaction := comdirect.AccountTransaction{
+		BookingStatus:     "NOTBOOKED",
+		Amount:            transfer.Amount.Neg(),
+		Creditor:          transfer.Creditor,
+		EndToEndReference: transfer.EndToEndReference,
+		RemittanceInfo:    "01" + transfer.RemittanceInfo,
+		TransactionType:   comdirect.TransactionType{Key: "TRANSFER", Text: "Übertrag / Überweisung"},
+	}
+	s.fixtures.Transactions[transfer.AccountID] = append([]comdirect.AccountTransaction{transaction}, s.fixtures.Transactions[transfer.AccountID]...)
+
+	for i, b := range s.fixtures.Balances {
+		if b.AccountId != transfer.AccountID {
+			continue
+		}
+		b.Balance, _ = b.Balance.Sub(transfer.Amount)
+		b.BalanceEUR, _ = b.BalanceEUR.Sub(transfer.Amount)
+		b.AvailableCashAmount, _ = b.AvailableCashAmount.Sub(transfer.Amount)
+		b.AvailableCashAmountEUR, _ = b.AvailableCashAmountEUR.Sub(transfer.Amount)
+		s.fixtures.Balances[i] = b
+	}
+}
+
+// transferSubject binds a TAN challenge to the account and the content of a transfer.
+func transferSubject(accountID string, transfer comdirect.TransferRequest) string {
+	data, _ := json.Marshal(transfer)
+	return "transfer:" + accountID + ":" + string(data)
+}
@@ -0,0 +1,246 @@
+package comdirect
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Europe/Berlin must be available on systems without zoneinfo
+)
+
+// DateLayout is the format of dates used by comdirect, e.g. booking dates.
+const DateLayout = "2006-01-02"
+
+// Location is the time zone of comdirect, Europe/Berlin. Dates and timestamps without
+// an explicit offset are interpreted in this location.
+var Location = mustLoadLocation("Europe/Berlin")
+
+// timestampLayouts are the formats of timestamps used by the different comdirect endpoints.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05,999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// Date is a calendar date without time, e.g. the booking date of a transaction.
+// The zero value represents a missing date, e.g. for transactions that are not booked yet.
+// Dates decoded from JSON are encoded in their original wire format, which they keep internally.
+// Dates must therefore be compared with Equal, not with ==, and must not be used as map keys.
+type Date struct {
+	t   time.Time
+	raw string
+}
+
+// NewDate returns the Date of the given day.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, Location)}
+}
+
+// DateOf returns the Date of t in Location.
+func DateOf(t time.Time) Date {
+	t = t.In(Location)
+	return NewDate(t.Year(), t.Month(), t.Day())
+}
+
+// ParseDate parses a date in the format DateLayout. An empty string results in the zero Date.
+func ParseDate(s string) (Date, error) {
+	if s == "" {
+		return Date{}, nil
+	}
+	t, err := time.ParseInLocation(DateLayout, s, Location)
+	if err != nil {
+		return Date{}, fmt.Errorf("comdirect: invalid date %q: %w", s, err)
+	}
+	return Date{t: t}, nil
+}
+
+// MustParseDate is like ParseDate but panics if s is invalid.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// IsZero reports whether d represents a missing date.
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// Time returns the start of the day d in Location.
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// Before reports whether d is before e.
+func (d Date) Before(e Date) bool {
+	return d.t.Before(e.t)
+}
+
+// After reports whether d is after e.
+func (d Date) After(e Date) bool {
+	return d.t.After(e.t)
+}
+
+// Equal reports whether d and e are the same day.
+func (d Date) Equal(e Date) bool {
+	return d.t.Equal(e.t)
+}
+
+// AddDays returns the Date n days after d.
+func (d Date) AddDays(n int) Date {
+	return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// String returns d in the format DateLayout, or an empty string for the zero Date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(DateLayout)
+}
+
+// MarshalJSON encodes d in its original wire format, or as string in the format DateLayout.
+// The zero Date is encoded as null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.raw != "" {
+		return []byte(d.raw), nil
+	}
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a date string. null and "" are decoded as the zero Date.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	parsed.raw = string(data)
+	*d = parsed
+	return nil
+}
+
+// Timestamp is a point in time, e.g. the creation time of an order.
+// The zero value represents a missing timestamp. Timestamps decoded from JSON are encoded
+// in their original wire format, which they keep internally. Timestamps must therefore be
+// compared with Equal, not with ==, and must not be used as map keys.
+type Timestamp struct {
+	t   time.Time
+	raw string
+}
+
+// NewTimestamp returns the Timestamp of t.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{t: t.In(Location)}
+}
+
+// ParseTimestamp parses a timestamp in one of the formats used by comdirect, e.g.
+// "2024-03-28T17:35:00+01:00" or "2024-03-28 17:35:00". Timestamps without offset are
+// interpreted in Location. An empty string results in the zero Timestamp.
+func ParseTimestamp(s string) (Timestamp, error) {
+	if s == "" {
+		return Timestamp{}, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, Location); err == nil {
+			return Timestamp{t: t.In(Location)}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("comdirect: invalid timestamp %q", s)
+}
+
+// MustParseTimestamp is like ParseTimestamp but panics if s is invalid.
+func MustParseTimestamp(s string) Timestamp {
+	ts, err := ParseTimestamp(s)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+// IsZero reports whether ts represents a missing timestamp.
+func (ts Timestamp) IsZero() bool {
+	return ts.t.IsZero()
+}
+
+// Time returns ts as time.Time in Location.
+func (ts Timestamp) Time() time.Time {
+	return ts.t
+}
+
+// Equal reports whether ts and u are the same point in time.
+func (ts Timestamp) Equal(u Timestamp) bool {
+	return ts.t.Equal(u.t)
+}
+
+// Date returns the Date of ts in Location.
+func (ts Timestamp) Date() Date {
+	if ts.IsZero() {
+		return Date{}
+	}
+	return DateOf(ts.t)
+}
+
+// String returns ts in the format time.RFC3339, or an empty string for the zero Timestamp.
+func (ts Timestamp) String() string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.t.Format(time.RFC3339)
+}
+
+// MarshalJSON encodes ts in its original wire format, or as string in the format time.RFC3339.
+// The zero Timestamp is encoded as null.
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	if ts.raw != "" {
+		return []byte(ts.raw), nil
+	}
+	if ts.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ts.String())
+}
+
+// UnmarshalJSON decodes a timestamp string. null and "" are decoded as the zero Timestamp.
+func (ts *Timestamp) UnmarshalJSON(data []byte) error {
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	parsed.raw = string(data)
+	*ts = parsed
+	return nil
+}
+
+// unquote decodes a JSON string, null is decoded as an empty string.
+func unquote(data []byte) (string, error) {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", err
+	}
+	if s == nil {
+		return "", nil
+	}
+	return *s, nil
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
@@ -0,0 +1,141 @@
+package comdirect_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+func TestParseDate(t *testing.T) {
+	d, err := comdirect.ParseDate("2024-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Time().Location() != comdirect.Location || d.Time().Hour() != 0 {
+		t.Errorf("expected start of day in Europe/Berlin, got %s", d.Time())
+	}
+	if !d.AddDays(1).Equal(comdirect.NewDate(2024, time.April, 1)) {
+		t.Errorf("unexpected next day: %s", d.AddDays(1))
+	}
+	if _, err = comdirect.ParseDate("31.03.2024"); err == nil {
+		t.Error("expected invalid date to fail")
+	}
+	if d, err = comdirect.ParseDate(""); err != nil || !d.IsZero() {
+		t.Errorf("expected empty date to be zero: %v", err)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2024-03-28T17:35:00+01:00", "2024-03-28T17:35:00+01:00"},
+		{"2024-03-28T16:35:00Z", "2024-03-28T17:35:00+01:00"},
+		{"2024-03-28T17:35:00,123+01:00", "2024-03-28T17:35:00+01:00"},
+		{"2024-03-28T17:35:00.123+0100", "2024-03-28T17:35:00+01:00"},
+		{"2024-07-01 12:00:00", "2024-07-01T12:00:00+02:00"},
+		{"2024-07-01T12:00:00", "2024-07-01T12:00:00+02:00"},
+	}
+	for _, tt := range tests {
+		ts, err := comdirect.ParseTimestamp(tt.in)
+		if err != nil {
+			t.Errorf("ParseTimestamp(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if got := ts.String(); got != tt.want {
+			t.Errorf("ParseTimestamp(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if _, err := comdirect.ParseTimestamp("yesterday"); err == nil {
+		t.Error("expected invalid timestamp to fail")
+	}
+	// 23:30 UTC is already the next day in Berlin.
+	if d := comdirect.MustParseTimestamp("2024-03-28T23:30:00Z").Date(); d.String() != "2024-03-29" {
+		t.Errorf("expected date in Europe/Berlin, got %s", d)
+	}
+}
+
+func TestDateTime_JSON(t *testing.T) {
+	in := `{"bookingStatus":"BOOKED","bookingDate":"2024-03-28","valutaDate":null}`
+	var transaction struct {
+		BookingStatus string         `json:"bookingStatus"`
+		BookingDate   comdirect.Date `json:"bookingDate"`
+		ValutaDate    comdirect.Date `json:"valutaDate"`
+	}
+	if err := json.Unmarshal([]byte(in), &transaction); err != nil {
+		t.Fatal(err)
+	}
+	if transaction.BookingDate.String() != "2024-03-28" || !transaction.ValutaDate.IsZero() {
+		t.Errorf("unexpected dates: %+v", transaction)
+	}
+	out, err := json.Marshal(transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("expected %s, got %s", in, out)
+	}
+
+	var execution struct {
+		ExecutionTimestamp comdirect.Timestamp `json:"executionTimestamp"`
+	}
+	in = `{"executionTimestamp":"2024-03-28T17:35:00,123+01:00"}`
+	if err = json.Unmarshal([]byte(in), &execution); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ = json.Marshal(execution); string(out) != in {
+		t.Errorf("expected wire format to be preserved, got %s", out)
+	}
+	if err = json.Unmarshal([]byte(`{"executionTimestamp":"invalid"}`), &execution); err == nil {
+		t.Error("expected invalid timestamp to fail")
+	}
+
+	created := struct {
+		Date comdirect.Date `json:"date"`
+	}{comdirect.NewDate(2024, time.March, 1)}
+	if out, _ = json.Marshal(created); string(out) != `{"date":"2024-03-01"}` {
+		t.Errorf("unexpected encoding: %s", out)
+	}
+}
+
+func TestDateTime_JSONEqual(t *testing.T) {
+	var decoded struct {
+		Date      comdirect.Date      `json:"date"`
+		Timestamp comdirect.Timestamp `json:"timestamp"`
+	}
+	in := `{"date":"2024-03-28","timestamp":"2024-03-28 17:35:00"}`
+	if err := json.Unmarshal([]byte(in), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Date.Equal(comdirect.NewDate(2024, time.March, 28)) {
+		t.Errorf("expected decoded date to equal NewDate, got %s", decoded.Date)
+	}
+	want := comdirect.NewTimestamp(time.Date(2024, time.March, 28, 16, 35, 0, 0, time.UTC))
+	if !decoded.Timestamp.Equal(want) {
+		t.Errorf("expected decoded timestamp to equal %s, got %s", want, decoded.Timestamp)
+	}
+}
+
+func TestAccountTransactions_FilterSince(t *testing.T) {
+	transactions := &comdirect.AccountTransactions{Values: []comdirect.AccountTransaction{
+		{BookingStatus: "NOTBOOKED"},
+		{BookingStatus: "BOOKED", BookingDate: comdirect.NewDate(2024, time.March, 28)},
+		{BookingStatus: "BOOKED", BookingDate: comdirect.NewDate(2024, time.March, 25)},
+		{BookingStatus: "BOOKED", BookingDate: comdirect.NewDate(2024, time.March, 22)},
+	}}
+	filtered, err := transactions.FilterSince(time.Date(2024, time.March, 25, 0, 0, 0, 0, comdirect.Location))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered.Values) != 2 {
+		t.Errorf("expected 2 transactions, got %d", len(filtered.Values))
+	}
+
+	transactions.Values = append(transactions.Values[:1], comdirect.AccountTransaction{BookingStatus: "BOOKED", Reference: "ref"})
+	if _, err = transactions.FilterSince(time.Now()); err == nil {
+		t.Error("expected booked transaction without booking date to fail")
+	}
+}
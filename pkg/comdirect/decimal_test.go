@@ -0,0 +1,148 @@
+package comdirect_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2500.25", "2500.25"},
+		{"-0.10", "-0.10"},
+		{"+7", "7"},
+		{".5", "0.5"},
+		{"", "0"},
+		{"123456789012345678901234567890.000000001", "123456789012345678901234567890.000000001"},
+	}
+	for _, tt := range tests {
+		d, err := comdirect.ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"-", ".", "1.", "1,5", "1e5", "abc", "1.2.3"} {
+		if _, err := comdirect.ParseDecimal(in); err == nil {
+			t.Errorf("expected ParseDecimal(%q) to fail", in)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a := comdirect.MustParseDecimal("0.1")
+	b := comdirect.MustParseDecimal("0.20")
+	if got := a.Add(b).String(); got != "0.30" {
+		t.Errorf("0.1 + 0.20 = %s, want 0.30", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.10" {
+		t.Errorf("0.1 - 0.20 = %s, want -0.10", got)
+	}
+	if got := a.Mul(b).String(); got != "0.020" {
+		t.Errorf("0.1 * 0.20 = %s, want 0.020", got)
+	}
+	if got := b.Neg().String(); got != "-0.20" {
+		t.Errorf("-0.20 = %s", got)
+	}
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 || !comdirect.MustParseDecimal("0.30").Equal(comdirect.MustParseDecimal("0.3")) {
+		t.Error("unexpected comparison result")
+	}
+
+	// Summing many cents must not drift as with float64.
+	sum := comdirect.Decimal{}
+	cent := comdirect.NewDecimal(1, 2)
+	for i := 0; i < 100000; i++ {
+		sum = sum.Add(cent)
+	}
+	if got := sum.String(); got != "1000.00" {
+		t.Errorf("expected sum of 100000 cents to be 1000.00, got %s", got)
+	}
+}
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"1.005", 2, "1.01"},
+		{"1.004", 2, "1.00"},
+		{"-1.005", 2, "-1.01"},
+		{"2.5", 0, "3"},
+		{"1.5", 2, "1.50"},
+	}
+	for _, tt := range tests {
+		if got := comdirect.MustParseDecimal(tt.in).Round(tt.places).String(); got != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimal_Format(t *testing.T) {
+	d := comdirect.MustParseDecimal("-1234567.89")
+	if got := d.Format(comdirect.LocaleDE); got != "-1.234.567,89" {
+		t.Errorf("unexpected German format: %s", got)
+	}
+	if got := d.Format(comdirect.LocaleEN); got != "-1,234,567.89" {
+		t.Errorf("unexpected English format: %s", got)
+	}
+	if got := comdirect.MustParseDecimal("0.05").Format(comdirect.LocaleDE); got != "0,05" {
+		t.Errorf("unexpected format of small value: %s", got)
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var av comdirect.AmountValue
+	if err := json.Unmarshal([]byte(`{"value":"1.50","unit":"EUR"}`), &av); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(av)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"value":"1.50","unit":"EUR"}` {
+		t.Errorf("expected round trip to keep encoding, got %s", data)
+	}
+	for _, in := range []string{`{"value":1.5}`, `{"value":null}`, `{"value":""}`, `{}`} {
+		if err = json.Unmarshal([]byte(in), &av); err != nil {
+			t.Errorf("failed to decode %s: %s", in, err)
+		}
+	}
+	if err = json.Unmarshal([]byte(`{"value":"x"}`), &av); err == nil {
+		t.Error("expected invalid decimal to fail")
+	}
+}
+
+func TestAmountValue(t *testing.T) {
+	a, _ := comdirect.NewAmountValue("10.00", "EUR")
+	b, _ := comdirect.NewAmountValue("2.50", "EUR")
+	usd, _ := comdirect.NewAmountValue("1", "USD")
+
+	sum, err := comdirect.AmountValue{}.Add(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum, err = sum.Sub(b); err != nil || sum.String() != "7.50 EUR" {
+		t.Errorf("expected 7.50 EUR, got %s (%v)", sum, err)
+	}
+	if cmp, err := a.Cmp(b); err != nil || cmp != 1 {
+		t.Errorf("expected 10.00 EUR > 2.50 EUR, got %d (%v)", cmp, err)
+	}
+	if got := a.Neg().Format(comdirect.LocaleDE); got != "-10,00 EUR" {
+		t.Errorf("unexpected format: %s", got)
+	}
+	if _, err = a.Add(usd); !errors.Is(err, comdirect.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got: %v", err)
+	}
+	if _, err = a.Cmp(usd); !errors.Is(err, comdirect.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got: %v", err)
+	}
+}
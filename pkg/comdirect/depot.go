@@ -2,7 +2,6 @@ package comdirect
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 )
@@ -20,6 +19,8 @@ type DepotPosition struct {
 	DepotId                  string      `json:"depotId"`
 	PositionId               string      `json:"positionId"`
 	Wkn                      string      `json:"wkn"`
+	InstrumentID             string      `json:"instrumentId"`
+	Instrument               Instrument  `json:"instrument"`
 	CustodyType              string      `json:"custodyType"`
 	Quantity                 AmountValue `json:"quantity"`
 	AvailableQuantity        AmountValue `json:"availableQuantity"`
@@ -36,11 +37,16 @@ type DepotPosition struct {
 
 type Price struct {
 	Price         AmountValue `json:"price"`
-	PriceDateTime string      `json:"priceDateTime"`
+	PriceDateTime Timestamp   `json:"priceDateTime"`
 }
 
 type DepotTransaction struct {
 	TransactionID        string      `json:"transactionId"`
+	BookingStatus        string      `json:"bookingStatus"`
+	BookingDate          Date        `json:"bookingDate"`
+	BusinessDate         Date        `json:"businessDate"`
+	Quantity             AmountValue `json:"quantity"`
+	InstrumentID         string      `json:"instrumentId"`
 	Instrument           Instrument  `json:"instrument"`
 	ExecutionPrice       AmountValue `json:"executionPrice"`
 	TransactionValue     AmountValue `json:"transactionValue"`
@@ -77,19 +83,22 @@ type Depots struct {
 }
 
 // Depots retrieves all depots for the current Authentication.
-func (c *Client) Depots(ctx context.Context) (*Depots, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+func (c *Client) Depots(ctx context.Context, options ...Options) (*Depots, error) {
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
+	url := c.http.apiURL("/brokerage/clients/user/v3/depots")
+	encodeOptions(url, options)
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL("/brokerage/clients/user/v3/depots"),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    url,
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 	depots := &Depots{}
@@ -99,18 +108,21 @@ func (c *Client) Depots(ctx context.Context) (*Depots, error) {
 
 // DepotPositions retrieves all positions for a specific depot ID.
 func (c *Client) DepotPositions(ctx context.Context, depotID string, options ...Options) (*DepotPositions, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
+	url := c.http.apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/positions", depotID))
+	encodeOptions(url, options)
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/positions", depotID)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    url,
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 	depots := &DepotPositions{}
@@ -120,18 +132,21 @@ func (c *Client) DepotPositions(ctx context.Context, depotID string, options ...
 
 // DepotPosition retrieves a position by its ID from the depot specified by its ID.
 func (c *Client) DepotPosition(ctx context.Context, depotID string, positionID string, options ...Options) (*DepotPosition, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
+	url := c.http.apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/positions/%s", depotID, positionID))
+	encodeOptions(url, options)
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/positions/%s", depotID, positionID)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    url,
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 
@@ -142,18 +157,21 @@ func (c *Client) DepotPosition(ctx context.Context, depotID string, positionID s
 
 // DepotTransactions retrieves all transactions for a depot specified by its ID.
 func (c *Client) DepotTransactions(ctx context.Context, depotID string, options ...Options) (*DepotTransactions, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
+	url := c.http.apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/transactions", depotID))
+	encodeOptions(url, options)
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/brokerage/v3/depots/%s/transactions", depotID)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    url,
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 
@@ -1,102 +1,63 @@
-package comdirect
+package comdirect_test
 
 import (
-	"fmt"
-	"os"
 	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
 )
 
 func TestClient_Depots(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
-
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
-	}
 
-	depots, err := client.Depots()
+	depots, err := client.Depots(ctx)
 	if err != nil {
-		t.Errorf("failed to retrieve depots: %s", err)
+		t.Fatalf("failed to retrieve depots: %s", err)
+	}
+	if len(depots.Values) != 1 || depots.Values[0].DepotId != comdirecttest.DepotID {
+		t.Errorf("unexpected depots: %+v", depots)
 	}
-
-	fmt.Printf("successfully retrieved depots:\n%+v", depots)
 }
 
 func TestClient_DepotPositions(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
-	}
 
-	depotPositions, err := client.DepotPositions(os.Getenv("COMDIRECT_DEPOT_ID"))
+	depotPositions, err := client.DepotPositions(ctx, comdirecttest.DepotID)
 	if err != nil {
-		t.Errorf("failed to retrieve depot positions: %s", err)
+		t.Fatalf("failed to retrieve depot positions: %s", err)
+	}
+	if len(depotPositions.Values) != 1 || depotPositions.Aggregated.Depot.DepotId != comdirecttest.DepotID {
+		t.Errorf("unexpected depot positions: %+v", depotPositions)
 	}
-
-	fmt.Printf("successfully retrieved depot positions:\n%+v", depotPositions)
 }
 
 func TestClient_DepotPosition(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
-
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
-	}
 
-	depotPositions, err := client.DepotPosition(os.Getenv("COMDIRECT_DEPOT_ID"), os.Getenv("COMDIRECT_POSITION_ID"))
+	depotPosition, err := client.DepotPosition(ctx, comdirecttest.DepotID, comdirecttest.PositionID)
 	if err != nil {
-		t.Errorf("failed to retrieve depot position: %s", err)
+		t.Fatalf("failed to retrieve depot position: %s", err)
+	}
+	if depotPosition.PositionId != comdirecttest.PositionID || depotPosition.Wkn != "865985" {
+		t.Errorf("unexpected depot position: %+v", depotPosition)
 	}
-
-	fmt.Printf("successfully retrieved depot position:\n%+v", depotPositions)
 }
 
 func TestClient_DepotTransactions(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
-
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
-	}
 
-	depotTransactions, err := client.DepotTransactions(os.Getenv("COMDIRECT_DEPOT_ID"))
+	depotTransactions, err := client.DepotTransactions(ctx, comdirecttest.DepotID)
 	if err != nil {
-		t.Errorf("failed to retrieve depot transactions: %s", err)
+		t.Fatalf("failed to retrieve depot transactions: %s", err)
+	}
+	if len(depotTransactions.Values) != 1 || depotTransactions.Values[0].Instrument.WKN != "865985" {
+		t.Errorf("unexpected depot transactions: %+v", depotTransactions)
 	}
-
-	fmt.Printf("successfully retrieved depot transactions:\n%+v", depotTransactions)
 }
@@ -2,7 +2,6 @@ package comdirect
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,7 +13,7 @@ import (
 type Document struct {
 	DocumentID       string           `json:"documentId"`
 	Name             string           `json:"name"`
-	DateCreation     string           `json:"dateCreation"`
+	DateCreation     Date             `json:"dateCreation"`
 	MimeType         string           `json:"mimeType"`
 	Deletable        bool             `json:"deletable"`
 	Advertisement    bool             `json:"advertisement"`
@@ -33,21 +32,22 @@ type DocumentMetaData struct {
 }
 
 func (c *Client) Documents(ctx context.Context, options ...Options) (*Documents, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
-	url := apiURL("/messages/clients/user/v2/documents")
+	url := c.http.apiURL("/messages/clients/user/v2/documents")
 
 	encodeOptions(url, options)
 
 	req := &http.Request{
 		Method: http.MethodGet,
 		URL:    url,
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 	documents := &Documents{}
@@ -56,31 +56,31 @@ func (c *Client) Documents(ctx context.Context, options ...Options) (*Documents,
 }
 
 func (c *Client) DownloadDocument(ctx context.Context, document *Document, folder string) error {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return err
 	}
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/messages/v2/documents/%s", document.DocumentID)),
+		URL:    c.http.apiURL(fmt.Sprintf("/messages/v2/documents/%s", document.DocumentID)),
 		Header: http.Header{
 			AcceptHeaderKey:          {document.MimeType},
 			ContentTypeHeaderKey:     {"application/json"},
-			AuthorizationHeaderKey:   {BearerPrefix + c.authentication.accessToken.AccessToken},
+			AuthorizationHeaderKey:   {BearerPrefix + auth.accessToken.AccessToken},
 			HttpRequestInfoHeaderKey: {string(info)},
 		},
 	}
 	req = req.WithContext(ctx)
-	res, err := c.http.Do(req)
-	defer res.Body.Close()
-
+	res, err := c.http.do(req)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
 	if folder == "" {
 		folder, err = os.Getwd()
@@ -91,13 +91,13 @@ func (c *Client) DownloadDocument(ctx context.Context, document *Document, folde
 
 	ext := strings.Split(document.MimeType, "/")
 	fileName := strings.ReplaceAll(document.Name, " ", "_")
-	file, err := os.Create(folder + "/" + document.DateCreation + "-" + fileName + "." + ext[1])
+	file, err := os.Create(folder + "/" + document.DateCreation.String() + "-" + fileName + "." + ext[1])
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	_, _ = io.Copy(file, res.Body)
+	_, err = io.Copy(file, res.Body)
 
 	return err
 }
@@ -1,30 +1,47 @@
-package comdirect
+package comdirect_test
 
 import (
-	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
 )
 
 func TestClient_Documents(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
+
+	documents, err := client.Documents(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve documents: %s", err)
+	}
+	if len(documents.Values) != 1 || documents.Values[0].DocumentID != comdirecttest.DocumentID {
+		t.Errorf("unexpected documents: %+v", documents)
 	}
+}
+
+func TestClient_DownloadDocument(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
 
 	documents, err := client.Documents(ctx)
 	if err != nil {
-		t.Errorf("failed to retrieve instruments: %s", err)
+		t.Fatalf("failed to retrieve documents: %s", err)
+	}
+	folder := t.TempDir()
+	if err = client.DownloadDocument(ctx, &documents.Values[0], folder); err != nil {
+		t.Fatalf("failed to download document: %s", err)
 	}
 
-	fmt.Printf("successfully retrieved instrument:\n%+v", documents[0])
+	files, err := filepath.Glob(filepath.Join(folder, "*.pdf"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one downloaded pdf file, got %v", files)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil || len(content) == 0 {
+		t.Errorf("expected downloaded document content: %v", err)
+	}
 }
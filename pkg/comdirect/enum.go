@@ -0,0 +1,168 @@
+package comdirect
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The enums of orders are strings, so that responses with values unknown to this package are still
+// decoded. Requests are checked with Valid before they are sent, see OrderRequest.Validate.
+
+// OrderSide is the side of an order, i.e. buy or sell.
+type OrderSide string
+
+const (
+	OrderSideBuy  OrderSide = "BUY"
+	OrderSideSell OrderSide = "SELL"
+)
+
+// OrderSides are all known OrderSide values.
+var OrderSides = []OrderSide{OrderSideBuy, OrderSideSell}
+
+// Valid reports whether s is a known OrderSide.
+func (s OrderSide) Valid() bool {
+	return slices.Contains(OrderSides, s)
+}
+
+// ParseOrderSide parses an OrderSide case-insensitively.
+func ParseOrderSide(s string) (OrderSide, error) {
+	return parseEnum(s, OrderSides, "order side")
+}
+
+// OrderType is the type of an order, e.g. a market or limit order.
+type OrderType string
+
+const (
+	OrderTypeQuote              OrderType = "QUOTE"
+	OrderTypeMarket             OrderType = "MARKET"
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeStopMarket         OrderType = "STOP_MARKET"
+	OrderTypeStopLimit          OrderType = "STOP_LIMIT"
+	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET"
+	OrderTypeTrailingStopLimit  OrderType = "TRAILING_STOP_LIMIT"
+	OrderTypeNextOrder          OrderType = "NEXT_ORDER"
+	// OrderTypeOneCancelsOther replaces the key ONE_CANCELS_ORDER of earlier versions, which was a typo.
+	OrderTypeOneCancelsOther OrderType = "ONE_CANCELS_OTHER"
+)
+
+// OrderTypeValues are all known OrderType values.
+var OrderTypeValues = []OrderType{OrderTypeQuote, OrderTypeMarket, OrderTypeLimit, OrderTypeStopMarket,
+	OrderTypeStopLimit, OrderTypeTrailingStopMarket, OrderTypeTrailingStopLimit, OrderTypeNextOrder,
+	OrderTypeOneCancelsOther}
+
+// Valid reports whether t is a known OrderType.
+func (t OrderType) Valid() bool {
+	return slices.Contains(OrderTypeValues, t)
+}
+
+// ParseOrderType parses an OrderType case-insensitively.
+func ParseOrderType(s string) (OrderType, error) {
+	return parseEnum(s, OrderTypeValues, "order type")
+}
+
+// OrderStatus is the status of an Order.
+type OrderStatus string
+
+const (
+	OrderStatusOpen     OrderStatus = "OPEN"
+	OrderStatusExecuted OrderStatus = "EXECUTED"
+	OrderStatusSettled  OrderStatus = "SETTLED"
+	OrderStatusExpired  OrderStatus = "EXPIRED"
+	// OrderStatusCancelled is the status of orders cancelled by the user.
+	OrderStatusCancelled       OrderStatus = "CANCELLED_USER"
+	OrderStatusCancelledSystem OrderStatus = "CANCELLED_SYSTEM"
+	OrderStatusCancelledTrade  OrderStatus = "CANCELLED_TRADE"
+)
+
+// OrderStatuses are all known OrderStatus values.
+var OrderStatuses = []OrderStatus{OrderStatusOpen, OrderStatusExecuted, OrderStatusSettled, OrderStatusExpired,
+	OrderStatusCancelled, OrderStatusCancelledSystem, OrderStatusCancelledTrade}
+
+// Valid reports whether s is a known OrderStatus.
+func (s OrderStatus) Valid() bool {
+	return slices.Contains(OrderStatuses, s)
+}
+
+// Cancelled reports whether the order was cancelled by the user, the system or the venue. Unknown
+// statuses with the prefix CANCELLED_ are considered cancelled as well.
+func (s OrderStatus) Cancelled() bool {
+	return strings.HasPrefix(string(s), "CANCELLED_")
+}
+
+// ParseOrderStatus parses an OrderStatus case-insensitively.
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	return parseEnum(s, OrderStatuses, "order status")
+}
+
+// ValidityType defines how long an order is valid.
+type ValidityType string
+
+const (
+	// ValidityTypeGoodForDay orders expire at the end of the trading day.
+	ValidityTypeGoodForDay ValidityType = "GFD"
+	// ValidityTypeGoodTillDate orders expire at the end of the day of their validity.
+	ValidityTypeGoodTillDate ValidityType = "GTD"
+	// ValidityTypeGoodTillCancelled orders are valid until they are cancelled.
+	ValidityTypeGoodTillCancelled ValidityType = "GTC"
+)
+
+// ValidityTypes are all known ValidityType values.
+var ValidityTypes = []ValidityType{ValidityTypeGoodForDay, ValidityTypeGoodTillDate, ValidityTypeGoodTillCancelled}
+
+// Valid reports whether t is a known ValidityType.
+func (t ValidityType) Valid() bool {
+	return slices.Contains(ValidityTypes, t)
+}
+
+// ParseValidityType parses a ValidityType case-insensitively.
+func ParseValidityType(s string) (ValidityType, error) {
+	return parseEnum(s, ValidityTypes, "validity type")
+}
+
+// LimitExtension restricts the execution of a limit order.
+type LimitExtension string
+
+const (
+	// LimitExtensionAllOrNone orders are only executed at their full quantity.
+	LimitExtensionAllOrNone LimitExtension = "AON"
+	// LimitExtensionImmediateOrCancel orders are executed immediately as far as possible and cancelled otherwise.
+	LimitExtensionImmediateOrCancel LimitExtension = "IOC"
+	// LimitExtensionFillOrKill orders are executed immediately at their full quantity or cancelled.
+	LimitExtensionFillOrKill LimitExtension = "FOK"
+)
+
+// LimitExtensions are all known LimitExtension values.
+var LimitExtensions = []LimitExtension{LimitExtensionAllOrNone, LimitExtensionImmediateOrCancel, LimitExtensionFillOrKill}
+
+// Valid reports whether e is a known LimitExtension.
+func (e LimitExtension) Valid() bool {
+	return slices.Contains(LimitExtensions, e)
+}
+
+// TradingRestriction restricts an order to a trading phase of the venue.
+type TradingRestriction string
+
+const (
+	TradingRestrictionAuction         TradingRestriction = "AUCTION"
+	TradingRestrictionOpeningAuction  TradingRestriction = "OPENING_AUCTION"
+	TradingRestrictionClosingAuction  TradingRestriction = "CLOSING_AUCTION"
+	TradingRestrictionContinuousTrade TradingRestriction = "CONTINUOUS_TRADE"
+)
+
+// TradingRestrictions are all known TradingRestriction values.
+var TradingRestrictions = []TradingRestriction{TradingRestrictionAuction, TradingRestrictionOpeningAuction,
+	TradingRestrictionClosingAuction, TradingRestrictionContinuousTrade}
+
+// Valid reports whether r is a known TradingRestriction.
+func (r TradingRestriction) Valid() bool {
+	return slices.Contains(TradingRestrictions, r)
+}
+
+func parseEnum[T ~string](s string, values []T, name string) (T, error) {
+	value := T(strings.ToUpper(strings.TrimSpace(s)))
+	if !slices.Contains(values, value) {
+		return "", fmt.Errorf("unknown %s %q, expected one of %v", name, s, values)
+	}
+	return value, nil
+}
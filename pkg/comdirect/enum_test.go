@@ -0,0 +1,77 @@
+package comdirect_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+func TestParseOrderType(t *testing.T) {
+	orderType, err := comdirect.ParseOrderType(" one_cancels_other ")
+	if err != nil || orderType != comdirect.OrderTypeOneCancelsOther {
+		t.Errorf("expected ONE_CANCELS_OTHER, got %q: %v", orderType, err)
+	}
+	if _, err := comdirect.ParseOrderType("ONE_CANCELS_ORDER"); err == nil {
+		t.Errorf("expected unknown order type to be rejected")
+	}
+}
+
+func TestOrderStatus_Cancelled(t *testing.T) {
+	for _, status := range []comdirect.OrderStatus{comdirect.OrderStatusCancelled, comdirect.OrderStatusCancelledSystem,
+		comdirect.OrderStatusCancelledTrade, "CANCELLED_VENUE"} {
+		if !status.Cancelled() {
+			t.Errorf("expected %s to be cancelled", status)
+		}
+	}
+	if comdirect.OrderStatusOpen.Cancelled() || comdirect.OrderStatusExpired.Cancelled() {
+		t.Error("expected open and expired orders not to be cancelled")
+	}
+}
+
+func TestOrderRequest_JSON(t *testing.T) {
+	order := testOrderRequest()
+	order.Side = "HOLD"
+	if _, err := json.Marshal(order); !errors.Is(err, comdirect.ErrInvalidOrder) {
+		t.Errorf("expected unknown side to be rejected when encoding, got: %v", err)
+	}
+
+	var request comdirect.OrderRequest
+	err := json.Unmarshal([]byte(`{"side":"BUY","orderType":"LIMIT","validityType":"GTW"}`), &request)
+	if !errors.Is(err, comdirect.ErrInvalidOrder) {
+		t.Errorf("expected unknown validity type to be rejected when decoding, got: %v", err)
+	}
+	order = testOrderRequest()
+	order.OrderType, order.TriggerLimit = comdirect.OrderTypeStopLimit, order.Limit
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("failed to encode order: %s", err)
+	}
+	if err := json.Unmarshal(data, &request); err != nil || request.OrderType != comdirect.OrderTypeStopLimit {
+		t.Errorf("expected order to be decoded, got %+v: %v", request, err)
+	}
+}
+
+func TestOrder_JSON(t *testing.T) {
+	var order comdirect.Order
+	err := json.Unmarshal([]byte(`{"orderType":"ICEBERG","orderStatus":"PARTIALLY_EXECUTED","side":"BUY"}`), &order)
+	if err != nil {
+		t.Fatalf("expected unknown values to be accepted in responses, got: %s", err)
+	}
+	if order.OrderType.Valid() || order.OrderStatus != "PARTIALLY_EXECUTED" || order.Side != comdirect.OrderSideBuy {
+		t.Errorf("unexpected order: %+v", order)
+	}
+}
+
+func TestOrderTypes_JSON(t *testing.T) {
+	var venue comdirect.Venue
+	err := json.Unmarshal([]byte(`{"orderTypes":{"ONE_CANCELS_OTHER":{"limitExtensions":["AON"]},"MARKET":{}}}`), &venue)
+	if err != nil {
+		t.Fatalf("failed to decode venue: %s", err)
+	}
+	oco, ok := venue.OrderTypes[comdirect.OrderTypeOneCancelsOther]
+	if len(venue.OrderTypes) != 2 || !ok || oco.LimitExtensions[0] != comdirect.LimitExtensionAllOrNone {
+		t.Errorf("unexpected order types: %+v", venue.OrderTypes)
+	}
+}
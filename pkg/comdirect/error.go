@@ -0,0 +1,144 @@
+package comdirect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors that can be used with errors.Is to check for the cause of an APIError.
+var (
+	ErrBadRequest    = errors.New("comdirect: bad request")
+	ErrUnauthorized  = errors.New("comdirect: unauthorized")
+	ErrForbidden     = errors.New("comdirect: forbidden")
+	ErrNotFound      = errors.New("comdirect: not found")
+	ErrUnprocessable = errors.New("comdirect: unprocessable entity")
+	ErrRateLimited   = errors.New("comdirect: rate limit exceeded")
+	ErrServerError   = errors.New("comdirect: server error")
+	ErrTANRequired   = errors.New("comdirect: TAN required")
+)
+
+// APIError is returned for every response of the comdirect REST API with a non 2xx status code.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code returned by comdirect, e.g. "invalid_token".
+	Code string
+	// Messages contains the messages returned by comdirect.
+	Messages []APIMessage
+	// RequestID is the request ID of the x-http-request-info header.
+	RequestID string
+	// Method and Endpoint identify the failed request.
+	Method   string
+	Endpoint string
+	// OnceAuthenticationInfo holds the x-once-authentication-info header if the response contained one.
+	OnceAuthenticationInfo string
+}
+
+// APIMessage represents a single message of an error response of the comdirect REST API.
+type APIMessage struct {
+	Severity string                 `json:"severity"`
+	Key      string                 `json:"key"`
+	Message  string                 `json:"message"`
+	Args     map[string]interface{} `json:"args,omitempty"`
+	Origin   []string               `json:"origin,omitempty"`
+}
+
+// apiErrorBody covers both the error format of the REST API and the one of the OAuth2 endpoints.
+type apiErrorBody struct {
+	Code             string       `json:"code"`
+	Messages         []APIMessage `json:"messages"`
+	Error            string       `json:"error"`
+	ErrorDescription string       `json:"error_description"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "comdirect: %s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Code != "" {
+		fmt.Fprintf(&b, ": %s", e.Code)
+	}
+	for _, m := range e.Messages {
+		if m.Message != "" && m.Message != e.Code {
+			fmt.Fprintf(&b, ": %s", m.Message)
+		}
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is reports whether the APIError matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrTANRequired:
+		return e.OnceAuthenticationInfo != "" && e.StatusCode >= 400 && e.StatusCode < 500
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnprocessable:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode < 600
+	}
+	return false
+}
+
+// newAPIError creates an APIError from a response and closes the response body.
+func newAPIError(res *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode:             res.StatusCode,
+		OnceAuthenticationInfo: res.Header.Get(OnceAuthenticationInfoHeaderKey),
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.Endpoint = res.Request.URL.Path
+		apiErr.RequestID = requestIDFromHeader(res.Request.Header)
+	}
+	if id := requestIDFromHeader(res.Header); id != "" {
+		apiErr.RequestID = id
+	}
+
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+
+	var errBody apiErrorBody
+	if err = json.Unmarshal(body, &errBody); err != nil {
+		apiErr.Messages = []APIMessage{{Severity: "ERROR", Message: strings.TrimSpace(string(body))}}
+		return apiErr
+	}
+	apiErr.Code = errBody.Code
+	apiErr.Messages = errBody.Messages
+	if errBody.Error != "" {
+		apiErr.Code = errBody.Error
+		apiErr.Messages = append(apiErr.Messages, APIMessage{
+			Severity: "ERROR",
+			Key:      errBody.Error,
+			Message:  errBody.ErrorDescription,
+		})
+	}
+	return apiErr
+}
+
+// requestIDFromHeader extracts the request ID of the x-http-request-info header.
+func requestIDFromHeader(header http.Header) string {
+	var info requestInfo
+	if err := json.Unmarshal([]byte(header.Get(HttpRequestInfoHeaderKey)), &info); err != nil {
+		return ""
+	}
+	return info.ClientRequestID.RequestID
+}
@@ -0,0 +1,91 @@
+package comdirect_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestAPIError_InvalidCredentials(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	options := server.AuthOptions()
+	options.Password = "wrong"
+	authenticator := comdirect.NewAuthenticator(options, server.ClientOptions()...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	_, err := authenticator.Authenticate(ctx)
+
+	var apiErr *comdirect.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got: %v", err)
+	}
+	if !errors.Is(err, comdirect.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got: %v", err)
+	}
+	if apiErr.Code != "invalid_grant" || apiErr.Endpoint != comdirect.OAuthTokenPath || apiErr.Method != http.MethodPost {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestAPIError_NotFound(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	_, err := client.Balance(ctx, "unknown")
+
+	var apiErr *comdirect.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, comdirect.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	if apiErr.RequestID == "" || len(apiErr.Messages) != 1 || apiErr.Messages[0].Key != "account.not.found" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		target error
+	}{
+		{"rate limited", http.StatusTooManyRequests, nil, comdirect.ErrRateLimited},
+		{"server error", http.StatusServiceUnavailable, nil, comdirect.ErrServerError},
+		{"unprocessable", http.StatusUnprocessableEntity, nil, comdirect.ErrUnprocessable},
+		{"forbidden", http.StatusForbidden, nil, comdirect.ErrForbidden},
+		{"TAN required", http.StatusUnauthorized, http.Header{comdirect.OnceAuthenticationInfoHeaderKey: {`{"id":"1","typ":"P_TAN"}`}}, comdirect.ErrTANRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"code":"error","messages":[{"severity":"ERROR","key":"error","message":"failed"}]}`))
+			}))
+			defer server.Close()
+
+			auth := comdirect.NewAuthentication(comdirect.AccessToken{AccessToken: "token", ExpiresIn: 600}, "session", time.Now())
+			client := comdirect.NewWithAuthentication(auth, comdirect.WithBaseURL(server.URL))
+			ctx, cancel := contextTimeout10Seconds()
+			defer cancel()
+
+			_, err := client.Reports(ctx)
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected %v, got: %v", tt.target, err)
+			}
+			if errors.Is(err, comdirect.ErrNotFound) {
+				t.Errorf("did not expect ErrNotFound: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package comdirect
+
+// Export unexported identifiers for the external comdirect_test package.
+
+var (
+	GenerateSessionID = generateSessionID
+	GenerateRequestID = generateRequestID
+
+	OnceAuthenticationHeaders = onceAuthenticationHeaders
+)
+
+func (a *Authenticator) AuthOptions() *AuthOptions {
+	return a.authOptions
+}
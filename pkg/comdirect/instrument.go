@@ -1,7 +1,7 @@
 package comdirect
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -32,18 +32,19 @@ type StaticData struct {
 
 // Instrument retrieves instrument information by WKN, ISIN or mnemonic
 func (c *Client) Instrument(instrument string) ([]Instrument, error) {
-	if !c.IsAuthenticated() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(context.Background())
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/brokerage/v1/instruments/%s", instrument)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    c.http.apiURL(fmt.Sprintf("/brokerage/v1/instruments/%s", instrument)),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 
 	instruments := &Instruments{}
@@ -1,30 +1,17 @@
-package comdirect
+package comdirect_test
 
 import (
-	"fmt"
-	"os"
 	"testing"
 )
 
 func TestClient_Instrument(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
-	}
-	client := NewWithAuthOptions(options)
-	ctx, cancel := contextTimeout10Seconds()
-	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
-	}
+	client, _ := newTestClient(t)
 
 	instruments, err := client.Instrument("865985")
 	if err != nil {
-		t.Errorf("failed to retrieve instruments: %s", err)
+		t.Fatalf("failed to retrieve instruments: %s", err)
+	}
+	if len(instruments) != 1 || instruments[0].ISIN != "US0378331005" {
+		t.Errorf("unexpected instruments: %+v", instruments)
 	}
-
-	fmt.Printf("successfully retrieved instrument:\n%+v", instruments[0])
 }
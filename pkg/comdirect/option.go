@@ -0,0 +1,164 @@
+package comdirect
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultBaseURL         = HttpsScheme + "://" + Host
+	DefaultRateLimit       = 10
+	DefaultRateBurst       = 10
+	DefaultTANPollInterval = 3 * time.Second
+	DefaultRefreshSkew     = 30 * time.Second
+)
+
+// Option configures the behavior of a Client or an Authenticator.
+type Option func(*options)
+
+// options collects the settings applied by an Option.
+type options struct {
+	baseURL      string
+	httpClient   *http.Client
+	transport    http.RoundTripper
+	limiter      *rate.Limiter
+	timeout      time.Duration
+	userAgent    string
+	pollInterval time.Duration
+
+	autoRefresh      bool
+	refreshSkew      time.Duration
+	onTokenRefreshed func(*Authentication)
+	tokenStore       TokenStore
+}
+
+// WithBaseURL sets the scheme, host and optional path prefix used for all requests,
+// e.g. to point the library at a local stub server. Defaults to DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests.
+// The given client is copied and never modified.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithTransport sets the http.RoundTripper of the underlying http.Client,
+// e.g. to route requests through a proxy or an instrumented transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithRateLimiter sets the rate.Limiter every request has to wait for.
+// Defaults to DefaultRateLimit requests per second as allowed by comdirect.
+func WithRateLimiter(limiter *rate.Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+// WithTimeout sets the timeout of the underlying http.Client. Defaults to DefaultHttpTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithTANPollInterval sets the interval in which the Authenticator polls the status
+// of a push TAN challenge. Defaults to DefaultTANPollInterval.
+func WithTANPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithAutoRefresh enables the transparent refresh of the access token of a Client.
+// The token is refreshed if it expires within the given skew, DefaultRefreshSkew if skew is zero.
+// Auto refresh requires the Client to be created with an Authenticator or AuthOptions.
+func WithAutoRefresh(skew time.Duration) Option {
+	return func(o *options) {
+		o.autoRefresh = true
+		o.refreshSkew = skew
+		if skew <= 0 {
+			o.refreshSkew = DefaultRefreshSkew
+		}
+	}
+}
+
+// WithOnTokenRefreshed sets a callback that is called with the new Authentication every time
+// the Client refreshed its access token, e.g. to persist it. The callback runs without holding
+// the lock of the Client, so it may call methods of the Client like GetAuthentication.
+func WithOnTokenRefreshed(callback func(*Authentication)) Option {
+	return func(o *options) {
+		o.onTokenRefreshed = callback
+	}
+}
+
+// WithTokenStore sets the TokenStore of a Client. A Client without Authentication loads it from the
+// TokenStore on first use, saves it after every authentication and refresh, and deletes it on revoke.
+func WithTokenStore(store TokenStore) Option {
+	return func(o *options) {
+		o.tokenStore = store
+	}
+}
+
+// applyOptions applies the given options on top of the default settings.
+func applyOptions(opts ...Option) *options {
+	o := &options{
+		baseURL:      DefaultBaseURL,
+		pollInterval: DefaultTANPollInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// newHTTPClient creates an HTTPClient from the given options.
+func newHTTPClient(o *options) *HTTPClient {
+	client := http.Client{Timeout: DefaultHttpTimeout}
+	if o.httpClient != nil {
+		client = *o.httpClient
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+	if o.timeout > 0 {
+		client.Timeout = o.timeout
+	}
+
+	limiter := o.limiter
+	if limiter == nil {
+		limiter = rate.NewLimiter(DefaultRateLimit, DefaultRateBurst)
+	}
+
+	baseURL, err := url.Parse(o.baseURL)
+	if err == nil && (baseURL.Scheme == "" || baseURL.Host == "") {
+		err = &url.Error{Op: "parse", URL: o.baseURL, Err: errInvalidBaseURL}
+	}
+
+	return &HTTPClient{
+		Client:    &client,
+		Limiter:   limiter,
+		baseURL:   baseURL,
+		userAgent: o.userAgent,
+		err:       err,
+	}
+}
@@ -0,0 +1,72 @@
+package comdirect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	var userAgent, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"paging":{"index":0,"matches":0},"values":[]}`))
+	}))
+	defer server.Close()
+
+	auth := NewAuthentication(AccessToken{AccessToken: "token", ExpiresIn: 600}, "session", time.Now())
+	client := NewWithAuthentication(auth, WithBaseURL(server.URL+"/prefix/"), WithUserAgent("go-comdirect-test"))
+
+	if _, err := client.Reports(context.Background()); err != nil {
+		t.Fatalf("failed to retrieve reports: %s", err)
+	}
+	if path != "/prefix/api/reports/participants/user/v1/allbalances" {
+		t.Errorf("unexpected request path: %s", path)
+	}
+	if userAgent != "go-comdirect-test" {
+		t.Errorf("unexpected user agent: %s", userAgent)
+	}
+}
+
+func TestWithBaseURL_Invalid(t *testing.T) {
+	auth := NewAuthentication(AccessToken{AccessToken: "token", ExpiresIn: 600}, "session", time.Now())
+	client := NewWithAuthentication(auth, WithBaseURL("localhost"))
+
+	if _, err := client.Reports(context.Background()); err == nil {
+		t.Error("expected error for base URL without scheme")
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	original := &http.Client{Timeout: time.Minute}
+	limiter := rate.NewLimiter(1, 1)
+	h := newHTTPClient(applyOptions(WithHTTPClient(original), WithTimeout(time.Second), WithRateLimiter(limiter)))
+
+	if h.Client == original {
+		t.Error("expected http.Client to be copied")
+	}
+	if original.Timeout != time.Minute {
+		t.Errorf("original http.Client was modified: %s", original.Timeout)
+	}
+	if h.Timeout != time.Second {
+		t.Errorf("expected timeout of 1s, got %s", h.Timeout)
+	}
+	if h.Limiter != limiter {
+		t.Error("expected configured rate limiter")
+	}
+}
+
+func TestNewWithAuthOptions_SharedHTTPClient(t *testing.T) {
+	client := NewWithAuthOptions(&AuthOptions{}, WithBaseURL("http://localhost:8080"))
+	if client.http != client.authenticator.http {
+		t.Error("expected client and authenticator to share the HTTPClient")
+	}
+	if u := client.authenticator.http.comdirectURL(OAuthTokenPath).String(); u != "http://localhost:8080/oauth/token" {
+		t.Errorf("unexpected token URL: %s", u)
+	}
+}
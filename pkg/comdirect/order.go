@@ -1,9 +1,13 @@
 package comdirect
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 const (
@@ -15,14 +19,44 @@ type Dimension struct {
 }
 
 type Venue struct {
-	Name          string     `json:"name"`
-	VenueID       string     `json:"venueId"`
-	Country       string     `json:"country"`
-	Type          string     `json:"type"`
-	Currencies    []string   `json:"currencies"`
-	Sides         []string   `json:"sides"`
-	ValidityTypes []string   `json:"validityTypes"`
-	OrderTypes    OrderTypes `json:"orderTypes"`
+	Name          string         `json:"name"`
+	VenueID       string         `json:"venueId"`
+	Country       string         `json:"country"`
+	Type          string         `json:"type"`
+	Currencies    []string       `json:"currencies"`
+	Sides         []OrderSide    `json:"sides"`
+	ValidityTypes []ValidityType `json:"validityTypes"`
+	OrderTypes    OrderTypes     `json:"orderTypes"`
+}
+
+// ValidateOrder checks that the venue supports the side, order type, validity type, limit extension,
+// trading restriction and currency of an OrderRequest, so that it is rejected before it is sent to comdirect.
+func (v *Venue) ValidateOrder(order *OrderRequest) error {
+	if order.VenueID != v.VenueID {
+		return fmt.Errorf("%w: order is placed at venue %q, not %s", ErrInvalidOrder, order.VenueID, v.Name)
+	}
+	if !slices.Contains(v.Sides, order.Side) {
+		return fmt.Errorf("%w: side %s is not supported by %s", ErrInvalidOrder, order.Side, v.Name)
+	}
+	capabilities, ok := v.OrderTypes[order.OrderType]
+	if !ok {
+		return fmt.Errorf("%w: order type %s is not supported by %s", ErrInvalidOrder, order.OrderType, v.Name)
+	}
+	if !slices.Contains(v.ValidityTypes, order.ValidityType) {
+		return fmt.Errorf("%w: validity type %s is not supported by %s", ErrInvalidOrder, order.ValidityType, v.Name)
+	}
+	if order.LimitExtension != "" && !slices.Contains(capabilities.LimitExtensions, order.LimitExtension) {
+		return fmt.Errorf("%w: limit extension %s is not supported for %s orders by %s",
+			ErrInvalidOrder, order.LimitExtension, order.OrderType, v.Name)
+	}
+	if order.TradingRestriction != "" && !slices.Contains(capabilities.TradingRestrictions, order.TradingRestriction) {
+		return fmt.Errorf("%w: trading restriction %s is not supported for %s orders by %s",
+			ErrInvalidOrder, order.TradingRestriction, order.OrderType, v.Name)
+	}
+	if order.Limit != nil && !slices.Contains(v.Currencies, order.Limit.Unit) {
+		return fmt.Errorf("%w: currency %s is not supported by %s", ErrInvalidOrder, order.Limit.Unit, v.Name)
+	}
+	return nil
 }
 
 type Dimensions struct {
@@ -30,32 +64,213 @@ type Dimensions struct {
 	Values []Dimension `json:"values"`
 }
 
-type OrderTypes struct {
-	Quote              OrderType `json:"QUOTE"`
-	Market             OrderType `json:"MARKET"`
-	StopMarket         OrderType `json:"STOP_MARKET"`
-	NextOrder          OrderType `json:"NEXT_ORDER"`
-	OneCancelsOther    OrderType `json:"ONE_CANCELS_ORDER"`
-	Limit              OrderType `json:"LIMIT"`
-	TrailingStopMarket OrderType `json:"TRAILING_STOP_MARKET"`
+// FindVenue returns the venue of the dimensions specified by its ID or, case-insensitively, by its name.
+func FindVenue(dimensions []Dimension, venue string) (*Venue, bool) {
+	for i := range dimensions {
+		for j, v := range dimensions[i].Venues {
+			if v.VenueID == venue || strings.EqualFold(v.Name, venue) {
+				return &dimensions[i].Venues[j], true
+			}
+		}
+	}
+	return nil, false
 }
 
-type OrderType struct {
-	LimitExtensions     []string `json:"limitExtensions"`
-	TradingRestrictions []string `json:"tradingRestrictions"`
+// OrderTypes are the order types supported by a Venue.
+type OrderTypes map[OrderType]OrderTypeCapabilities
+
+// OrderTypeCapabilities are the limit extensions and trading restrictions a Venue supports for an OrderType.
+type OrderTypeCapabilities struct {
+	LimitExtensions     []LimitExtension     `json:"limitExtensions"`
+	TradingRestrictions []TradingRestriction `json:"tradingRestrictions"`
 }
 
+// ErrInvalidOrder is returned if an OrderRequest is incomplete or inconsistent.
+var ErrInvalidOrder = errors.New("comdirect: invalid order")
+
+// OrderRequest describes a new order or the changes of an existing order specified by OrderID.
+// Limit is only set for limit orders, Validity only for the validity type GTD. Stop orders require
+// a TriggerLimit and trailing stop orders either an absolute or a relative trailing distance instead.
 type OrderRequest struct {
-	DepotID      string      `json:"depotId,omitempty"`
-	OrderID      string      `json:"orderId,omitempty"`
-	Side         string      `json:"side"`
+	DepotID      string       `json:"depotId,omitempty"`
+	OrderID      string       `json:"orderId,omitempty"`
+	Side         OrderSide    `json:"side,omitempty"`
+	InstrumentID string       `json:"instrumentId,omitempty"`
+	OrderType    OrderType    `json:"orderType,omitempty"`
+	Quantity     AmountValue  `json:"quantity"`
+	VenueID      string       `json:"venueId,omitempty"`
+	Limit        *AmountValue `json:"limit,omitempty"`
+	TriggerLimit *AmountValue `json:"triggerLimit,omitempty"`
+	// TrailingLimitDistAbs is the distance of the trigger limit to the price, TrailingLimitDistRel
+	// the distance in percent of the price.
+	TrailingLimitDistAbs *AmountValue `json:"trailingLimitDistAbs,omitempty"`
+	TrailingLimitDistRel *Decimal     `json:"trailingLimitDistRel,omitempty"`
+	ValidityType         ValidityType `json:"validityType,omitempty"`
+	Validity             string       `json:"validity,omitempty"`
+	// LimitExtension and TradingRestriction are optional, see OrderTypeCapabilities.
+	LimitExtension     LimitExtension     `json:"limitExtension,omitempty"`
+	TradingRestriction TradingRestriction `json:"tradingRestriction,omitempty"`
+	// QuoteTicketID and QuoteID are only set for quote orders, see Quote.OrderRequest.
+	QuoteTicketID string `json:"quoteTicketId,omitempty"`
+	QuoteID       string `json:"quoteId,omitempty"`
+}
+
+// Validate checks a new OrderRequest before it is sent to comdirect.
+func (o *OrderRequest) Validate() error {
+	switch {
+	case o.DepotID == "":
+		return fmt.Errorf("%w: depot ID is required", ErrInvalidOrder)
+	case o.Side == "":
+		return fmt.Errorf("%w: side is required", ErrInvalidOrder)
+	case o.InstrumentID == "":
+		return fmt.Errorf("%w: instrument ID is required", ErrInvalidOrder)
+	case o.OrderType == "":
+		return fmt.Errorf("%w: order type is required", ErrInvalidOrder)
+	case o.VenueID == "":
+		return fmt.Errorf("%w: venue ID is required", ErrInvalidOrder)
+	case o.ValidityType == "":
+		return fmt.Errorf("%w: validity type is required", ErrInvalidOrder)
+	case o.OrderType == OrderTypeLimit && o.Limit == nil:
+		return fmt.Errorf("%w: limit orders require a limit", ErrInvalidOrder)
+	case o.OrderType == OrderTypeMarket && o.Limit != nil:
+		return fmt.Errorf("%w: market orders must not have a limit", ErrInvalidOrder)
+	case o.OrderType == OrderTypeQuote && (o.QuoteTicketID == "" || o.QuoteID == "" || o.Limit == nil):
+		return fmt.Errorf("%w: quote orders require a quote ticket, a quote and its price as limit", ErrInvalidOrder)
+	case o.triggered() && o.TriggerLimit == nil:
+		return fmt.Errorf("%w: %s orders require a trigger limit", ErrInvalidOrder, o.OrderType)
+	case o.OrderType == OrderTypeStopMarket && o.Limit != nil:
+		return fmt.Errorf("%w: stop market orders must not have a limit", ErrInvalidOrder)
+	case (o.OrderType == OrderTypeStopLimit || o.OrderType == OrderTypeOneCancelsOther) && o.Limit == nil:
+		return fmt.Errorf("%w: %s orders require a limit", ErrInvalidOrder, o.OrderType)
+	case o.OrderType == OrderTypeTrailingStopMarket && o.Limit != nil:
+		return fmt.Errorf("%w: trailing stop market orders must not have a limit", ErrInvalidOrder)
+	case o.OrderType == OrderTypeTrailingStopLimit && o.Limit == nil:
+		return fmt.Errorf("%w: trailing stop limit orders require a limit", ErrInvalidOrder)
+	case o.trailing() && (o.TrailingLimitDistAbs == nil) == (o.TrailingLimitDistRel == nil):
+		return fmt.Errorf("%w: %s orders require either an absolute or a relative trailing distance", ErrInvalidOrder, o.OrderType)
+	case o.OrderType == OrderTypeNextOrder:
+		return fmt.Errorf("%w: order type %s is not supported", ErrInvalidOrder, o.OrderType)
+	case o.TriggerLimit != nil && !o.triggered():
+		return fmt.Errorf("%w: %s orders must not have a trigger limit", ErrInvalidOrder, o.OrderType)
+	case (o.TrailingLimitDistAbs != nil || o.TrailingLimitDistRel != nil) && !o.trailing():
+		return fmt.Errorf("%w: %s orders must not have a trailing distance", ErrInvalidOrder, o.OrderType)
+	}
+	return o.validateValues()
+}
+
+// triggered reports whether the order is a stop order with a trigger limit.
+func (o *OrderRequest) triggered() bool {
+	return o.OrderType == OrderTypeStopMarket || o.OrderType == OrderTypeStopLimit || o.OrderType == OrderTypeOneCancelsOther
+}
+
+// trailing reports whether the order is a trailing stop order.
+func (o *OrderRequest) trailing() bool {
+	return o.OrderType == OrderTypeTrailingStopMarket || o.OrderType == OrderTypeTrailingStopLimit
+}
+
+// validateChange checks an OrderRequest that changes the order specified by OrderID.
+func (o *OrderRequest) validateChange() error {
+	if o.OrderID == "" {
+		return fmt.Errorf("%w: order ID is required", ErrInvalidOrder)
+	}
+	return o.validateValues()
+}
+
+func (o *OrderRequest) validateValues() error {
+	if err := o.validateEnums(); err != nil {
+		return err
+	}
+	if o.Quantity.Value.Sign() <= 0 {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidOrder)
+	}
+	if o.Limit != nil && o.Limit.Value.Sign() <= 0 {
+		return fmt.Errorf("%w: limit must be positive", ErrInvalidOrder)
+	}
+	if o.TriggerLimit != nil && o.TriggerLimit.Value.Sign() <= 0 {
+		return fmt.Errorf("%w: trigger limit must be positive", ErrInvalidOrder)
+	}
+	if (o.TrailingLimitDistAbs != nil && o.TrailingLimitDistAbs.Value.Sign() <= 0) ||
+		(o.TrailingLimitDistRel != nil && o.TrailingLimitDistRel.Sign() <= 0) {
+		return fmt.Errorf("%w: trailing distance must be positive", ErrInvalidOrder)
+	}
+	if o.ValidityType == ValidityTypeGoodTillDate && o.Validity == "" {
+		return fmt.Errorf("%w: validity type GTD requires a validity date", ErrInvalidOrder)
+	}
+	if _, err := ParseDate(o.Validity); err != nil {
+		return fmt.Errorf("%w: validity: %w", ErrInvalidOrder, err)
+	}
+	return nil
+}
+
+// validateEnums checks that the enums of the OrderRequest are either empty or known values.
+func (o *OrderRequest) validateEnums() error {
+	switch {
+	case o.Side != "" && !o.Side.Valid():
+		return fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, o.Side)
+	case o.OrderType != "" && !o.OrderType.Valid():
+		return fmt.Errorf("%w: unknown order type %q", ErrInvalidOrder, o.OrderType)
+	case o.ValidityType != "" && !o.ValidityType.Valid():
+		return fmt.Errorf("%w: unknown validity type %q", ErrInvalidOrder, o.ValidityType)
+	case o.LimitExtension != "" && !o.LimitExtension.Valid():
+		return fmt.Errorf("%w: unknown limit extension %q", ErrInvalidOrder, o.LimitExtension)
+	case o.TradingRestriction != "" && !o.TradingRestriction.Valid():
+		return fmt.Errorf("%w: unknown trading restriction %q", ErrInvalidOrder, o.TradingRestriction)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the OrderRequest and fails if one of its enums has an unknown value.
+func (o OrderRequest) MarshalJSON() ([]byte, error) {
+	if err := o.validateEnums(); err != nil {
+		return nil, err
+	}
+	type orderRequest OrderRequest
+	return json.Marshal(orderRequest(o))
+}
+
+// UnmarshalJSON decodes an OrderRequest and fails if one of its enums has an unknown value.
+// Responses like Order accept unknown values instead.
+func (o *OrderRequest) UnmarshalJSON(data []byte) error {
+	type orderRequest OrderRequest
+	if err := json.Unmarshal(data, (*orderRequest)(o)); err != nil {
+		return err
+	}
+	return o.validateEnums()
+}
+
+// CostIndication is the ex-ante cost information of an order required by MiFID II. Relative costs are
+// percentages of the order volume.
+type CostIndication struct {
+	DepotID      string      `json:"depotId"`
 	InstrumentID string      `json:"instrumentId"`
-	OrderType    string      `json:"orderType"`
+	Side         OrderSide   `json:"side"`
 	Quantity     AmountValue `json:"quantity"`
-	VenueID      string      `json:"venueId"`
-	Limit        AmountValue `json:"limit"`
-	ValidityType string      `json:"validityType"`
-	Validity     string      `json:"validity"`
+	OrderVolume  AmountValue `json:"orderVolume"`
+	// OrderCosts are the costs of the service of comdirect, e.g. the order commission.
+	OrderCosts Cost `json:"orderCosts"`
+	// ProductCosts are the costs of the instrument itself, e.g. the running costs of a fund.
+	ProductCosts Cost `json:"productCosts"`
+	// ThirdPartyFees are the fees of third parties, e.g. the fees of the venue.
+	ThirdPartyFees Cost           `json:"thirdPartyFees"`
+	TotalCosts     Cost           `json:"totalCosts"`
+	EffectOnReturn EffectOnReturn `json:"effectOnReturn"`
+}
+
+// Cost is an absolute cost and its percentage of the order volume.
+type Cost struct {
+	Amount   AmountValue `json:"amount"`
+	Relative Decimal     `json:"relative"`
+}
+
+// EffectOnReturn shows how the costs reduce the return of an investment over its holding period.
+type EffectOnReturn struct {
+	FirstYear      Cost `json:"firstYear"`
+	FollowingYears Cost `json:"followingYears"`
+	LastYear       Cost `json:"lastYear"`
+}
+
+type CostIndications struct {
+	Values []CostIndication `json:"values"`
 }
 
 type Orders struct {
@@ -64,36 +279,36 @@ type Orders struct {
 }
 
 type Order struct {
-	DepotID             string      `json:"depotId"`
-	SettlementAccountID string      `json:"settlementAccountId"`
-	OrderID             string      `json:"orderID"`
-	CreationTimestamp   string      `json:"creationTimestamp"`
-	LegNumber           string      `json:"legNumber"`
-	BestEx              bool        `json:"bestEx"`
-	OrderType           string      `json:"orderType"`
-	OrderStatus         string      `json:"orderStatus"`
-	SubOrders           []Order     `json:"subOrders"`
-	Side                string      `json:"side"`
-	InstrumentID        string      `json:"instrumentId"`
-	QuoteTicketID       string      `json:"quoteTicketId"`
-	QuoteID             string      `json:"quoteID"`
-	VenueID             string      `json:"venueID"`
-	Quantity            AmountValue `json:"quantity"`
-	LimitExtension      string      `json:"limitExtension"`
-	TradingRestriction  string      `json:"tradingRestriction"`
-	Limit               AmountValue `json:"limit"`
-	TriggerLimit        AmountValue `json:"triggerLimit"`
-	// TODO: AmountString
-	TrailingLimitDistAbs string `json:"trailingLimitDistAbs"`
-	// TODO: PercentageString
-	TrailingLimitDistRel string      `json:"trailingLimitDistRel"`
-	ValidityType         string      `json:"validityType"`
-	Validity             string      `json:"validity"`
-	OpenQuantity         AmountValue `json:"openQuantity"`
-	CancelledQuantity    AmountValue `json:"cancelledQuantity"`
-	ExecutedQuantity     AmountValue `json:"executedQuantity"`
-	ExpectedValue        AmountValue `json:"expectedValue"`
-	Executions           []Execution `json:"executions"`
+	DepotID             string             `json:"depotId"`
+	SettlementAccountID string             `json:"settlementAccountId"`
+	OrderID             string             `json:"orderID"`
+	CreationTimestamp   Timestamp          `json:"creationTimestamp"`
+	LegNumber           string             `json:"legNumber"`
+	BestEx              bool               `json:"bestEx"`
+	OrderType           OrderType          `json:"orderType"`
+	OrderStatus         OrderStatus        `json:"orderStatus"`
+	SubOrders           []Order            `json:"subOrders"`
+	Side                OrderSide          `json:"side"`
+	InstrumentID        string             `json:"instrumentId"`
+	QuoteTicketID       string             `json:"quoteTicketId"`
+	QuoteID             string             `json:"quoteID"`
+	VenueID             string             `json:"venueID"`
+	Quantity            AmountValue        `json:"quantity"`
+	LimitExtension      LimitExtension     `json:"limitExtension"`
+	TradingRestriction  TradingRestriction `json:"tradingRestriction"`
+	Limit               AmountValue        `json:"limit"`
+	TriggerLimit        AmountValue        `json:"triggerLimit"`
+	// TrailingLimitDistAbs and TrailingLimitDistRel are the trailing distances of trailing stop orders,
+	// see OrderRequest.
+	TrailingLimitDistAbs AmountValue  `json:"trailingLimitDistAbs"`
+	TrailingLimitDistRel Decimal      `json:"trailingLimitDistRel"`
+	ValidityType         ValidityType `json:"validityType"`
+	Validity             string       `json:"validity"`
+	OpenQuantity         AmountValue  `json:"openQuantity"`
+	CancelledQuantity    AmountValue  `json:"cancelledQuantity"`
+	ExecutedQuantity     AmountValue  `json:"executedQuantity"`
+	ExpectedValue        AmountValue  `json:"expectedValue"`
+	Executions           []Execution  `json:"executions"`
 }
 
 type Execution struct {
@@ -101,80 +316,244 @@ type Execution struct {
 	ExecutionNumber    int         `json:"executionNumber"`
 	ExecutedQuantity   AmountValue `json:"executedQuantity"`
 	ExecutionPrice     AmountValue `json:"executionPrice"`
-	ExecutionTimestamp string      `json:"executionTimestamp"`
+	ExecutionTimestamp Timestamp   `json:"executionTimestamp"`
 }
 
-func (c *Client) Dimensions() ([]Dimension, error) {
-	if !c.IsAuthenticated() {
-		return nil, errors.New(CLIENT_NOT_AUTHENTICATED)
+// Dimensions returns the venues with the sides, order types, validity types and currencies they support,
+// see Venue.ValidateOrder.
+func (c *Client) Dimensions(ctx context.Context) ([]Dimension, error) {
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
 
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL("/brokerage/v3/orders/dimensions"),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    c.http.apiURL("/brokerage/v3/orders/dimensions"),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
+	req = req.WithContext(ctx)
 
 	dimensions := &Dimensions{}
 	_, err = c.http.exchange(req, dimensions)
 	return dimensions.Values, err
 }
 
-func (c *Client) Orders(depotID string) ([]Order, error) {
-	if !c.IsAuthenticated() {
-		return nil, errors.New(CLIENT_NOT_AUTHENTICATED)
+// Orders returns a page of the orders of the depot specified by its ID. Use an OrderQuery to filter
+// the orders by status, side, order type, venue or instrument.
+func (c *Client) Orders(ctx context.Context, depotID string, options ...Options) (*Orders, error) {
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
+	url := c.http.apiURL(fmt.Sprintf("/brokerage/depots/%s/v3/orders", depotID))
+	encodeOptions(url, options)
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL(fmt.Sprintf("/brokerage/depots/%s/v3/orders", depotID)),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    url,
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
+	req = req.WithContext(ctx)
 
 	orders := &Orders{}
 	_, err = c.http.exchange(req, orders)
-	return orders.Values, nil
+	return orders, err
 }
 
-func (c *Client) Order(orderID string) {
+// Order returns the order specified by its ID including its sub-orders and executions.
+func (c *Client) Order(ctx context.Context, orderID string) (*Order, error) {
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := requestInfoJSON(auth.sessionID)
+	if err != nil {
+		return nil, err
+	}
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    c.http.apiURL(fmt.Sprintf("/brokerage/v3/orders/%s", orderID)),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
+	}
+	req = req.WithContext(ctx)
 
+	order := &Order{}
+	_, err = c.http.exchange(req, order)
+	return order, err
 }
 
-func (c *Client) CreateOrder(order OrderRequest, tan string) {
-	// TODO
+// CreateOrder pre-validates, validates and places a new order.
+// The TAN challenge is solved with the TANHandler of the AuthOptions, push TAN challenges are
+// awaited until they are approved in the photoTAN app.
+func (c *Client) CreateOrder(ctx context.Context, order *OrderRequest) (*Order, error) {
+	if err := c.PreValidateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	challenge, tan, err := c.authorize(ctx, func(tanType TANType) (*TANChallenge, error) {
+		return c.validateOrder(ctx, order, tanType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteOrder(ctx, order, challenge, tan)
 }
 
-func (c *Client) UpdateOrder(orderID string, tan string) {
-	// TODO
+// PreValidateOrder checks an order with comdirect without creating a TAN challenge, e.g. whether the
+// instrument can be traded at the venue and the available cash amount covers a buy order.
+func (c *Client) PreValidateOrder(ctx context.Context, order *OrderRequest) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/orders/prevalidation", order)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
 }
 
-func (c *Client) DeleteOrder(orderID string, tan string) {
-	// TODO
+// ValidateOrder validates an order and returns the TAN challenge required to place it with ExecuteOrder.
+func (c *Client) ValidateOrder(ctx context.Context, order *OrderRequest) (*TANChallenge, error) {
+	return c.validateOrder(ctx, order, "")
+}
+
+func (c *Client) validateOrder(ctx context.Context, order *OrderRequest, tanType TANType) (*TANChallenge, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	return c.requestTANChallenge(ctx, "/brokerage/v3/orders/validation", order, tanType)
 }
 
-func (c *Client) PreValidateOrder() {
-	// TODO
+// ExecuteOrder places an order validated by ValidateOrder with the TAN of the challenge.
+// For TANTypePush challenges the TAN is empty and the challenge must be approved in the photoTAN app first.
+func (c *Client) ExecuteOrder(ctx context.Context, order *OrderRequest, challenge *TANChallenge, tan string) (*Order, error) {
+	if challenge == nil {
+		return nil, errors.New("TAN challenge cannot be nil")
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/orders", order)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = onceAuthenticationHeaders(req.Header, challenge, tan)
+
+	created := &Order{}
+	_, err = c.http.exchange(req, created)
+	return created, err
 }
 
-func (c *Client) ValidateOrder() {
-	// TODO
+// UpdateOrder validates and executes the changes of the order specified by the OrderID of order,
+// e.g. a new limit, quantity or validity. The TAN challenge is solved like for CreateOrder.
+func (c *Client) UpdateOrder(ctx context.Context, order *OrderRequest) (*Order, error) {
+	challenge, tan, err := c.authorize(ctx, func(tanType TANType) (*TANChallenge, error) {
+		return c.validateOrderUpdate(ctx, order, tanType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteOrderUpdate(ctx, order, challenge, tan)
 }
 
-func (c *Client) ExAnteOrder() {
-	// TODO
+// ValidateOrderUpdate validates the changes of the order specified by the OrderID of order and returns
+// the TAN challenge required to execute them with ExecuteOrderUpdate.
+func (c *Client) ValidateOrderUpdate(ctx context.Context, order *OrderRequest) (*TANChallenge, error) {
+	return c.validateOrderUpdate(ctx, order, "")
 }
 
-func (c *Client) ValidateOrderUpdate(order OrderRequest) {
-	// TODO
+func (c *Client) validateOrderUpdate(ctx context.Context, order *OrderRequest, tanType TANType) (*TANChallenge, error) {
+	if err := order.validateChange(); err != nil {
+		return nil, err
+	}
+	return c.requestTANChallenge(ctx, fmt.Sprintf("/brokerage/v3/orders/%s/validation", order.OrderID), order, tanType)
 }
 
-func (c *Client) ValidateOrderDeletion(orderID string) {
-	// TODO
+// ExecuteOrderUpdate changes an order as validated by ValidateOrderUpdate with the TAN of the challenge.
+func (c *Client) ExecuteOrderUpdate(ctx context.Context, order *OrderRequest, challenge *TANChallenge, tan string) (*Order, error) {
+	if challenge == nil {
+		return nil, errors.New("TAN challenge cannot be nil")
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPatch, fmt.Sprintf("/brokerage/v3/orders/%s", order.OrderID), order)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = onceAuthenticationHeaders(req.Header, challenge, tan)
+
+	updated := &Order{}
+	_, err = c.http.exchange(req, updated)
+	return updated, err
+}
+
+// DeleteOrder validates and executes the deletion of the order specified by its ID, which cancels the
+// open quantity of the order. The TAN challenge is solved like for CreateOrder.
+func (c *Client) DeleteOrder(ctx context.Context, orderID string) error {
+	challenge, tan, err := c.authorize(ctx, func(tanType TANType) (*TANChallenge, error) {
+		return c.validateOrderDeletion(ctx, orderID, tanType)
+	})
+	if err != nil {
+		return err
+	}
+	return c.ExecuteOrderDeletion(ctx, orderID, challenge, tan)
+}
+
+// ValidateOrderDeletion validates the deletion of the order specified by its ID and returns the
+// TAN challenge required to delete it with ExecuteOrderDeletion.
+func (c *Client) ValidateOrderDeletion(ctx context.Context, orderID string) (*TANChallenge, error) {
+	return c.validateOrderDeletion(ctx, orderID, "")
+}
+
+func (c *Client) validateOrderDeletion(ctx context.Context, orderID string, tanType TANType) (*TANChallenge, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order ID is required", ErrInvalidOrder)
+	}
+	// an empty body distinguishes the validation of a deletion from the one of an update
+	return c.requestTANChallenge(ctx, fmt.Sprintf("/brokerage/v3/orders/%s/validation", orderID), struct{}{}, tanType)
+}
+
+// ExecuteOrderDeletion deletes an order as validated by ValidateOrderDeletion with the TAN of the challenge.
+func (c *Client) ExecuteOrderDeletion(ctx context.Context, orderID string, challenge *TANChallenge, tan string) error {
+	if challenge == nil {
+		return errors.New("TAN challenge cannot be nil")
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodDelete, fmt.Sprintf("/brokerage/v3/orders/%s", orderID), nil)
+	if err != nil {
+		return err
+	}
+	req.Header = onceAuthenticationHeaders(req.Header, challenge, tan)
+
+	res, err := c.http.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// ExAnteOrder returns the ex-ante cost information of an order, which must be shown to the investor
+// before the order is placed.
+func (c *Client) ExAnteOrder(ctx context.Context, order *OrderRequest) (*CostIndication, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/orders/costindicationexante", order)
+	if err != nil {
+		return nil, err
+	}
+
+	indications := &CostIndications{}
+	if _, err = c.http.exchange(req, indications); err != nil {
+		return nil, err
+	}
+	if len(indications.Values) == 0 {
+		return nil, errors.New("response contains no cost indication")
+	}
+	return &indications.Values[0], nil
 }
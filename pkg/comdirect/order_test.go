@@ -1,30 +1,315 @@
-package comdirect
+package comdirect_test
 
 import (
-	"fmt"
-	"os"
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
 )
 
 func TestClient_Dimensions(t *testing.T) {
-	options := &AuthOptions{
-		Username:     os.Getenv("COMDIRECT_USERNAME"),
-		Password:     os.Getenv("COMDIRECT_PASSWORD"),
-		ClientId:     os.Getenv("COMDIRECT_CLIENT_ID"),
-		ClientSecret: os.Getenv("COMDIRECT_CLIENT_SECRET"),
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	dimensions, err := client.Dimensions(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve dimensions: %s", err)
+	}
+	if len(dimensions) != 1 || len(dimensions[0].Venues) != 1 {
+		t.Errorf("unexpected dimensions: %+v", dimensions)
 	}
-	client := NewWithAuthOptions(options)
+
+	cancel()
+	if _, err = client.Dimensions(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func testOrderRequest() *comdirect.OrderRequest {
+	return &comdirect.OrderRequest{
+		DepotID:      comdirecttest.DepotID,
+		Side:         comdirect.OrderSideBuy,
+		InstrumentID: comdirecttest.InstrumentID,
+		OrderType:    comdirect.OrderTypeLimit,
+		Quantity:     comdirect.AmountValue{Value: comdirect.MustParseDecimal("10"), Unit: "XXX"},
+		VenueID:      comdirecttest.VenueID,
+		Limit:        &comdirect.AmountValue{Value: comdirect.MustParseDecimal("150.50"), Unit: "EUR"},
+		ValidityType: comdirect.ValidityTypeGoodTillDate,
+		Validity:     "2024-04-30",
+	}
+}
+
+func TestClient_CreateOrder(t *testing.T) {
+	client, _ := newTestClient(t)
 	ctx, cancel := contextTimeout10Seconds()
 	defer cancel()
-	if _, err := client.Authenticate(ctx); err != nil {
-		t.Errorf("authentication failed: %s", err)
-		return
+
+	order, err := client.CreateOrder(ctx, testOrderRequest())
+	if err != nil {
+		t.Fatalf("failed to create order: %s", err)
+	}
+	if order.OrderID == "" || order.OrderStatus != "OPEN" || order.Limit.Value.String() != "150.50" {
+		t.Errorf("unexpected order: %+v", order)
+	}
+	orders, err := client.Orders(ctx, comdirecttest.DepotID)
+	if err != nil {
+		t.Fatalf("failed to retrieve orders: %s", err)
+	}
+	if len(orders.Values) != 1 || orders.Values[0].OrderID != order.OrderID {
+		t.Errorf("expected the created order, got: %+v", orders)
 	}
 
-	instruments, err := client.Dimensions()
+	change := &comdirect.OrderRequest{
+		OrderID:  order.OrderID,
+		Quantity: comdirect.AmountValue{Value: comdirect.MustParseDecimal("5"), Unit: "XXX"},
+		Limit:    &comdirect.AmountValue{Value: comdirect.MustParseDecimal("145"), Unit: "EUR"},
+	}
+	updated, err := client.UpdateOrder(ctx, change)
 	if err != nil {
-		t.Errorf("failed to retrieve instruments: %s", err)
+		t.Fatalf("failed to update order: %s", err)
+	}
+	if updated.Quantity.Value.String() != "5" || updated.Limit.Value.String() != "145" || updated.Validity != "2024-04-30" {
+		t.Errorf("unexpected updated order: %+v", updated)
 	}
 
-	fmt.Printf("successfully retrieved instrument:\n%+v", instruments[0])
+	if err = client.DeleteOrder(ctx, order.OrderID); err != nil {
+		t.Fatalf("failed to delete order: %s", err)
+	}
+	cancelled, err := client.Order(ctx, order.OrderID)
+	if err != nil {
+		t.Fatalf("failed to retrieve order: %s", err)
+	}
+	if cancelled.OrderStatus != "CANCELLED_USER" || cancelled.CancelledQuantity.Value.String() != "5" {
+		t.Errorf("expected cancelled order, got: %+v", cancelled)
+	}
+	if err = client.DeleteOrder(ctx, order.OrderID); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected deletion of a cancelled order to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_ExecuteOrder_ModifiedOrder(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	order := testOrderRequest()
+	challenge, err := client.ValidateOrder(ctx, order)
+	if err != nil {
+		t.Fatalf("failed to validate order: %s", err)
+	}
+	if challenge.ID == "" {
+		t.Fatalf("expected TAN challenge, got: %+v", challenge)
+	}
+	order.Quantity.Value = comdirect.MustParseDecimal("1000")
+	if _, err = client.ExecuteOrder(ctx, order, challenge, comdirecttest.TAN); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected order that differs from the validated one to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_PreValidateOrder(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	if err := client.PreValidateOrder(ctx, testOrderRequest()); err != nil {
+		t.Errorf("expected valid order, got: %s", err)
+	}
+	order := testOrderRequest()
+	order.VenueID = "UNKNOWN"
+	if err := client.PreValidateOrder(ctx, order); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected unknown venue to be rejected, got: %v", err)
+	}
+	if _, err := client.ValidateOrderDeletion(ctx, "unknown"); !errors.Is(err, comdirect.ErrNotFound) {
+		t.Errorf("expected unknown order to be rejected, got: %v", err)
+	}
+}
+
+func TestOrderRequest_Validate(t *testing.T) {
+	tests := map[string]func(o *comdirect.OrderRequest){
+		"missing depot":       func(o *comdirect.OrderRequest) { o.DepotID = "" },
+		"invalid side":        func(o *comdirect.OrderRequest) { o.Side = "HOLD" },
+		"missing instrument":  func(o *comdirect.OrderRequest) { o.InstrumentID = "" },
+		"missing venue":       func(o *comdirect.OrderRequest) { o.VenueID = "" },
+		"zero quantity":       func(o *comdirect.OrderRequest) { o.Quantity.Value = comdirect.MustParseDecimal("0") },
+		"limit without limit": func(o *comdirect.OrderRequest) { o.Limit = nil },
+		"market with limit":   func(o *comdirect.OrderRequest) { o.OrderType = comdirect.OrderTypeMarket },
+		"unknown order type":  func(o *comdirect.OrderRequest) { o.OrderType = "LIMITED" },
+		"unknown validity":    func(o *comdirect.OrderRequest) { o.ValidityType = "GTW" },
+		"unknown extension":   func(o *comdirect.OrderRequest) { o.LimitExtension = "ALL" },
+		"limit with trigger":  func(o *comdirect.OrderRequest) { o.TriggerLimit = o.Limit },
+		"stop without trigger": func(o *comdirect.OrderRequest) {
+			o.OrderType, o.Limit = comdirect.OrderTypeStopMarket, nil
+		},
+		"OCO without trigger": func(o *comdirect.OrderRequest) { o.OrderType = comdirect.OrderTypeOneCancelsOther },
+		"trailing without distance": func(o *comdirect.OrderRequest) {
+			o.OrderType, o.Limit = comdirect.OrderTypeTrailingStopMarket, nil
+		},
+		"next order":                 func(o *comdirect.OrderRequest) { o.OrderType = comdirect.OrderTypeNextOrder },
+		"stop limit without trigger": func(o *comdirect.OrderRequest) { o.OrderType = comdirect.OrderTypeStopLimit },
+		"trailing limit without limit": func(o *comdirect.OrderRequest) {
+			distance := comdirect.MustParseDecimal("5")
+			o.OrderType, o.TrailingLimitDistRel, o.Limit = comdirect.OrderTypeTrailingStopLimit, &distance, nil
+		},
+		"negative limit":   func(o *comdirect.OrderRequest) { o.Limit.Value = comdirect.MustParseDecimal("-1") },
+		"GTD without date": func(o *comdirect.OrderRequest) { o.Validity = "" },
+		"invalid validity": func(o *comdirect.OrderRequest) { o.Validity = "30.04.2024" },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			order := testOrderRequest()
+			modify(order)
+			if err := order.Validate(); !errors.Is(err, comdirect.ErrInvalidOrder) {
+				t.Errorf("expected ErrInvalidOrder, got: %v", err)
+			}
+		})
+	}
+	if err := testOrderRequest().Validate(); err != nil {
+		t.Errorf("expected valid order, got: %v", err)
+	}
+
+	stop := testOrderRequest()
+	stop.OrderType, stop.TriggerLimit, stop.Limit = comdirect.OrderTypeStopMarket, stop.Limit, nil
+	if err := stop.Validate(); err != nil {
+		t.Errorf("expected valid stop market order, got: %v", err)
+	}
+	trailing := testOrderRequest()
+	distance := comdirect.MustParseDecimal("5")
+	trailing.OrderType, trailing.TrailingLimitDistRel, trailing.Limit = comdirect.OrderTypeTrailingStopMarket, &distance, nil
+	if err := trailing.Validate(); err != nil {
+		t.Errorf("expected valid trailing stop market order, got: %v", err)
+	}
+	stopLimit := testOrderRequest()
+	stopLimit.OrderType, stopLimit.TriggerLimit = comdirect.OrderTypeStopLimit, stopLimit.Limit
+	if err := stopLimit.Validate(); err != nil {
+		t.Errorf("expected valid stop limit order, got: %v", err)
+	}
+	trailingLimit := testOrderRequest()
+	trailingLimit.OrderType, trailingLimit.TrailingLimitDistAbs = comdirect.OrderTypeTrailingStopLimit, trailingLimit.Limit
+	if err := trailingLimit.Validate(); err != nil {
+		t.Errorf("expected valid trailing stop limit order, got: %v", err)
+	}
+}
+
+func TestClient_ExAnteOrder(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	costs, err := client.ExAnteOrder(ctx, testOrderRequest())
+	if err != nil {
+		t.Fatalf("failed to retrieve costs: %s", err)
+	}
+	if costs.OrderVolume.String() != "1505.00 EUR" || costs.OrderCosts.Amount.String() != "9.90 EUR" {
+		t.Errorf("unexpected order costs: %+v", costs)
+	}
+	if costs.TotalCosts.Amount.String() != "11.40 EUR" || costs.TotalCosts.Relative.String() != "0.76" {
+		t.Errorf("unexpected total costs: %+v", costs.TotalCosts)
+	}
+	if !costs.EffectOnReturn.FirstYear.Amount.Value.Equal(costs.TotalCosts.Amount.Value) {
+		t.Errorf("expected the total costs to reduce the return of the first year, got: %+v", costs.EffectOnReturn)
+	}
+
+	market := testOrderRequest()
+	market.OrderType = comdirect.OrderTypeMarket
+	market.Limit = nil
+	market.Quantity.Value = comdirect.MustParseDecimal("40")
+	costs, err = client.ExAnteOrder(ctx, market)
+	if err != nil {
+		t.Fatalf("failed to retrieve costs: %s", err)
+	}
+	if costs.OrderVolume.String() != "6820.00 EUR" || costs.TotalCosts.Amount.String() != "23.45 EUR" {
+		t.Errorf("unexpected costs of market order: %+v", costs)
+	}
+}
+
+func TestClient_Orders_Query(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	if _, err := client.CreateOrder(ctx, testOrderRequest()); err != nil {
+		t.Fatalf("failed to create order: %s", err)
+	}
+	quote := requestTestQuote(t, client, comdirect.OrderSideSell)
+	executed, err := client.ExecuteQuote(ctx, comdirecttest.DepotID, quote)
+	if err != nil {
+		t.Fatalf("failed to execute quote: %s", err)
+	}
+
+	tests := []struct {
+		query    *comdirect.OrderQuery
+		expected int
+	}{
+		{comdirect.NewOrderQuery(), 2},
+		{comdirect.NewOrderQuery().Status(comdirect.OrderStatusOpen), 1},
+		{comdirect.NewOrderQuery().Status(comdirect.OrderStatusExecuted).Side(comdirect.OrderSideSell), 1},
+		{comdirect.NewOrderQuery().Side(comdirect.OrderSideSell).OrderType(comdirect.OrderTypeLimit), 0},
+		{comdirect.NewOrderQuery().Venue(comdirecttest.VenueID).Instrument(comdirecttest.InstrumentID), 2},
+		{comdirect.NewOrderQuery().Instrument("unknown"), 0},
+	}
+	for _, test := range tests {
+		options := test.query.Options()
+		orders, err := client.Orders(ctx, comdirecttest.DepotID, options)
+		if err != nil {
+			t.Fatalf("failed to retrieve orders: %s", err)
+		}
+		if len(orders.Values) != test.expected {
+			t.Errorf("expected %d orders for %v, got %d", test.expected, options.Values(), len(orders.Values))
+		}
+	}
+
+	order, err := client.Order(ctx, executed.OrderID)
+	if err != nil {
+		t.Fatalf("failed to retrieve order: %s", err)
+	}
+	if len(order.Executions) != 1 || order.Executions[0].ExecutedQuantity.Value.String() != "10" {
+		t.Errorf("expected order with execution, got: %+v", order)
+	}
+	if _, err = client.Order(ctx, "unknown"); !errors.Is(err, comdirect.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestVenue_ValidateOrder(t *testing.T) {
+	venue, ok := comdirect.FindVenue(comdirecttest.DefaultFixtures().Dimensions, "xetra")
+	if !ok || venue.VenueID != comdirecttest.VenueID {
+		t.Fatalf("expected to find Xetra by its name, got: %+v", venue)
+	}
+	tests := map[string]func(o *comdirect.OrderRequest){
+		"other venue":         func(o *comdirect.OrderRequest) { o.VenueID = "unknown" },
+		"unsupported type":    func(o *comdirect.OrderRequest) { o.OrderType = comdirect.OrderTypeStopMarket },
+		"unsupported GTC":     func(o *comdirect.OrderRequest) { o.ValidityType = comdirect.ValidityTypeGoodTillCancelled },
+		"unsupported IOC":     func(o *comdirect.OrderRequest) { o.LimitExtension = comdirect.LimitExtensionImmediateOrCancel },
+		"unsupported auction": func(o *comdirect.OrderRequest) { o.TradingRestriction = comdirect.TradingRestrictionAuction },
+		"unsupported USD":     func(o *comdirect.OrderRequest) { o.Limit.Unit = "USD" },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			order := testOrderRequest()
+			modify(order)
+			if err := venue.ValidateOrder(order); !errors.Is(err, comdirect.ErrInvalidOrder) {
+				t.Errorf("expected ErrInvalidOrder, got: %v", err)
+			}
+		})
+	}
+	order := testOrderRequest()
+	order.LimitExtension = comdirect.LimitExtensionAllOrNone
+	if err := venue.ValidateOrder(order); err != nil {
+		t.Errorf("expected order to be supported, got: %v", err)
+	}
+}
+
+func TestOrder_TrailingDistances(t *testing.T) {
+	var order comdirect.Order
+	data := `{"orderType":"TRAILING_STOP_MARKET","trailingLimitDistAbs":{"value":"2.50","unit":"EUR"},"trailingLimitDistRel":"1.5"}`
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		t.Fatalf("failed to decode order: %s", err)
+	}
+	if order.TrailingLimitDistAbs.String() != "2.50 EUR" || !order.TrailingLimitDistRel.Equal(comdirect.MustParseDecimal("1.5")) {
+		t.Errorf("unexpected trailing distances %s and %s", order.TrailingLimitDistAbs, order.TrailingLimitDistRel)
+	}
 }
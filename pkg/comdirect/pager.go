@@ -0,0 +1,138 @@
+package comdirect
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// DefaultPageSize is the number of values the All* iterators request per page,
+// unless a PagingCountQueryKey option is given.
+const DefaultPageSize = 50
+
+// pageFetcher retrieves a single page of values for the given options.
+type pageFetcher[T any] func(ctx context.Context, options Options) ([]T, Paging, error)
+
+// paginate returns an iterator over all values of a paginated endpoint. It requests one page
+// after another by advancing the paging-first index, starting at the PagingFirstQueryKey option
+// if given. Every page is subject to the rate limiter of the Client. The iterator stops if the
+// context is done and yields the error of a failed request as its last element.
+func paginate[T any](ctx context.Context, options []Options, fetch pageFetcher[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		values := Values{}
+		for _, o := range options {
+			for k, v := range o.Values() {
+				values[k] = v
+			}
+		}
+		first, _ := strconv.Atoi(values[PagingFirstQueryKey])
+		count, err := strconv.Atoi(values[PagingCountQueryKey])
+		if err != nil || count <= 0 {
+			count = DefaultPageSize
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			pageOptions := EmptyOptions()
+			pageOptions.WithValues(values)
+			pageOptions.Add(PagingFirstQueryKey, strconv.Itoa(first))
+			pageOptions.Add(PagingCountQueryKey, strconv.Itoa(count))
+
+			page, paging, err := fetch(ctx, pageOptions)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, v := range page {
+				if !yield(v, nil) {
+					return
+				}
+			}
+			first += len(page)
+			if len(page) == 0 || first >= paging.Matches {
+				return
+			}
+		}
+	}
+}
+
+// AllTransactions returns an iterator over all transactions of an account, fetched page by page.
+func (c *Client) AllTransactions(ctx context.Context, accountID string, options ...Options) iter.Seq2[AccountTransaction, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]AccountTransaction, Paging, error) {
+		page, err := c.Transactions(ctx, accountID, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllDocuments returns an iterator over all documents of the postbox, fetched page by page.
+func (c *Client) AllDocuments(ctx context.Context, options ...Options) iter.Seq2[Document, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]Document, Paging, error) {
+		page, err := c.Documents(ctx, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllDepots returns an iterator over all depots, fetched page by page.
+func (c *Client) AllDepots(ctx context.Context, options ...Options) iter.Seq2[Depot, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]Depot, Paging, error) {
+		page, err := c.Depots(ctx, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllDepotPositions returns an iterator over all positions of a depot, fetched page by page.
+func (c *Client) AllDepotPositions(ctx context.Context, depotID string, options ...Options) iter.Seq2[DepotPosition, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]DepotPosition, Paging, error) {
+		page, err := c.DepotPositions(ctx, depotID, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllDepotTransactions returns an iterator over all transactions of a depot, fetched page by page.
+func (c *Client) AllDepotTransactions(ctx context.Context, depotID string, options ...Options) iter.Seq2[DepotTransaction, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]DepotTransaction, Paging, error) {
+		page, err := c.DepotTransactions(ctx, depotID, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllOrders returns an iterator over all orders of a depot, fetched page by page.
+func (c *Client) AllOrders(ctx context.Context, depotID string, options ...Options) iter.Seq2[Order, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]Order, Paging, error) {
+		page, err := c.Orders(ctx, depotID, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
+
+// AllReports returns an iterator over the balance reports of all products, fetched page by page.
+func (c *Client) AllReports(ctx context.Context, options ...Options) iter.Seq2[Report, error] {
+	return paginate(ctx, options, func(ctx context.Context, o Options) ([]Report, Paging, error) {
+		page, err := c.Reports(ctx, o)
+		if err != nil {
+			return nil, Paging{}, err
+		}
+		return page.Values, page.Paging, nil
+	})
+}
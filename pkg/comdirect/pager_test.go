@@ -0,0 +1,173 @@
+package comdirect_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestClient_AllTransactions(t *testing.T) {
+	client, requests := newCountingTestClient(t, "/transactions")
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	options := comdirect.EmptyOptions()
+	options.Add(comdirect.PagingCountQueryKey, "7")
+	var references []string
+	for transaction, err := range client.AllTransactions(ctx, comdirecttest.AccountID, options) {
+		if err != nil {
+			t.Fatalf("failed to iterate transactions: %s", err)
+		}
+		references = append(references, transaction.Reference)
+	}
+	if len(references) != 30 {
+		t.Errorf("expected 30 transactions, got %d", len(references))
+	}
+	seen := map[string]bool{}
+	for _, r := range references {
+		if seen[r] {
+			t.Errorf("transaction %s returned twice", r)
+		}
+		seen[r] = true
+	}
+	if n := requests.Load(); n != 5 {
+		t.Errorf("expected 5 page requests, got %d", n)
+	}
+}
+
+func TestClient_AllTransactions_Break(t *testing.T) {
+	client, requests := newCountingTestClient(t, "/transactions")
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	options := comdirect.EmptyOptions()
+	options.Add(comdirect.PagingCountQueryKey, "10")
+	count := 0
+	for _, err := range client.AllTransactions(ctx, comdirecttest.AccountID, options) {
+		if err != nil {
+			t.Fatalf("failed to iterate transactions: %s", err)
+		}
+		count++
+		if count == 12 {
+			break
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 page requests, got %d", n)
+	}
+}
+
+func TestClient_AllTransactions_Error(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	var errs []error
+	for _, err := range client.AllTransactions(ctx, "unknown") {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], comdirect.ErrNotFound) {
+		t.Errorf("expected a single ErrNotFound, got: %v", errs)
+	}
+}
+
+func TestClient_AllTransactions_Canceled(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := comdirect.EmptyOptions()
+	options.Add(comdirect.PagingCountQueryKey, "5")
+	var err error
+	count := 0
+	for _, err = range client.AllTransactions(ctx, comdirecttest.AccountID, options) {
+		if err != nil {
+			break
+		}
+		count++
+		if count == 5 {
+			cancel()
+		}
+	}
+	if !errors.Is(err, context.Canceled) || count != 5 {
+		t.Errorf("expected iteration to stop with context.Canceled after 5 transactions, got %d: %v", count, err)
+	}
+}
+
+func TestClient_AllDepotTransactions(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	options := comdirect.EmptyOptions()
+	options.Add(comdirect.PagingCountQueryKey, "1")
+	count := 0
+	for _, err := range client.AllDepotTransactions(ctx, comdirecttest.DepotID, options) {
+		if err != nil {
+			t.Fatalf("failed to iterate depot transactions: %s", err)
+		}
+		count++
+	}
+	transactions, err := client.DepotTransactions(ctx, comdirecttest.DepotID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 || count != transactions.Paging.Matches {
+		t.Errorf("expected %d depot transactions, got %d", transactions.Paging.Matches, count)
+	}
+}
+
+func TestClient_AllDocuments(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	count := 0
+	for document, err := range client.AllDocuments(ctx) {
+		if err != nil {
+			t.Fatalf("failed to iterate documents: %s", err)
+		}
+		if document.DocumentID != comdirecttest.DocumentID {
+			t.Errorf("unexpected document: %+v", document)
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 document, got %d", count)
+	}
+}
+
+// newCountingTestClient returns an authenticated Client that counts the requests to paths with the given suffix.
+func newCountingTestClient(t *testing.T, suffix string) (*comdirect.Client, *atomic.Int32) {
+	t.Helper()
+	server := comdirecttest.NewServer()
+	t.Cleanup(server.Close)
+	requests := &atomic.Int32{}
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, suffix) {
+			requests.Add(1)
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	opts := append(server.ClientOptions(), comdirect.WithTransport(transport))
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	return client, requests
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
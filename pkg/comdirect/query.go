@@ -0,0 +1,161 @@
+package comdirect
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BookingStatus is the booking status of an AccountTransaction.
+type BookingStatus string
+
+const (
+	BookingStatusBooked    BookingStatus = "BOOKED"
+	BookingStatusNotBooked BookingStatus = "NOTBOOKED"
+	BookingStatusBoth      BookingStatus = "BOTH"
+)
+
+// TransactionDirection selects incoming or outgoing transactions.
+type TransactionDirection string
+
+const (
+	TransactionDirectionCredit TransactionDirection = "CREDIT"
+	TransactionDirectionDebit  TransactionDirection = "DEBIT"
+	TransactionDirectionBoth   TransactionDirection = "CREDIT_AND_DEBIT"
+)
+
+// TransactionAttrAccount is the attribute of an AccountTransaction holding the account.
+// Use it with TransactionQuery.WithAttr and TransactionQuery.WithoutAttr.
+const TransactionAttrAccount = "account"
+
+// TransactionQuery builds the Options of Client.Transactions and Client.AllTransactions,
+// so that transactions are filtered by comdirect instead of the client.
+//
+//	query := comdirect.NewTransactionQuery().
+//		BookingStatus(comdirect.BookingStatusBooked).
+//		From(comdirect.NewDate(2024, time.January, 1))
+//	transactions, err := client.Transactions(ctx, accountID, query.Options())
+type TransactionQuery struct {
+	options Options
+}
+
+// NewTransactionQuery creates an empty TransactionQuery that matches all transactions.
+func NewTransactionQuery() *TransactionQuery {
+	return &TransactionQuery{options: EmptyOptions()}
+}
+
+// BookingStatus restricts the query to transactions with the given BookingStatus.
+func (q *TransactionQuery) BookingStatus(status BookingStatus) *TransactionQuery {
+	return q.set(BookingStatusQueryKey, string(status))
+}
+
+// From restricts the query to transactions booked on or after date.
+func (q *TransactionQuery) From(date Date) *TransactionQuery {
+	return q.set(MinBookingDateQueryKey, date.String())
+}
+
+// To restricts the query to transactions booked on or before date.
+func (q *TransactionQuery) To(date Date) *TransactionQuery {
+	return q.set(MaxBookingDateQueryKey, date.String())
+}
+
+// Direction restricts the query to incoming or outgoing transactions.
+func (q *TransactionQuery) Direction(direction TransactionDirection) *TransactionQuery {
+	return q.set(TransactionDirectionQueryKey, string(direction))
+}
+
+// WithAttr requests the given optional attributes, e.g. TransactionAttrAccount.
+func (q *TransactionQuery) WithAttr(attrs ...string) *TransactionQuery {
+	return q.set(WithAttrQueryKey, strings.Join(attrs, ","))
+}
+
+// WithoutAttr omits the given attributes from the response, e.g. TransactionAttrAccount.
+func (q *TransactionQuery) WithoutAttr(attrs ...string) *TransactionQuery {
+	return q.set(WithoutAttrQueryKey, strings.Join(attrs, ","))
+}
+
+// Paging sets the index of the first transaction and the number of transactions of a page.
+func (q *TransactionQuery) Paging(first int, count int) *TransactionQuery {
+	q.set(PagingFirstQueryKey, strconv.Itoa(first))
+	return q.set(PagingCountQueryKey, strconv.Itoa(count))
+}
+
+// Options returns a copy of the Options of the query.
+func (q *TransactionQuery) Options() Options {
+	options := EmptyOptions()
+	options.WithValues(q.options.Values())
+	return options
+}
+
+// set adds the option, or removes it if value is empty.
+func (q *TransactionQuery) set(key string, value string) *TransactionQuery {
+	if value == "" {
+		delete(q.options.values, key)
+		return q
+	}
+	q.options.Add(key, value)
+	return q
+}
+
+// OrderQuery builds the Options of Client.Orders and Client.AllOrders, so that orders are filtered
+// by comdirect instead of the client.
+//
+//	query := comdirect.NewOrderQuery().
+//		Status(comdirect.OrderStatusOpen).
+//		Side(comdirect.OrderSideBuy)
+//	orders, err := client.Orders(ctx, depotID, query.Options())
+type OrderQuery struct {
+	options Options
+}
+
+// NewOrderQuery creates an empty OrderQuery that matches all orders.
+func NewOrderQuery() *OrderQuery {
+	return &OrderQuery{options: EmptyOptions()}
+}
+
+// Status restricts the query to orders with the given OrderStatus.
+func (q *OrderQuery) Status(status OrderStatus) *OrderQuery {
+	return q.set(OrderStatusQueryKey, string(status))
+}
+
+// Side restricts the query to buy or sell orders.
+func (q *OrderQuery) Side(side OrderSide) *OrderQuery {
+	return q.set(SideQueryKey, string(side))
+}
+
+// OrderType restricts the query to orders of the given OrderType.
+func (q *OrderQuery) OrderType(orderType OrderType) *OrderQuery {
+	return q.set(OrderTypeQueryKey, string(orderType))
+}
+
+// Venue restricts the query to orders at the venue specified by its ID.
+func (q *OrderQuery) Venue(venueID string) *OrderQuery {
+	return q.set(VenueIDQueryKey, venueID)
+}
+
+// Instrument restricts the query to orders of the instrument specified by its ID.
+func (q *OrderQuery) Instrument(instrumentID string) *OrderQuery {
+	return q.set(InstrumentIDQueryKey, instrumentID)
+}
+
+// Paging sets the index of the first order and the number of orders of a page.
+func (q *OrderQuery) Paging(first int, count int) *OrderQuery {
+	q.set(PagingFirstQueryKey, strconv.Itoa(first))
+	return q.set(PagingCountQueryKey, strconv.Itoa(count))
+}
+
+// Options returns a copy of the Options of the query.
+func (q *OrderQuery) Options() Options {
+	options := EmptyOptions()
+	options.WithValues(q.options.Values())
+	return options
+}
+
+// set adds the option, or removes it if value is empty.
+func (q *OrderQuery) set(key string, value string) *OrderQuery {
+	if value == "" {
+		delete(q.options.values, key)
+		return q
+	}
+	q.options.Add(key, value)
+	return q
+}
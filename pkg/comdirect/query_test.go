@@ -0,0 +1,89 @@
+package comdirect_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestTransactionQuery_Options(t *testing.T) {
+	query := comdirect.NewTransactionQuery().
+		BookingStatus(comdirect.BookingStatusBooked).
+		From(comdirect.NewDate(2024, time.January, 1)).
+		To(comdirect.NewDate(2024, time.March, 31)).
+		Direction(comdirect.TransactionDirectionDebit).
+		WithAttr(comdirect.TransactionAttrAccount).
+		Paging(10, 50)
+
+	options := query.Options()
+	expected := comdirect.Values{
+		comdirect.BookingStatusQueryKey:        "BOOKED",
+		comdirect.MinBookingDateQueryKey:       "2024-01-01",
+		comdirect.MaxBookingDateQueryKey:       "2024-03-31",
+		comdirect.TransactionDirectionQueryKey: "DEBIT",
+		comdirect.WithAttrQueryKey:             "account",
+		comdirect.PagingFirstQueryKey:          "10",
+		comdirect.PagingCountQueryKey:          "50",
+	}
+	if len(options.Values()) != len(expected) {
+		t.Fatalf("unexpected options: %v", options.Values())
+	}
+	for k, v := range expected {
+		if options.Values()[k] != v {
+			t.Errorf("expected %s=%s, got %q", k, v, options.Values()[k])
+		}
+	}
+
+	options.Add(comdirect.PagingFirstQueryKey, "0")
+	query.From(comdirect.Date{})
+	options = query.Options()
+	if options.Values()[comdirect.PagingFirstQueryKey] != "10" {
+		t.Error("expected Options to return a copy")
+	}
+	if _, ok := options.Values()[comdirect.MinBookingDateQueryKey]; ok {
+		t.Error("expected zero date to remove the filter")
+	}
+}
+
+func TestClient_Transactions_Query(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	query := comdirect.NewTransactionQuery().
+		From(comdirect.NewDate(2024, time.March, 1)).
+		To(comdirect.NewDate(2024, time.March, 28))
+	transactions, err := client.Transactions(ctx, comdirecttest.AccountID, query.Options())
+	if err != nil {
+		t.Fatalf("failed to exchange account transactions %s", err)
+	}
+	if transactions.Paging.Matches != 10 {
+		t.Errorf("expected 10 transactions in March, got %d", transactions.Paging.Matches)
+	}
+
+	query.Direction(comdirect.TransactionDirectionDebit)
+	count := 0
+	for transaction, err := range client.AllTransactions(ctx, comdirecttest.AccountID, query.Options()) {
+		if err != nil {
+			t.Fatalf("failed to exchange account transactions %s", err)
+		}
+		if transaction.Amount.Value.Sign() >= 0 {
+			t.Errorf("expected only debit transactions, got: %+v", transaction)
+		}
+		count++
+	}
+	if count != 7 {
+		t.Errorf("expected 7 debit transactions in March, got %d", count)
+	}
+
+	query = comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusNotBooked)
+	transactions, err = client.Transactions(ctx, comdirecttest.AccountID, query.Options())
+	if err != nil {
+		t.Fatalf("failed to exchange account transactions %s", err)
+	}
+	if len(transactions.Values) != 1 || transactions.Values[0].BookingStatus != "NOTBOOKED" {
+		t.Errorf("expected the pending transaction, got: %+v", transactions.Values)
+	}
+}
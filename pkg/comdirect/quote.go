@@ -1,21 +1,186 @@
 package comdirect
 
-func (c *Client) CreateQuoteTicket() {
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
+// ErrQuoteExpired is returned if a Quote is executed after its expiry.
+var ErrQuoteExpired = errors.New("comdirect: quote expired")
+
+// QuoteTicket authorizes the quote orders of a depot with a single TAN. A ticket is created with
+// CreateQuoteTicket and activated with the TAN by UpdateQuoteTicket.
+type QuoteTicket struct {
+	QuoteTicketID string `json:"quoteTicketId"`
+	DepotID       string `json:"depotId"`
+	Status        string `json:"status"`
+}
+
+// QuoteRequest requests a binding quote for buying or selling an instrument at a venue.
+type QuoteRequest struct {
+	QuoteTicketID string      `json:"quoteTicketId"`
+	InstrumentID  string      `json:"instrumentId"`
+	VenueID       string      `json:"venueId"`
+	Side          OrderSide   `json:"side"`
+	Quantity      AmountValue `json:"quantity"`
+}
+
+// Quote is a binding price of a venue for a QuoteRequest. It can only be executed until its expiry.
+type Quote struct {
+	QuoteID         string      `json:"quoteId"`
+	QuoteTicketID   string      `json:"quoteTicketId"`
+	InstrumentID    string      `json:"instrumentId"`
+	VenueID         string      `json:"venueId"`
+	Side            OrderSide   `json:"side"`
+	Quantity        AmountValue `json:"quantity"`
+	Price           AmountValue `json:"price"`
+	QuoteTimestamp  Timestamp   `json:"quoteTimestamp"`
+	ExpiryTimestamp Timestamp   `json:"expiryTimestamp"`
+}
+
+// Expired reports whether the quote cannot be executed anymore.
+func (q *Quote) Expired() bool {
+	return !time.Now().Before(q.ExpiryTimestamp.Time())
+}
+
+// TimeLeft returns the time until the quote expires, or zero if it is expired.
+func (q *Quote) TimeLeft() time.Duration {
+	return max(time.Until(q.ExpiryTimestamp.Time()), 0)
+}
+
+// OrderRequest returns the quote order of depotID that executes the quote at its price.
+func (q *Quote) OrderRequest(depotID string) *OrderRequest {
+	price := q.Price
+	return &OrderRequest{
+		DepotID:       depotID,
+		Side:          q.Side,
+		InstrumentID:  q.InstrumentID,
+		OrderType:     OrderTypeQuote,
+		Quantity:      q.Quantity,
+		VenueID:       q.VenueID,
+		Limit:         &price,
+		ValidityType:  ValidityTypeGoodForDay,
+		QuoteTicketID: q.QuoteTicketID,
+		QuoteID:       q.QuoteID,
+	}
+}
+
+// CreateQuoteTicket creates a quote ticket for the depot specified by its ID and activates it with a TAN.
+// The TAN challenge is solved with the TANHandler of the AuthOptions, push TAN challenges are
+// awaited until they are approved in the photoTAN app. The ticket is created once, a preferred TAN type
+// only requests a new challenge for it.
+func (c *Client) CreateQuoteTicket(ctx context.Context, depotID string) (*QuoteTicket, error) {
+	var ticket *QuoteTicket
+	challenge, tan, err := c.authorize(ctx, func(tanType TANType) (*TANChallenge, error) {
+		if ticket != nil {
+			return c.validateQuoteTicket(ctx, ticket.QuoteTicketID, tanType)
+		}
+		var challenge *TANChallenge
+		var err error
+		ticket, challenge, err = c.newQuoteTicket(ctx, depotID, tanType)
+		return challenge, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateQuoteTicket(ctx, ticket.QuoteTicketID, challenge, tan)
+}
+
+// NewQuoteTicket creates an inactive quote ticket for the depot specified by its ID and returns the TAN
+// challenge required to activate it with UpdateQuoteTicket.
+func (c *Client) NewQuoteTicket(ctx context.Context, depotID string) (*QuoteTicket, *TANChallenge, error) {
+	return c.newQuoteTicket(ctx, depotID, "")
 }
 
-func (c *Client) UpdateQuoteTicket(quoteTicketID string) {
+func (c *Client) newQuoteTicket(ctx context.Context, depotID string, tanType TANType) (*QuoteTicket, *TANChallenge, error) {
+	ticket := &QuoteTicket{}
+	challenge, err := c.exchangeTANChallenge(ctx, http.MethodPost, "/brokerage/v3/quoteticket", &QuoteTicket{DepotID: depotID}, ticket, tanType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ticket, challenge, nil
+}
 
+// validateQuoteTicket requests a new TAN challenge of tanType for the inactive quote ticket specified by its ID.
+func (c *Client) validateQuoteTicket(ctx context.Context, quoteTicketID string, tanType TANType) (*TANChallenge, error) {
+	path := fmt.Sprintf("/brokerage/v3/quoteticket/%s/validation", quoteTicketID)
+	return c.exchangeTANChallenge(ctx, http.MethodPatch, path, struct{}{}, nil, tanType)
 }
 
-func (c *Client) CreateQuoteRequest() {
+// UpdateQuoteTicket activates the quote ticket specified by its ID with the TAN of the challenge.
+// For TANTypePush challenges the TAN is empty and the challenge must be approved in the photoTAN app first.
+func (c *Client) UpdateQuoteTicket(ctx context.Context, quoteTicketID string, challenge *TANChallenge, tan string) (*QuoteTicket, error) {
+	if challenge == nil {
+		return nil, errors.New("TAN challenge cannot be nil")
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPatch, fmt.Sprintf("/brokerage/v3/quoteticket/%s", quoteTicketID), struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	req.Header = onceAuthenticationHeaders(req.Header, challenge, tan)
 
+	ticket := &QuoteTicket{}
+	_, err = c.http.exchange(req, ticket)
+	return ticket, err
 }
 
-func (c *Client) ValidateQuoteOrder() {
+// CreateQuoteRequest requests a binding quote with an active quote ticket.
+func (c *Client) CreateQuoteRequest(ctx context.Context, request *QuoteRequest) (*Quote, error) {
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/quotes", request)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{}
+	_, err = c.http.exchange(req, quote)
+	return quote, err
+}
 
+// ValidateQuoteOrder validates a quote order, see Quote.OrderRequest. Quote orders are authorized by their
+// quote ticket and do not require a TAN.
+func (c *Client) ValidateQuoteOrder(ctx context.Context, order *OrderRequest) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/orders/validation", order)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
 }
 
-func (c *Client) CreateQuoteOrder() {
+// CreateQuoteOrder places a quote order validated by ValidateQuoteOrder.
+func (c *Client) CreateQuoteOrder(ctx context.Context, order *OrderRequest) (*Order, error) {
+	req, err := c.newJSONRequest(ctx, http.MethodPost, "/brokerage/v3/orders", order)
+	if err != nil {
+		return nil, err
+	}
+
+	created := &Order{}
+	_, err = c.http.exchange(req, created)
+	return created, err
+}
 
+// ExecuteQuote validates and places the quote order of depotID for a quote. It returns ErrQuoteExpired
+// without placing the order if the quote is expired before the validation or before the order is placed.
+func (c *Client) ExecuteQuote(ctx context.Context, depotID string, quote *Quote) (*Order, error) {
+	if quote.Expired() {
+		return nil, fmt.Errorf("%w at %s", ErrQuoteExpired, quote.ExpiryTimestamp)
+	}
+	order := quote.OrderRequest(depotID)
+	if err := c.ValidateQuoteOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	// the validation may have used up the rest of the quote lifetime
+	if quote.Expired() {
+		return nil, fmt.Errorf("%w at %s", ErrQuoteExpired, quote.ExpiryTimestamp)
+	}
+	return c.CreateQuoteOrder(ctx, order)
 }
@@ -1 +1,172 @@
-package comdirect
+package comdirect_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func requestTestQuote(t *testing.T, client *comdirect.Client, side comdirect.OrderSide) *comdirect.Quote {
+	t.Helper()
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	ticket, err := client.CreateQuoteTicket(ctx, comdirecttest.DepotID)
+	if err != nil {
+		t.Fatalf("failed to create quote ticket: %s", err)
+	}
+	if ticket.QuoteTicketID == "" || ticket.Status != "ACTIVE" {
+		t.Fatalf("expected active quote ticket, got: %+v", ticket)
+	}
+	quote, err := client.CreateQuoteRequest(ctx, &comdirect.QuoteRequest{
+		QuoteTicketID: ticket.QuoteTicketID,
+		InstrumentID:  comdirecttest.InstrumentID,
+		VenueID:       comdirecttest.VenueID,
+		Side:          side,
+		Quantity:      comdirect.AmountValue{Value: comdirect.MustParseDecimal("10"), Unit: "XXX"},
+	})
+	if err != nil {
+		t.Fatalf("failed to request quote: %s", err)
+	}
+	return quote
+}
+
+func TestClient_ExecuteQuote(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	quote := requestTestQuote(t, client, comdirect.OrderSideBuy)
+	if quote.Price.String() != "170.67 EUR" || quote.Expired() || quote.TimeLeft() <= 0 {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+	order, err := client.ExecuteQuote(ctx, comdirecttest.DepotID, quote)
+	if err != nil {
+		t.Fatalf("failed to execute quote: %s", err)
+	}
+	if order.OrderStatus != "EXECUTED" || order.QuoteID != quote.QuoteID || len(order.Executions) != 1 ||
+		!order.Executions[0].ExecutionPrice.Value.Equal(quote.Price.Value) {
+		t.Errorf("unexpected quote order: %+v", order)
+	}
+	if _, err = client.ExecuteQuote(ctx, comdirecttest.DepotID, quote); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected a quote to be executed only once, got: %v", err)
+	}
+}
+
+func TestClient_ExecuteQuote_Expired(t *testing.T) {
+	client, server := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	server.SetQuoteLifetime(-time.Second)
+	quote := requestTestQuote(t, client, comdirect.OrderSideSell)
+	if !quote.Expired() || quote.TimeLeft() != 0 {
+		t.Errorf("expected expired quote, got: %+v", quote)
+	}
+	if _, err := client.ExecuteQuote(ctx, comdirecttest.DepotID, quote); !errors.Is(err, comdirect.ErrQuoteExpired) {
+		t.Errorf("expected ErrQuoteExpired, got: %v", err)
+	}
+	// the venue rejects expired quotes as well
+	if err := client.ValidateQuoteOrder(ctx, quote.OrderRequest(comdirecttest.DepotID)); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected expired quote to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_ExecuteQuote_ExpiredDuringValidation(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	// timestamps have a precision of seconds, so the quote expires within the next two seconds
+	server.SetQuoteLifetime(2 * time.Second)
+	var created atomic.Bool
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := http.DefaultTransport.RoundTrip(req)
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/orders/validation"):
+			// a slow validation lets the quote expire
+			time.Sleep(2 * time.Second)
+		case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/v3/orders"):
+			created.Store(true)
+		}
+		return res, err
+	})
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), append(server.ClientOptions(), comdirect.WithTransport(transport))...)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+
+	quote := requestTestQuote(t, client, comdirect.OrderSideBuy)
+	if quote.Expired() {
+		t.Fatalf("expected quote to be valid before the validation, got: %+v", quote)
+	}
+	if _, err := client.ExecuteQuote(ctx, comdirecttest.DepotID, quote); !errors.Is(err, comdirect.ErrQuoteExpired) {
+		t.Errorf("expected ErrQuoteExpired, got: %v", err)
+	}
+	if created.Load() {
+		t.Error("expected no order to be placed for an expired quote")
+	}
+}
+
+func TestClient_CreateQuoteRequest_InactiveTicket(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	ticket, challenge, err := client.NewQuoteTicket(ctx, comdirecttest.DepotID)
+	if err != nil {
+		t.Fatalf("failed to create quote ticket: %s", err)
+	}
+	if challenge.ID == "" || ticket.Status != "PENDING" {
+		t.Fatalf("expected pending quote ticket with TAN challenge, got: %+v %+v", ticket, challenge)
+	}
+	_, err = client.CreateQuoteRequest(ctx, &comdirect.QuoteRequest{
+		QuoteTicketID: ticket.QuoteTicketID,
+		InstrumentID:  comdirecttest.InstrumentID,
+		VenueID:       comdirecttest.VenueID,
+		Side:          "BUY",
+		Quantity:      comdirect.AmountValue{Value: comdirect.MustParseDecimal("1"), Unit: "XXX"},
+	})
+	if !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected quote request with inactive ticket to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_CreateQuoteTicket_PhotoTAN(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	handler := &testTANHandler{preferred: comdirect.TANTypePhoto, tan: comdirecttest.TAN}
+	options := server.AuthOptions()
+	options.TANHandler = handler
+	created := &atomic.Int32{}
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/quoteticket") {
+			created.Add(1)
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := comdirect.NewWithAuthOptions(options, append(server.ClientOptions(), comdirect.WithTransport(transport))...)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+
+	handler.challenge = nil
+	ticket, err := client.CreateQuoteTicket(ctx, comdirecttest.DepotID)
+	if err != nil {
+		t.Fatalf("failed to create quote ticket: %s", err)
+	}
+	if ticket.Status != "ACTIVE" || handler.challenge == nil || handler.challenge.Type != comdirect.TANTypePhoto {
+		t.Errorf("expected quote ticket activated with photoTAN, got: %+v %+v", ticket, handler.challenge)
+	}
+	if n := created.Load(); n != 1 {
+		t.Errorf("expected a single quote ticket to be created, got: %d", n)
+	}
+}
@@ -2,7 +2,6 @@ package comdirect
 
 import (
 	"context"
-	"errors"
 	"net/http"
 )
 
@@ -23,7 +22,7 @@ type ReportBalance struct {
 	AvailableCashAmountEUR AmountValue `json:"availableCashAmountEUR"`
 	Depot                  Depot       `json:"depot"`
 	DepotID                string      `json:"depotId"`
-	DateLastUpdate         string      `json:"dateLastUpdate"`
+	DateLastUpdate         Timestamp   `json:"dateLastUpdate"`
 	PrevDayValue           AmountValue `json:"prevDayValue"`
 }
 
@@ -40,17 +39,18 @@ type Reports struct {
 
 // Reports returns the balance for all available accounts.
 func (c *Client) Reports(ctx context.Context, options ...Options) (*Reports, error) {
-	if c.authentication == nil || c.authentication.accessToken.AccessToken == "" || c.authentication.IsExpired() {
-		return nil, errors.New("authentication is expired or not initialized")
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
 	}
-	info, err := requestInfoJSON(c.authentication.sessionID)
+	info, err := requestInfoJSON(auth.sessionID)
 	if err != nil {
 		return nil, err
 	}
 	req := &http.Request{
 		Method: http.MethodGet,
-		URL:    apiURL("/reports/participants/user/v1/allbalances"),
-		Header: defaultHeaders(c.authentication.accessToken.AccessToken, string(info)),
+		URL:    c.http.apiURL("/reports/participants/user/v1/allbalances"),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
 	}
 	req = req.WithContext(ctx)
 
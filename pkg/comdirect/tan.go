@@ -0,0 +1,268 @@
+package comdirect
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TANType identifies a TAN procedure supported by comdirect.
+type TANType string
+
+const (
+	// TANTypePush is approved in the comdirect photoTAN app without entering a TAN.
+	TANTypePush TANType = "P_TAN_PUSH"
+	// TANTypePhoto requires scanning a photoTAN image and entering the displayed TAN.
+	TANTypePhoto TANType = "P_TAN"
+	// TANTypeMobile requires entering a TAN sent by SMS.
+	TANTypeMobile TANType = "M_TAN"
+)
+
+// TANChallenge is a TAN challenge returned in the x-once-authentication-info header.
+type TANChallenge struct {
+	// ID identifies the challenge and is sent back together with the TAN.
+	ID string
+	// Type is the TAN procedure of the challenge.
+	Type TANType
+	// AvailableTypes lists all TAN procedures available for the user.
+	AvailableTypes []TANType
+	// Challenge is the raw challenge, e.g. the masked mobile phone number for TANTypeMobile.
+	Challenge string
+	// Image is the decoded PNG image of a TANTypePhoto challenge.
+	Image []byte
+
+	// statusHref is the link polled for the approval of a TANTypePush challenge.
+	statusHref string
+}
+
+// TANHandler solves TAN challenges that require user interaction.
+type TANHandler interface {
+	// SelectTANType returns the preferred TAN type out of the available ones.
+	// An empty TANType keeps the TAN type chosen by comdirect.
+	SelectTANType(available []TANType) TANType
+	// HandleTAN presents the challenge to the user and returns the entered TAN.
+	// It is not called for TANTypePush challenges, which are approved in the photoTAN app.
+	HandleTAN(ctx context.Context, challenge *TANChallenge) (string, error)
+}
+
+// newTANChallenge creates a TANChallenge from the x-once-authentication-info header.
+func newTANChallenge(info onceAuthenticationInfo) (*TANChallenge, error) {
+	challenge := &TANChallenge{
+		ID:             info.Id,
+		Type:           TANType(info.Typ),
+		AvailableTypes: tanTypes(info.AvailableTypes),
+		Challenge:      info.Challenge,
+		statusHref:     info.Link.Href,
+	}
+	if challenge.Type == TANTypePhoto && info.Challenge != "" {
+		encoded := info.Challenge
+		if i := strings.Index(encoded, ","); strings.HasPrefix(encoded, "data:") && i >= 0 {
+			encoded = encoded[i+1:]
+		}
+		image, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode photoTAN image: %w", err)
+		}
+		challenge.Image = image
+	}
+	return challenge, nil
+}
+
+// parseTANChallenge creates a TANChallenge from the x-once-authentication-info header of a response.
+func parseTANChallenge(res *http.Response) (*TANChallenge, error) {
+	header := res.Header.Get(OnceAuthenticationInfoHeaderKey)
+	if header == "" {
+		return nil, errors.New("response contains no TAN challenge")
+	}
+	var info onceAuthenticationInfo
+	if err := json.Unmarshal([]byte(header), &info); err != nil {
+		return nil, fmt.Errorf("failed to decode TAN challenge: %w", err)
+	}
+	return newTANChallenge(info)
+}
+
+// onceAuthenticationHeaders adds the headers that answer a TAN challenge to header.
+// The TAN is omitted for TANTypePush challenges, which are approved in the photoTAN app.
+func onceAuthenticationHeaders(header http.Header, challenge *TANChallenge, tan string) http.Header {
+	// encoding a struct of a single string cannot fail
+	info, _ := json.Marshal(struct {
+		ID string `json:"id"`
+	}{challenge.ID})
+	header.Set(OnceAuthenticationInfoHeaderKey, string(info))
+	if tan != "" {
+		header.Set(OnceAuthenticationHeaderKey, tan)
+	}
+	return header
+}
+
+// tanHandler returns the TANHandler of the AuthOptions of the Client, if any.
+func (c *Client) tanHandler() TANHandler {
+	if c.authenticator == nil || c.authenticator.authOptions == nil {
+		return nil
+	}
+	return c.authenticator.authOptions.TANHandler
+}
+
+// preferredTANType returns the TAN type preferred by the TANHandler, if it differs from the one of challenge.
+func (c *Client) preferredTANType(challenge *TANChallenge) (TANType, error) {
+	handler := c.tanHandler()
+	if handler == nil {
+		return "", nil
+	}
+	preferred := handler.SelectTANType(challenge.AvailableTypes)
+	if preferred == "" || preferred == challenge.Type {
+		return "", nil
+	}
+	if !containsTANType(challenge.AvailableTypes, preferred) {
+		return "", fmt.Errorf("TAN type %s is not available; available types are %v", preferred, challenge.AvailableTypes)
+	}
+	return preferred, nil
+}
+
+// authorize validates a transaction with validate and solves the resulting TAN challenge. If the
+// TANHandler prefers another TAN type, the transaction is validated again with that type.
+func (c *Client) authorize(ctx context.Context, validate func(tanType TANType) (*TANChallenge, error)) (*TANChallenge, string, error) {
+	challenge, err := validate("")
+	if err != nil {
+		return nil, "", err
+	}
+	preferred, err := c.preferredTANType(challenge)
+	if err != nil {
+		return nil, "", err
+	}
+	if preferred != "" {
+		if challenge, err = validate(preferred); err != nil {
+			return nil, "", err
+		}
+	}
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	tan, err := c.solveTANChallenge(ctx, auth, challenge)
+	if err != nil {
+		return nil, "", err
+	}
+	return challenge, tan, nil
+}
+
+// requestTANChallenge posts body to the validation endpoint of a transaction and returns the TAN challenge
+// of the response. A non-empty tanType requests a challenge of that TAN type.
+func (c *Client) requestTANChallenge(ctx context.Context, path string, body interface{}, tanType TANType) (*TANChallenge, error) {
+	return c.exchangeTANChallenge(ctx, http.MethodPost, path, body, nil, tanType)
+}
+
+// exchangeTANChallenge is like requestTANChallenge, but sends body with method and decodes the response body
+// into target unless it is nil.
+func (c *Client) exchangeTANChallenge(ctx context.Context, method, path string, body interface{}, target interface{}, tanType TANType) (*TANChallenge, error) {
+	req, err := c.newJSONRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	setTANType(req, tanType)
+
+	var res *http.Response
+	if target == nil {
+		if res, err = c.http.do(req); err == nil {
+			_ = res.Body.Close()
+		}
+	} else {
+		res, err = c.http.exchange(req, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseTANChallenge(res)
+}
+
+// setTANType requests a TAN challenge of tanType with the x-once-authentication-info header of req.
+// An empty tanType keeps the TAN type chosen by comdirect.
+func setTANType(req *http.Request, tanType TANType) {
+	if tanType == "" {
+		return
+	}
+	// encoding a struct of a single string cannot fail
+	info, _ := json.Marshal(struct {
+		Typ TANType `json:"typ"`
+	}{tanType})
+	req.Header.Set(OnceAuthenticationInfoHeaderKey, string(info))
+}
+
+// solveTANChallenge waits for the approval of a TANTypePush challenge or asks the TANHandler
+// of the AuthOptions for the TAN of other challenges.
+func (c *Client) solveTANChallenge(ctx context.Context, auth *Authentication, challenge *TANChallenge) (string, error) {
+	if challenge.Type == TANTypePush {
+		info, err := requestInfoJSON(auth.sessionID)
+		if err != nil {
+			return "", err
+		}
+		header := defaultHeaders(auth.accessToken.AccessToken, string(info))
+		return "", c.http.awaitApproval(ctx, challenge.statusHref, header, c.pollInterval)
+	}
+	handler := c.tanHandler()
+	if handler == nil {
+		return "", fmt.Errorf("TAN type %s requires a TANHandler", challenge.Type)
+	}
+	tan, err := handler.HandleTAN(ctx, challenge)
+	if err != nil {
+		return "", err
+	}
+	if tan == "" {
+		return "", errors.New("TAN cannot be empty")
+	}
+	return tan, nil
+}
+
+// awaitApproval polls the status link of a TANTypePush challenge every interval until the challenge
+// is approved in the photoTAN app or the context is done.
+func (h *HTTPClient) awaitApproval(ctx context.Context, href string, header http.Header, interval time.Duration) error {
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    h.comdirectURL(href),
+		Header: header,
+	}
+	req = req.WithContext(ctx)
+
+	for {
+		select {
+		case <-time.After(interval):
+			res, err := h.do(req)
+			if err != nil {
+				return err
+			}
+			var status authStatus
+			err = json.NewDecoder(res.Body).Decode(&status)
+			_ = res.Body.Close()
+			if err != nil {
+				return err
+			}
+			if status.Status == "AUTHENTICATED" {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func tanTypes(types []string) []TANType {
+	result := make([]TANType, 0, len(types))
+	for _, t := range types {
+		result = append(result, TANType(t))
+	}
+	return result
+}
+
+func containsTANType(types []TANType, tanType TANType) bool {
+	for _, t := range types {
+		if t == tanType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package comdirect_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+type testTANHandler struct {
+	preferred comdirect.TANType
+	tan       string
+	challenge *comdirect.TANChallenge
+}
+
+func (h *testTANHandler) SelectTANType(available []comdirect.TANType) comdirect.TANType {
+	return h.preferred
+}
+
+func (h *testTANHandler) HandleTAN(ctx context.Context, challenge *comdirect.TANChallenge) (string, error) {
+	h.challenge = challenge
+	return h.tan, nil
+}
+
+func TestTANHandler_PhotoTAN(t *testing.T) {
+	handler := &testTANHandler{preferred: comdirect.TANTypePhoto, tan: comdirecttest.TAN}
+	authenticate(t, handler)
+
+	if handler.challenge == nil || handler.challenge.Type != comdirect.TANTypePhoto {
+		t.Fatalf("expected photoTAN challenge, got: %+v", handler.challenge)
+	}
+	if _, err := png.Decode(bytes.NewReader(handler.challenge.Image)); err != nil {
+		t.Errorf("expected decoded photoTAN PNG image: %s", err)
+	}
+}
+
+func TestTANHandler_MobileTAN(t *testing.T) {
+	handler := &testTANHandler{preferred: comdirect.TANTypeMobile, tan: comdirecttest.TAN}
+	authenticate(t, handler)
+
+	if handler.challenge == nil || handler.challenge.Challenge == "" || handler.challenge.Image != nil {
+		t.Errorf("expected mobileTAN challenge with phone number, got: %+v", handler.challenge)
+	}
+}
+
+func TestTANHandler_InvalidTAN(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	options := server.AuthOptions()
+	options.TANHandler = &testTANHandler{preferred: comdirect.TANTypePhoto, tan: "000000"}
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	_, err := comdirect.NewAuthenticator(options, server.ClientOptions()...).Authenticate(ctx)
+	if !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected invalid TAN to be rejected, got: %v", err)
+	}
+}
+
+func TestTANHandler_PushTAN(t *testing.T) {
+	handler := &testTANHandler{}
+	authenticate(t, handler)
+
+	if handler.challenge != nil {
+		t.Errorf("did not expect HandleTAN to be called for push TAN: %+v", handler.challenge)
+	}
+}
+
+func authenticate(t *testing.T, handler comdirect.TANHandler) {
+	t.Helper()
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	options := server.AuthOptions()
+	options.TANHandler = handler
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	if _, err := comdirect.NewAuthenticator(options, server.ClientOptions()...).Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+}
+
+func TestOnceAuthenticationHeaders(t *testing.T) {
+	challenge := &comdirect.TANChallenge{ID: `12"34\\`, Type: comdirect.TANTypePhoto}
+	header := comdirect.OnceAuthenticationHeaders(http.Header{}, challenge, "123456")
+
+	var info struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(header.Get(comdirect.OnceAuthenticationInfoHeaderKey)), &info); err != nil || info.ID != challenge.ID {
+		t.Errorf("expected challenge ID %q to be encoded as JSON, got %q: %v", challenge.ID, info.ID, err)
+	}
+	if header.Get(comdirect.OnceAuthenticationHeaderKey) != "123456" {
+		t.Errorf("expected TAN header, got: %v", header)
+	}
+}
@@ -0,0 +1,62 @@
+package comdirect
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoAuthentication is returned by a TokenStore if it does not hold an Authentication.
+var ErrNoAuthentication = errors.New("comdirect: no stored authentication")
+
+// TokenStore persists an Authentication including its refresh token and login time,
+// so that a session can be reused across processes. Implementations must be safe for concurrent use.
+//
+// The package tokenstore provides implementations backed by the OS keyring and an encrypted file.
+type TokenStore interface {
+	// Load returns the stored Authentication or ErrNoAuthentication if there is none.
+	Load(ctx context.Context) (*Authentication, error)
+	// Save stores the given Authentication and replaces any previously stored one.
+	Save(ctx context.Context, auth *Authentication) error
+	// Delete removes the stored Authentication. Deleting from an empty TokenStore is not an error.
+	Delete(ctx context.Context) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps the Authentication in memory.
+type MemoryTokenStore struct {
+	mu   sync.Mutex
+	auth *Authentication
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context) (*Authentication, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.auth == nil {
+		return nil, ErrNoAuthentication
+	}
+	auth := *s.auth
+	return &auth, nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, auth *Authentication) error {
+	if auth == nil {
+		return errors.New("authentication cannot be nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *auth
+	s.auth = &stored
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = nil
+	return nil
+}
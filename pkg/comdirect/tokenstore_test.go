@@ -0,0 +1,101 @@
+package comdirect_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+)
+
+func TestAuthentication_JSON(t *testing.T) {
+	loginTime := time.Date(2024, 3, 28, 10, 30, 0, 0, time.UTC)
+	auth := comdirect.NewAuthentication(comdirect.AccessToken{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresIn:    599,
+	}, "session", loginTime)
+
+	data, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded comdirect.Authentication
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.AccessToken() != auth.AccessToken() || decoded.SessionID() != "session" || !decoded.ExpiryTime().Equal(loginTime) {
+		t.Errorf("expected %+v, got %+v", auth, decoded)
+	}
+}
+
+func TestClient_WithTokenStore(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	store := comdirect.NewMemoryTokenStore()
+	opts := append(server.ClientOptions(), comdirect.WithTokenStore(store), comdirect.WithAutoRefresh(0))
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+	if _, err := client.Restore(ctx); !errors.Is(err, comdirect.ErrNotAuthenticated) {
+		t.Fatalf("expected ErrNotAuthenticated from empty store, got: %v", err)
+	}
+	auth, err := client.Authenticate(ctx)
+	if err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	if stored, err := store.Load(ctx); err != nil || stored.AccessToken() != auth.AccessToken() {
+		t.Fatalf("expected authentication to be saved: %v", err)
+	}
+
+	// A second client, e.g. in another process, picks up the stored session and refreshes it.
+	expired := comdirect.NewAuthentication(auth.AccessToken(), auth.SessionID(), time.Now().Add(-time.Hour))
+	if err = store.Save(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	other := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+	if _, err = other.Balances(ctx); err != nil {
+		t.Fatalf("expected stored authentication to be refreshed and used: %s", err)
+	}
+	stored, err := store.Load(ctx)
+	if err != nil || stored.AccessToken().AccessToken == auth.AccessToken().AccessToken {
+		t.Fatalf("expected refreshed authentication to be saved: %v", err)
+	}
+
+	if err = other.Revoke(); err != nil {
+		t.Fatalf("revoke failed: %s", err)
+	}
+	if _, err = store.Load(ctx); !errors.Is(err, comdirect.ErrNoAuthentication) {
+		t.Errorf("expected authentication to be deleted on revoke, got: %v", err)
+	}
+}
+
+func TestClient_Restore(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	store := comdirect.NewMemoryTokenStore()
+	opts := append(server.ClientOptions(), comdirect.WithTokenStore(store))
+
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...).Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	stored, _ := store.Load(ctx)
+	expired := comdirect.NewAuthentication(stored.AccessToken(), stored.SessionID(), time.Now().Add(-time.Hour))
+	if err := store.Save(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	client := comdirect.NewWithAuthOptions(server.AuthOptions(), opts...)
+	auth, err := client.Restore(ctx)
+	if err != nil {
+		t.Fatalf("expected expired authentication to be refreshed: %s", err)
+	}
+	if auth.IsExpired() || !client.IsAuthenticated() {
+		t.Error("expected restored authentication to be valid")
+	}
+}
@@ -0,0 +1,159 @@
+package comdirect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+// TransferType is the payment scheme of a transfer.
+type TransferType string
+
+const (
+	// TransferTypeSEPA is a SEPA credit transfer, usually credited on the next business day.
+	TransferTypeSEPA TransferType = "SEPA_CREDIT_TRANSFER"
+	// TransferTypeInstant is a SEPA instant credit transfer, credited within seconds if the
+	// bank of the creditor supports it.
+	TransferTypeInstant TransferType = "SEPA_INSTANT_CREDIT_TRANSFER"
+)
+
+const (
+	MaxCreditorNameLength      = 70
+	MaxRemittanceInfoLength    = 140
+	MaxEndToEndReferenceLength = 35
+)
+
+// ErrInvalidTransfer is returned if a TransferRequest violates the SEPA rules.
+var ErrInvalidTransfer = errors.New("comdirect: invalid transfer")
+
+// TransferRequest describes a transfer from an account of the user to a creditor.
+type TransferRequest struct {
+	Type              TransferType `json:"transferType"`
+	Creditor          Creditor     `json:"creditor"`
+	Amount            AmountValue  `json:"amount"`
+	RemittanceInfo    string       `json:"remittanceInfo,omitempty"`
+	EndToEndReference string       `json:"endToEndReference,omitempty"`
+}
+
+// Transfer is a transfer that was executed by comdirect.
+type Transfer struct {
+	TransferID        string    `json:"transferId"`
+	AccountID         string    `json:"accountId"`
+	Status            string    `json:"status"`
+	CreationTimestamp Timestamp `json:"creationTimestamp"`
+	TransferRequest
+}
+
+// NewTransferRequest creates a SEPA credit transfer of amount to the given creditor.
+// The IBAN and BIC of the creditor are normalized, e.g. spaces are removed.
+// Use TransferTypeInstant as Type for an instant transfer.
+func NewTransferRequest(creditor Creditor, amount AmountValue, remittanceInfo string) (*TransferRequest, error) {
+	creditor.Iban = iban.Normalize(creditor.Iban)
+	creditor.Bic = iban.Normalize(creditor.Bic)
+	transfer := &TransferRequest{
+		Type:           TransferTypeSEPA,
+		Creditor:       creditor,
+		Amount:         amount,
+		RemittanceInfo: remittanceInfo,
+	}
+	if err := transfer.Validate(); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Validate checks the TransferRequest against the SEPA rules before it is sent to comdirect.
+// The IBAN and BIC of the creditor must be in electronic format, see iban.Normalize.
+func (t *TransferRequest) Validate() error {
+	if err := t.validateCreditor(); err != nil {
+		return err
+	}
+	switch {
+	case t.Type != TransferTypeSEPA && t.Type != TransferTypeInstant:
+		return fmt.Errorf("%w: unsupported transfer type %q", ErrInvalidTransfer, t.Type)
+	case t.Creditor.HolderName == "":
+		return fmt.Errorf("%w: creditor name is required", ErrInvalidTransfer)
+	case utf8.RuneCountInString(t.Creditor.HolderName) > MaxCreditorNameLength:
+		return fmt.Errorf("%w: creditor name exceeds %d characters", ErrInvalidTransfer, MaxCreditorNameLength)
+	case t.Amount.Unit != "EUR":
+		return fmt.Errorf("%w: SEPA transfers must be in EUR, not %q", ErrInvalidTransfer, t.Amount.Unit)
+	case t.Amount.Value.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+	case t.Amount.Value.Scale() > 2 && !t.Amount.Value.Equal(t.Amount.Value.Round(2)):
+		return fmt.Errorf("%w: amount must not have more than two decimal places", ErrInvalidTransfer)
+	case utf8.RuneCountInString(t.RemittanceInfo) > MaxRemittanceInfoLength:
+		return fmt.Errorf("%w: remittance information exceeds %d characters", ErrInvalidTransfer, MaxRemittanceInfoLength)
+	case utf8.RuneCountInString(t.EndToEndReference) > MaxEndToEndReferenceLength:
+		return fmt.Errorf("%w: end-to-end reference exceeds %d characters", ErrInvalidTransfer, MaxEndToEndReferenceLength)
+	}
+	return nil
+}
+
+func (t *TransferRequest) validateCreditor() error {
+	if t.Creditor.Iban == "" {
+		return fmt.Errorf("%w: creditor IBAN is required", ErrInvalidTransfer)
+	}
+	if err := iban.Validate(t.Creditor.Iban); err != nil {
+		return fmt.Errorf("%w: creditor %w", ErrInvalidTransfer, err)
+	}
+	if t.Creditor.Iban != iban.Normalize(t.Creditor.Iban) {
+		return fmt.Errorf("%w: creditor IBAN must be in electronic format", ErrInvalidTransfer)
+	}
+	if t.Creditor.Bic == "" {
+		return nil
+	}
+	if err := iban.ValidateBIC(t.Creditor.Bic); err != nil {
+		return fmt.Errorf("%w: creditor %w", ErrInvalidTransfer, err)
+	}
+	if t.Creditor.Bic != iban.Normalize(t.Creditor.Bic) {
+		return fmt.Errorf("%w: creditor BIC must be in upper case", ErrInvalidTransfer)
+	}
+	return nil
+}
+
+// Transfer validates and executes a transfer from the account specified by its ID.
+// The TAN challenge is solved with the TANHandler of the AuthOptions, push TAN challenges are
+// awaited until they are approved in the photoTAN app.
+func (c *Client) Transfer(ctx context.Context, accountID string, transfer *TransferRequest) (*Transfer, error) {
+	challenge, tan, err := c.authorize(ctx, func(tanType TANType) (*TANChallenge, error) {
+		return c.validateTransfer(ctx, accountID, transfer, tanType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteTransfer(ctx, accountID, transfer, challenge, tan)
+}
+
+// ValidateTransfer validates a transfer from the account specified by its ID and returns the
+// TAN challenge required to execute it with ExecuteTransfer.
+func (c *Client) ValidateTransfer(ctx context.Context, accountID string, transfer *TransferRequest) (*TANChallenge, error) {
+	return c.validateTransfer(ctx, accountID, transfer, "")
+}
+
+func (c *Client) validateTransfer(ctx context.Context, accountID string, transfer *TransferRequest, tanType TANType) (*TANChallenge, error) {
+	if err := transfer.Validate(); err != nil {
+		return nil, err
+	}
+	return c.requestTANChallenge(ctx, fmt.Sprintf("/banking/v1/accounts/%s/transfers/validation", accountID), transfer, tanType)
+}
+
+// ExecuteTransfer executes a transfer validated by ValidateTransfer with the TAN of the challenge.
+// For TANTypePush challenges the TAN is empty and the challenge must be approved in the photoTAN app first.
+func (c *Client) ExecuteTransfer(ctx context.Context, accountID string, transfer *TransferRequest, challenge *TANChallenge, tan string) (*Transfer, error) {
+	if challenge == nil {
+		return nil, errors.New("TAN challenge cannot be nil")
+	}
+	req, err := c.newJSONRequest(ctx, http.MethodPost, fmt.Sprintf("/banking/v1/accounts/%s/transfers", accountID), transfer)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = onceAuthenticationHeaders(req.Header, challenge, tan)
+
+	executed := &Transfer{}
+	_, err = c.http.exchange(req, executed)
+	return executed, err
+}
@@ -0,0 +1,159 @@
+package comdirect_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+var testCreditor = comdirect.Creditor{HolderName: "Max Mustermann", Iban: "DE02100100100006820101", Bic: "PBNKDEFFXXX"}
+
+func TestClient_Transfer(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	transfer, err := comdirect.NewTransferRequest(testCreditor, comdirect.AmountValue{Value: comdirect.MustParseDecimal("100.50"), Unit: "EUR"}, "Invoice 2024-001")
+	if err != nil {
+		t.Fatalf("failed to create transfer: %s", err)
+	}
+	executed, err := client.Transfer(ctx, comdirecttest.AccountID, transfer)
+	if err != nil {
+		t.Fatalf("failed to execute transfer: %s", err)
+	}
+	if executed.TransferID == "" || executed.Status != "EXECUTED" || executed.Creditor != testCreditor {
+		t.Errorf("unexpected transfer: %+v", executed)
+	}
+
+	balance, err := client.Balance(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("failed to exchange account balance %s", err)
+	}
+	if balance.Balance.Value.String() != "2399.75" {
+		t.Errorf("expected balance to be reduced by the transfer, got: %s", balance.Balance.Value)
+	}
+	transactions, err := client.Transactions(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("failed to exchange account transactions %s", err)
+	}
+	if first := transactions.Values[0]; first.BookingStatus != "NOTBOOKED" || first.Amount.Value.String() != "-100.50" {
+		t.Errorf("expected transfer as not booked transaction, got: %+v", first)
+	}
+}
+
+func TestClient_Transfer_PhotoTAN(t *testing.T) {
+	server := comdirecttest.NewServer()
+	defer server.Close()
+	handler := &testTANHandler{preferred: comdirect.TANTypePhoto, tan: comdirecttest.TAN}
+	options := server.AuthOptions()
+	options.TANHandler = handler
+	client := comdirect.NewWithAuthOptions(options, server.ClientOptions()...)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+	if _, err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+
+	handler.challenge = nil
+	transfer := &comdirect.TransferRequest{
+		Type:              comdirect.TransferTypeInstant,
+		Creditor:          testCreditor,
+		Amount:            comdirect.AmountValue{Value: comdirect.MustParseDecimal("10"), Unit: "EUR"},
+		EndToEndReference: "PAYOUT-42",
+	}
+	if _, err := client.Transfer(ctx, comdirecttest.AccountID, transfer); err != nil {
+		t.Fatalf("failed to execute transfer: %s", err)
+	}
+	if handler.challenge == nil || handler.challenge.Type != comdirect.TANTypePhoto {
+		t.Errorf("expected photoTAN challenge for transfer, got: %+v", handler.challenge)
+	}
+}
+
+func TestClient_ExecuteTransfer_InvalidTAN(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	transfer, _ := comdirect.NewTransferRequest(testCreditor, comdirect.AmountValue{Value: comdirect.MustParseDecimal("1.00"), Unit: "EUR"}, "")
+	challenge, err := client.ValidateTransfer(ctx, comdirecttest.AccountID, transfer)
+	if err != nil {
+		t.Fatalf("failed to validate transfer: %s", err)
+	}
+	if challenge.ID == "" {
+		t.Fatalf("expected TAN challenge, got: %+v", challenge)
+	}
+	if _, err = client.ExecuteTransfer(ctx, comdirecttest.AccountID, transfer, challenge, "000000"); !errors.Is(err, comdirect.ErrUnprocessable) {
+		t.Errorf("expected invalid TAN to be rejected, got: %v", err)
+	}
+}
+
+func TestClient_ExecuteTransfer_ModifiedTransfer(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx, cancel := contextTimeout10Seconds()
+	defer cancel()
+
+	transfer, _ := comdirect.NewTransferRequest(testCreditor, comdirect.AmountValue{Value: comdirect.MustParseDecimal("1.00"), Unit: "EUR"}, "")
+	challenge, err := client.ValidateTransfer(ctx, comdirecttest.AccountID, transfer)
+	if err != nil {
+		t.Fatalf("failed to validate transfer: %s", err)
+	}
+	transfer.Amount.Value = comdirect.MustParseDecimal("1000.00")
+	if _, err = client.ExecuteTransfer(ctx, comdirecttest.AccountID, transfer, challenge, comdirecttest.TAN); err == nil {
+		t.Error("expected transfer that differs from the validated one to be rejected")
+	}
+}
+
+func TestTransferRequest_Validate(t *testing.T) {
+	eur := func(s string) comdirect.AmountValue {
+		return comdirect.AmountValue{Value: comdirect.MustParseDecimal(s), Unit: "EUR"}
+	}
+	tests := map[string]comdirect.TransferRequest{
+		"missing type":      {Creditor: testCreditor, Amount: eur("1")},
+		"missing creditor":  {Type: comdirect.TransferTypeSEPA, Amount: eur("1")},
+		"missing IBAN":      {Type: comdirect.TransferTypeSEPA, Creditor: comdirect.Creditor{HolderName: "Max"}, Amount: eur("1")},
+		"IBAN with typo":    {Type: comdirect.TransferTypeSEPA, Creditor: comdirect.Creditor{HolderName: "Max", Iban: "DE02100100100006820102"}, Amount: eur("1")},
+		"paper format IBAN": {Type: comdirect.TransferTypeSEPA, Creditor: comdirect.Creditor{HolderName: "Max", Iban: "DE02 1001 0010 0006 8201 01"}, Amount: eur("1")},
+		"invalid BIC":       {Type: comdirect.TransferTypeSEPA, Creditor: comdirect.Creditor{HolderName: "Max", Iban: testCreditor.Iban, Bic: "PBNKDE"}, Amount: eur("1")},
+		"foreign currency":  {Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: comdirect.AmountValue{Value: comdirect.MustParseDecimal("1"), Unit: "USD"}},
+		"zero amount":       {Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: eur("0")},
+		"negative amount":   {Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: eur("-1")},
+		"fractional cents":  {Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: eur("1.005")},
+		"long reference":    {Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: eur("1"), EndToEndReference: "012345678901234567890123456789012345"},
+	}
+	for name, transfer := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := transfer.Validate(); !errors.Is(err, comdirect.ErrInvalidTransfer) {
+				t.Errorf("expected ErrInvalidTransfer, got: %v", err)
+			}
+		})
+	}
+
+	valid := comdirect.TransferRequest{Type: comdirect.TransferTypeSEPA, Creditor: testCreditor, Amount: eur("1.500")}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected trailing zeros to be valid, got: %v", err)
+	}
+	valid.EndToEndReference = strings.Repeat("ä", comdirect.MaxEndToEndReferenceLength)
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected the reference length to be counted in characters, got: %v", err)
+	}
+}
+
+func TestNewTransferRequest_IBAN(t *testing.T) {
+	amount := comdirect.AmountValue{Value: comdirect.MustParseDecimal("1"), Unit: "EUR"}
+	transfer, err := comdirect.NewTransferRequest(comdirect.Creditor{HolderName: "Max", Iban: "de02 1001 0010 0006 8201 01", Bic: "pbnkdeff"}, amount, "")
+	if err != nil {
+		t.Fatalf("expected IBAN in paper format to be accepted: %s", err)
+	}
+	if transfer.Creditor.Iban != "DE02100100100006820101" || transfer.Creditor.Bic != "PBNKDEFF" {
+		t.Errorf("expected normalized creditor, got: %+v", transfer.Creditor)
+	}
+
+	_, err = comdirect.NewTransferRequest(comdirect.Creditor{HolderName: "Max", Iban: "DE02100100100006820110"}, amount, "")
+	if !errors.Is(err, comdirect.ErrInvalidTransfer) || !errors.Is(err, iban.ErrChecksum) {
+		t.Errorf("expected IBAN checksum error, got: %v", err)
+	}
+}
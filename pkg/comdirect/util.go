@@ -1,33 +1,52 @@
 package comdirect
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/jsattler/go-comdirect/internal/httpstatus"
 	"golang.org/x/time/rate"
 )
 
 // TODO: Think about where to put the stuff in here.
 // TODO: Currently this is a pool for everything that does not fit somewhere else
 
+var errInvalidBaseURL = errors.New("base URL must contain a scheme and a host")
+
 type HTTPClient struct {
-	http.Client
-	rate.Limiter
+	*http.Client
+	*rate.Limiter
+	baseURL   *url.URL
+	userAgent string
+	err       error
 }
 
-// api.comdirect.de/api/{path}
-func apiURL(path string) *url.URL {
-	return comdirectURL(ApiPath + path)
+// {baseURL}/api/{path}
+func (h *HTTPClient) apiURL(path string) *url.URL {
+	return h.comdirectURL(ApiPath + path)
 }
 
-// api.comdirect.de/{path}
-func comdirectURL(path string) *url.URL {
-	return &url.URL{Host: Host, Scheme: HttpsScheme, Path: path}
+// {baseURL}/{path}
+func (h *HTTPClient) comdirectURL(path string) *url.URL {
+	if h.baseURL == nil {
+		return &url.URL{Host: Host, Scheme: HttpsScheme, Path: path}
+	}
+	return &url.URL{
+		Scheme: h.baseURL.Scheme,
+		User:   h.baseURL.User,
+		Host:   h.baseURL.Host,
+		Path:   strings.TrimSuffix(h.baseURL.Path, "/") + path,
+	}
 }
 
 func encodeOptions(url *url.URL, options []Options) {
@@ -57,23 +76,73 @@ func generateRequestID() string {
 	return id[0:9]
 }
 
-func (h *HTTPClient) exchange(request *http.Request, target interface{}) (*http.Response, error) {
-	err := h.Wait(request.Context())
-	if err != nil {
+// do waits for the rate limiter and sends the request with the configured User-Agent.
+// Responses with a non 2xx status code are returned as *APIError with the body already closed.
+func (h *HTTPClient) do(request *http.Request) (*http.Response, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	if err := h.Wait(request.Context()); err != nil {
 		return nil, err
 	}
+	if h.userAgent != "" {
+		if request.Header == nil {
+			request.Header = http.Header{}
+		}
+		request.Header.Set("User-Agent", h.userAgent)
+	}
 	res, err := h.Do(request)
 	if err != nil {
 		return res, err
 	}
+	if !httpstatus.Is2xx(res) {
+		return res, newAPIError(res)
+	}
+	return res, nil
+}
+
+func (h *HTTPClient) exchange(request *http.Request, target interface{}) (*http.Response, error) {
+	res, err := h.do(request)
+	if err != nil {
+		return res, err
+	}
 
 	if err = json.NewDecoder(res.Body).Decode(target); err != nil {
+		_ = res.Body.Close()
 		return res, err
 	}
 
 	return res, res.Body.Close()
 }
 
+// newJSONRequest creates an authenticated request to the REST API with body encoded as JSON.
+// The request has no body if body is nil.
+func (c *Client) newJSONRequest(ctx context.Context, method string, path string, body interface{}) (*http.Request, error) {
+	auth, err := c.validAuthentication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := requestInfoJSON(auth.sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: method,
+		URL:    c.http.apiURL(path),
+		Header: defaultHeaders(auth.accessToken.AccessToken, string(info)),
+	}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+	}
+	return req.WithContext(ctx), nil
+}
+
 func requestInfoJSON(sessionID string) ([]byte, error) {
 	info := &requestInfo{ClientRequestID: clientRequestID{
 		SessionID: sessionID,
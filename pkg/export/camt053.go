@@ -0,0 +1,279 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// CAMT053Namespace is the XML namespace of the CAMT.053 version written by WriteCAMT053,
+// which is supported by German banking and accounting software.
+const CAMT053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// maxUnstructuredLength is the maximum length of an unstructured remittance info element.
+const maxUnstructuredLength = 140
+
+// WriteCAMT053 writes the Statement as ISO 20022 CAMT.053 (camt.053.001.02) bank to customer statement.
+func WriteCAMT053(w io.Writer, s *Statement) error {
+	currency := s.currency()
+	stmt := camtStatement{
+		ID:           s.ID,
+		ElctrncSeqNb: s.Number,
+		CreDtTm:      s.CreatedAt.Format(time.RFC3339),
+		FrToDt: camtFromToDate{
+			FrDtTm: s.From.Time().Format(time.RFC3339),
+			ToDtTm: s.To.AddDays(1).Time().Add(-time.Second).Format(time.RFC3339),
+		},
+		Acct: camtAccount{
+			IBAN: s.Account.Iban,
+			Ccy:  currency,
+			BIC:  s.bic(),
+		},
+		Bal: []camtBalance{
+			newCAMTBalance("PRCD", s.OpeningBalance, s.From.AddDays(-1)),
+			newCAMTBalance("CLBD", s.ClosingBalance, s.To),
+		},
+	}
+
+	credit, debit := &camtSum{}, &camtSum{}
+	var creditSum, debitSum comdirect.Decimal
+	for _, t := range s.Transactions {
+		entry := newCAMTEntry(t)
+		stmt.Ntry = append(stmt.Ntry, entry)
+		if entry.CdtDbtInd == "CRDT" {
+			credit.NbOfNtries++
+			creditSum = creditSum.Add(t.Amount.Value.Abs())
+		} else {
+			debit.NbOfNtries++
+			debitSum = debitSum.Add(t.Amount.Value.Abs())
+		}
+	}
+	credit.Sum, debit.Sum = formatCAMTAmount(creditSum), formatCAMTAmount(debitSum)
+	stmt.TxsSummry = &camtSummary{TtlCdtNtries: credit, TtlDbtNtries: debit}
+
+	doc := camtDocument{
+		Xmlns: CAMT053Namespace,
+		BkToCstmrStmt: camtBankToCustomerStatement{
+			GrpHdr: camtGroupHeader{MsgId: s.ID, CreDtTm: s.CreatedAt.Format(time.RFC3339)},
+			Stmt:   stmt,
+		},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newCAMTBalance(code string, amount comdirect.AmountValue, date comdirect.Date) camtBalance {
+	return camtBalance{
+		Cd:        code,
+		Amt:       camtAmount{Ccy: amount.Unit, Value: formatCAMTAmount(amount.Value.Abs())},
+		CdtDbtInd: creditDebit(amount.Value),
+		Dt:        date.String(),
+	}
+}
+
+func newCAMTEntry(t comdirect.AccountTransaction) camtEntry {
+	entry := camtEntry{
+		Amt:         camtAmount{Ccy: t.Amount.Unit, Value: formatCAMTAmount(t.Amount.Value.Abs())},
+		CdtDbtInd:   creditDebit(t.Amount.Value),
+		Sts:         "BOOK",
+		BookgDt:     camtDate{Dt: t.BookingDate.String()},
+		AcctSvcrRef: t.Reference,
+		BkTxCd:      camtBankTransactionCode{Cd: t.TransactionType.Key},
+	}
+	if entry.BkTxCd.Cd == "" {
+		entry.BkTxCd.Cd = "NOTPROVIDED"
+	}
+	if !t.ValutaDate.IsZero() {
+		entry.ValDt = &camtDate{Dt: t.ValutaDate.String()}
+	}
+
+	details := camtTransactionDetails{
+		Refs: &camtReferences{EndToEndId: t.EndToEndReference, MndtId: t.DirectDebitMandateID},
+	}
+	if details.Refs.EndToEndId == "" {
+		details.Refs.EndToEndId = "NOTPROVIDED"
+	}
+	name, iban, bic := counterparty(t)
+	if name != "" || iban != "" {
+		party := &camtParty{Nm: name}
+		var account *camtPartyAccount
+		if iban != "" {
+			account = &camtPartyAccount{IBAN: iban}
+		}
+		details.RltdPties = &camtRelatedParties{}
+		if entry.CdtDbtInd == "DBIT" {
+			details.RltdPties.Cdtr, details.RltdPties.CdtrAcct = party, account
+		} else {
+			details.RltdPties.Dbtr, details.RltdPties.DbtrAcct = party, account
+		}
+	}
+	if bic != "" {
+		details.RltdAgts = &camtRelatedAgents{}
+		if entry.CdtDbtInd == "DBIT" {
+			details.RltdAgts.CdtrAgt = &camtAgent{BIC: bic}
+		} else {
+			details.RltdAgts.DbtrAgt = &camtAgent{BIC: bic}
+		}
+	}
+	if info := strings.Join(RemittanceLines(t.RemittanceInfo), " "); info != "" {
+		details.RmtInf = &camtRemittanceInfo{Ustrd: truncate(info, maxUnstructuredLength)}
+	}
+	entry.NtryDtls = &camtEntryDetails{TxDtls: details}
+	entry.AddtlNtryInf = t.TransactionType.Text
+	return entry
+}
+
+func creditDebit(d comdirect.Decimal) string {
+	if d.Sign() < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func formatCAMTAmount(d comdirect.Decimal) string {
+	return d.Round(2).String()
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+type camtDocument struct {
+	XMLName       xml.Name                    `xml:"Document"`
+	Xmlns         string                      `xml:"xmlns,attr"`
+	BkToCstmrStmt camtBankToCustomerStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtBankToCustomerStatement struct {
+	GrpHdr camtGroupHeader `xml:"GrpHdr"`
+	Stmt   camtStatement   `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MsgId   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	ID           string         `xml:"Id"`
+	ElctrncSeqNb int            `xml:"ElctrncSeqNb,omitempty"`
+	CreDtTm      string         `xml:"CreDtTm"`
+	FrToDt       camtFromToDate `xml:"FrToDt"`
+	Acct         camtAccount    `xml:"Acct"`
+	Bal          []camtBalance  `xml:"Bal"`
+	TxsSummry    *camtSummary   `xml:"TxsSummry,omitempty"`
+	Ntry         []camtEntry    `xml:"Ntry"`
+}
+
+type camtFromToDate struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+	Ccy  string `xml:"Ccy,omitempty"`
+	BIC  string `xml:"Svcr>FinInstnId>BIC"`
+}
+
+type camtBalance struct {
+	Cd        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	Dt        string     `xml:"Dt>Dt"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtSummary struct {
+	TtlCdtNtries *camtSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries *camtSum `xml:"TtlDbtNtries"`
+}
+
+type camtSum struct {
+	NbOfNtries int    `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Amt          camtAmount              `xml:"Amt"`
+	CdtDbtInd    string                  `xml:"CdtDbtInd"`
+	Sts          string                  `xml:"Sts"`
+	BookgDt      camtDate                `xml:"BookgDt"`
+	ValDt        *camtDate               `xml:"ValDt,omitempty"`
+	AcctSvcrRef  string                  `xml:"AcctSvcrRef,omitempty"`
+	BkTxCd       camtBankTransactionCode `xml:"BkTxCd"`
+	NtryDtls     *camtEntryDetails       `xml:"NtryDtls,omitempty"`
+	AddtlNtryInf string                  `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camtDate struct {
+	Dt string `xml:"Dt"`
+}
+
+type camtBankTransactionCode struct {
+	Cd string `xml:"Prtry>Cd"`
+}
+
+type camtEntryDetails struct {
+	TxDtls camtTransactionDetails `xml:"TxDtls"`
+}
+
+type camtTransactionDetails struct {
+	Refs      *camtReferences     `xml:"Refs,omitempty"`
+	RltdPties *camtRelatedParties `xml:"RltdPties,omitempty"`
+	RltdAgts  *camtRelatedAgents  `xml:"RltdAgts,omitempty"`
+	RmtInf    *camtRemittanceInfo `xml:"RmtInf,omitempty"`
+}
+
+type camtReferences struct {
+	EndToEndId string `xml:"EndToEndId,omitempty"`
+	MndtId     string `xml:"MndtId,omitempty"`
+}
+
+type camtRelatedParties struct {
+	Dbtr     *camtParty        `xml:"Dbtr,omitempty"`
+	DbtrAcct *camtPartyAccount `xml:"DbtrAcct,omitempty"`
+	Cdtr     *camtParty        `xml:"Cdtr,omitempty"`
+	CdtrAcct *camtPartyAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtParty struct {
+	Nm string `xml:"Nm,omitempty"`
+}
+
+type camtPartyAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type camtRelatedAgents struct {
+	DbtrAgt *camtAgent `xml:"DbtrAgt,omitempty"`
+	CdtrAgt *camtAgent `xml:"CdtrAgt,omitempty"`
+}
+
+type camtAgent struct {
+	BIC string `xml:"FinInstnId>BIC"`
+}
+
+type camtRemittanceInfo struct {
+	Ustrd string `xml:"Ustrd"`
+}
@@ -0,0 +1,221 @@
+// Package export converts comdirect account transactions into the statement formats of accounting software,
+// e.g. ISO 20022 CAMT.053 and SWIFT MT940.
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// ComdirectBIC is the BIC of comdirect, used as account servicer if the Statement has no BIC.
+const ComdirectBIC = "COBADEHDXXX"
+
+// remittanceLineLength is the length of a numbered line of the remittance info of comdirect,
+// a two digit line number followed by 35 characters.
+const remittanceLineLength = 37
+
+// ErrNoTransactions is returned by NewStatement if there are no booked transactions.
+var ErrNoTransactions = errors.New("export: no booked transactions")
+
+// Statement is an account statement: the booked transactions of an account in a period with the opening
+// balance before the first and the closing balance after the last transaction.
+type Statement struct {
+	// ID identifies the statement, e.g. in the MT940 field :20: and the CAMT.053 message ID.
+	ID string
+	// Number is the sequence number of the statement, e.g. in the MT940 field :28C:.
+	Number int
+	// CreatedAt is the creation time of the statement.
+	CreatedAt time.Time
+	// Account is the account of the statement.
+	Account comdirect.Account
+	// BIC is the BIC of the account servicer, defaults to ComdirectBIC.
+	BIC string
+	// From and To are the first and last booking date of the period.
+	From comdirect.Date
+	To   comdirect.Date
+	// OpeningBalance and ClosingBalance are the booked balances at the start and end of the period.
+	OpeningBalance comdirect.AmountValue
+	ClosingBalance comdirect.AmountValue
+	// Transactions are the booked transactions of the period, ordered by booking date, oldest first.
+	Transactions []comdirect.AccountTransaction
+}
+
+// NewStatement creates a Statement of the booked transactions that ends with the current balance of the account.
+// The transactions must include all transactions booked since the first of them, as the opening balance is
+// derived from the current balance. Transactions that are not booked yet are skipped.
+func NewStatement(balance comdirect.AccountBalance, transactions []comdirect.AccountTransaction) (*Statement, error) {
+	var booked []comdirect.AccountTransaction
+	for _, t := range transactions {
+		if t.BookingStatus == "BOOKED" && !t.BookingDate.IsZero() {
+			booked = append(booked, t)
+		}
+	}
+	if len(booked) == 0 {
+		return nil, ErrNoTransactions
+	}
+	slices.SortStableFunc(booked, func(a, b comdirect.AccountTransaction) int {
+		return a.BookingDate.Time().Compare(b.BookingDate.Time())
+	})
+
+	opening := balance.Balance
+	for _, t := range booked {
+		var err error
+		if opening, err = opening.Sub(t.Amount); err != nil {
+			return nil, err
+		}
+	}
+	from, to := booked[0].BookingDate, booked[len(booked)-1].BookingDate
+	return &Statement{
+		ID:             "STMT" + strings.ReplaceAll(to.String(), "-", ""),
+		Number:         1,
+		CreatedAt:      time.Now(),
+		Account:        balance.Account,
+		BIC:            ComdirectBIC,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: balance.Balance,
+		Transactions:   booked,
+	}, nil
+}
+
+// Until returns a copy of the Statement that ends with the transactions booked on date.
+// The closing balance is adjusted by the transactions booked after date.
+func (s *Statement) Until(date comdirect.Date) (*Statement, error) {
+	until := *s
+	until.Transactions = nil
+	for _, t := range s.Transactions {
+		if !t.BookingDate.After(date) {
+			until.Transactions = append(until.Transactions, t)
+			continue
+		}
+		var err error
+		if until.ClosingBalance, err = until.ClosingBalance.Sub(t.Amount); err != nil {
+			return nil, err
+		}
+	}
+	if len(until.Transactions) == 0 {
+		return nil, ErrNoTransactions
+	}
+	until.To = until.Transactions[len(until.Transactions)-1].BookingDate
+	return &until, nil
+}
+
+// InvestmentStatement is a depot statement: the positions of a depot and its booked transactions.
+type InvestmentStatement struct {
+	// ID identifies the statement, e.g. in the OFX transaction UID.
+	ID string
+	// CreatedAt is the creation time of the statement and the date of the positions.
+	CreatedAt time.Time
+	// Depot is the depot of the statement.
+	Depot comdirect.Depot
+	// Positions are the current positions of the depot.
+	Positions []comdirect.DepotPosition
+	// Transactions are the booked transactions of the depot, ordered by booking date, oldest first.
+	Transactions []comdirect.DepotTransaction
+}
+
+// NewInvestmentStatement creates an InvestmentStatement of the positions and transactions of the depot.
+// Transactions that are not booked yet are skipped.
+func NewInvestmentStatement(depot comdirect.Depot, positions []comdirect.DepotPosition, transactions []comdirect.DepotTransaction) *InvestmentStatement {
+	var booked []comdirect.DepotTransaction
+	for _, t := range transactions {
+		if t.BookingStatus != "NOTBOOKED" && !tradeDate(t).IsZero() {
+			booked = append(booked, t)
+		}
+	}
+	slices.SortStableFunc(booked, func(a, b comdirect.DepotTransaction) int {
+		return tradeDate(a).Time().Compare(tradeDate(b).Time())
+	})
+	now := time.Now()
+	return &InvestmentStatement{
+		ID:           "INV" + now.In(comdirect.Location).Format("20060102"),
+		CreatedAt:    now,
+		Depot:        depot,
+		Positions:    positions,
+		Transactions: booked,
+	}
+}
+
+// FITID returns a stable ID of a booked transaction, so that accounting software recognizes transactions
+// on repeated imports. It is the reference of the transaction, or a hash of its contents if comdirect
+// provides no reference.
+func FITID(t comdirect.AccountTransaction) string {
+	if t.Reference != "" {
+		return t.Reference
+	}
+	name, counterpartyIBAN, _ := counterparty(t)
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		t.BookingDate.String(), t.Amount.String(), name, counterpartyIBAN, t.EndToEndReference, t.RemittanceInfo,
+	}, "|")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// tradeDate returns the booking date of a depot transaction, or its business date if it has no booking date.
+func tradeDate(t comdirect.DepotTransaction) comdirect.Date {
+	if t.BookingDate.IsZero() {
+		return t.BusinessDate
+	}
+	return t.BookingDate
+}
+
+// tradeAmounts returns the quantity of a depot transaction, negative for sales, the payment, negative
+// for purchases, and the fees as the difference between the transaction value and the execution price.
+func tradeAmounts(t comdirect.DepotTransaction) (quantity comdirect.Decimal, cash comdirect.Decimal, fees comdirect.Decimal) {
+	quantity = t.Quantity.Value.Abs()
+	value := t.TransactionValue.Value.Abs()
+	cost := quantity.Mul(t.ExecutionPrice.Value)
+	if value.IsZero() {
+		value = cost
+	}
+	if t.TransactionDirection == "OUT" {
+		return quantity.Neg(), value, cost.Sub(value)
+	}
+	return quantity, value.Neg(), value.Sub(cost)
+}
+
+func (s *Statement) bic() string {
+	if s.BIC == "" {
+		return ComdirectBIC
+	}
+	return s.BIC
+}
+
+func (s *Statement) currency() string {
+	if s.Account.Currency != "" {
+		return s.Account.Currency
+	}
+	return s.ClosingBalance.Unit
+}
+
+// RemittanceLines splits the remittance info of comdirect into its lines. comdirect numbers the lines of the
+// remittance info, e.g. "01Miete März  02Wohnung 3", the numbers are removed.
+func RemittanceLines(info string) []string {
+	if !strings.HasPrefix(info, "01") {
+		return []string{strings.TrimSpace(info)}
+	}
+	var lines []string
+	runes := []rune(info)
+	for len(runes) > 0 {
+		chunk := runes[:min(remittanceLineLength, len(runes))]
+		runes = runes[len(chunk):]
+		if line := strings.TrimSpace(string(chunk[min(2, len(chunk)):])); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// counterparty returns the name, IBAN and BIC of the other party of a transaction.
+func counterparty(t comdirect.AccountTransaction) (name string, iban string, bic string) {
+	if t.Amount.Value.Sign() < 0 || t.Remitter.HolderName == "" {
+		return t.Creditor.HolderName, t.Creditor.Iban, t.Creditor.Bic
+	}
+	return t.Remitter.HolderName, "", ""
+}
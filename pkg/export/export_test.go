@@ -0,0 +1,142 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+	"github.com/jsattler/go-comdirect/pkg/export"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestNewStatement(t *testing.T) {
+	statement := testStatement(t)
+
+	if len(statement.Transactions) != 4 {
+		t.Fatalf("expected 4 booked transactions, got %d", len(statement.Transactions))
+	}
+	if !statement.From.Equal(comdirect.NewDate(2024, time.March, 19)) || !statement.To.Equal(comdirect.NewDate(2024, time.March, 28)) {
+		t.Errorf("unexpected period %s to %s", statement.From, statement.To)
+	}
+	if statement.OpeningBalance.String() != "2258.45 EUR" || statement.ClosingBalance.String() != "2500.25 EUR" {
+		t.Errorf("unexpected balances %s and %s", statement.OpeningBalance, statement.ClosingBalance)
+	}
+
+	_, err := export.NewStatement(comdirect.AccountBalance{}, []comdirect.AccountTransaction{{BookingStatus: "NOTBOOKED"}})
+	if !errors.Is(err, export.ErrNoTransactions) {
+		t.Errorf("expected ErrNoTransactions, got: %v", err)
+	}
+}
+
+func TestStatement_Until(t *testing.T) {
+	statement, err := testStatement(t).Until(comdirect.NewDate(2024, time.March, 26))
+	if err != nil {
+		t.Fatalf("failed to limit statement: %s", err)
+	}
+	if len(statement.Transactions) != 3 || !statement.To.Equal(comdirect.NewDate(2024, time.March, 25)) {
+		t.Errorf("expected 3 transactions until 2024-03-25, got %d until %s", len(statement.Transactions), statement.To)
+	}
+	if statement.OpeningBalance.String() != "2258.45 EUR" || statement.ClosingBalance.String() != "2554.35 EUR" {
+		t.Errorf("unexpected balances %s and %s", statement.OpeningBalance, statement.ClosingBalance)
+	}
+	if _, err = statement.Until(comdirect.NewDate(2024, time.January, 1)); !errors.Is(err, export.ErrNoTransactions) {
+		t.Errorf("expected ErrNoTransactions, got: %v", err)
+	}
+}
+
+func TestWriteCAMT053(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteCAMT053(&buf, testStatement(t)); err != nil {
+		t.Fatalf("failed to write CAMT.053: %s", err)
+	}
+	assertGolden(t, "statement.camt053.xml", buf.Bytes())
+
+	var doc struct {
+		Entries []struct {
+			Amount    string `xml:"Amt"`
+			Indicator string `xml:"CdtDbtInd"`
+		} `xml:"BkToCstmrStmt>Stmt>Ntry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected well-formed XML: %s", err)
+	}
+	if len(doc.Entries) != 4 || doc.Entries[0].Amount != "54.10" || doc.Entries[0].Indicator != "DBIT" {
+		t.Errorf("unexpected entries: %+v", doc.Entries)
+	}
+}
+
+func TestWriteMT940(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteMT940(&buf, testStatement(t)); err != nil {
+		t.Fatalf("failed to write MT940: %s", err)
+	}
+	assertGolden(t, "statement.mt940", buf.Bytes())
+
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\r\n")), []byte("\r\n")) {
+		if len(line) > 65 {
+			t.Errorf("line exceeds 65 characters: %q", line)
+		}
+		for _, c := range line {
+			if c > 127 {
+				t.Errorf("line contains non-ASCII character: %q", line)
+			}
+		}
+	}
+}
+
+func TestRemittanceLines(t *testing.T) {
+	tests := map[string][]string{
+		"01Miete": {"Miete"},
+		"01Miete März                         02Wohnung 3": {"Miete März", "Wohnung 3"},
+		"Gehalt": {"Gehalt"},
+	}
+	for info, want := range tests {
+		if got := export.RemittanceLines(info); !slices.Equal(got, want) {
+			t.Errorf("expected %q for %q, got %q", want, info, got)
+		}
+	}
+}
+
+// testStatement returns a statement of the transactions of the default fixtures since 2024-03-19.
+func testStatement(t *testing.T) *export.Statement {
+	t.Helper()
+	fixtures := comdirecttest.DefaultFixtures()
+	var transactions []comdirect.AccountTransaction
+	for _, transaction := range fixtures.Transactions[comdirecttest.AccountID] {
+		if !transaction.BookingDate.Before(comdirect.NewDate(2024, time.March, 19)) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	statement, err := export.NewStatement(fixtures.Balances[0], transactions)
+	if err != nil {
+		t.Fatalf("failed to create statement: %s", err)
+	}
+	statement.CreatedAt = time.Date(2024, time.March, 29, 8, 0, 0, 0, comdirect.Location)
+	return statement
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run go test with -update to create it: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run go test with -update after verifying the changes:\n%s", path, got)
+	}
+}
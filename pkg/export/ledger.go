@@ -0,0 +1,271 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// JournalFormat is the syntax of a plain-text accounting journal.
+type JournalFormat string
+
+const (
+	FormatLedger    JournalFormat = "ledger"
+	FormatHLedger   JournalFormat = "hledger"
+	FormatBeancount JournalFormat = "beancount"
+)
+
+// postingWidth is the column width of accounts in postings, so that amounts are aligned.
+const postingWidth = 40
+
+// ErrUnsupportedFormat is returned by NewJournal for an unknown JournalFormat.
+var ErrUnsupportedFormat = errors.New("export: unsupported journal format")
+
+// Journal writes comdirect transactions as balanced entries of a plain-text accounting journal in the syntax
+// of ledger, hledger or beancount. The accounts of the entries are determined by Rules.
+//
+// Beancount requires an open directive for every account, Journal does not write them, as they usually
+// belong to the main file of the books.
+type Journal struct {
+	format JournalFormat
+	rules  Rules
+}
+
+// NewJournal creates a Journal in the given format. If rules is nil, transactions are booked against
+// Expenses:Unknown and Income:Unknown.
+func NewJournal(format JournalFormat, rules *Rules) (*Journal, error) {
+	switch format {
+	case FormatLedger, FormatHLedger, FormatBeancount:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	j := &Journal{format: format}
+	if rules != nil {
+		j.rules = *rules
+		j.rules.Rules = slices.Clone(rules.Rules)
+	}
+	if err := j.rules.compile(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// WriteStatement writes an entry for each transaction of the Statement, followed by an assertion of
+// the closing balance.
+func (j *Journal) WriteStatement(w io.Writer, s *Statement) error {
+	account := j.rules.account(s.Account.AccountDisplayID, s.Account.AccountID, s.Account.Iban)
+
+	var entries []entry
+	for i, t := range s.Transactions {
+		name, counterpartyIBAN, _ := counterparty(t)
+		e := entry{
+			date:      t.BookingDate,
+			code:      t.Reference,
+			payee:     cmp.Or(name, t.TransactionType.Text),
+			narration: strings.Join(RemittanceLines(t.RemittanceInfo), " "),
+			meta: [][2]string{
+				{"type", t.TransactionType.Key},
+				{"iban", counterpartyIBAN},
+				{"endToEndReference", t.EndToEndReference},
+				{"mandateId", t.DirectDebitMandateID},
+				{"creditorId", t.DirectDebitCreditorID},
+			},
+			postings: []posting{
+				{account: account, amount: j.amount(t.Amount)},
+				{account: j.rules.Match(t), amount: j.amount(t.Amount.Neg())},
+			},
+		}
+		if i == len(s.Transactions)-1 && j.format != FormatBeancount {
+			e.postings[0].assertion = j.amount(s.ClosingBalance)
+		}
+		entries = append(entries, e)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		j.writeEntry(bw, e)
+	}
+	if j.format == FormatBeancount {
+		// beancount asserts balances at the beginning of the day
+		fmt.Fprintf(bw, "%s balance %s %s\n\n", s.To.AddDays(1), account, j.amount(s.ClosingBalance))
+	}
+	return bw.Flush()
+}
+
+// WriteDepotTransactions writes an entry for each booked transaction of the depot. Securities are posted
+// with their ISIN as commodity, purchases at their execution price as cost basis. The payments are posted
+// to the default settlement account of the depot.
+func (j *Journal) WriteDepotTransactions(w io.Writer, depot comdirect.Depot, transactions []comdirect.DepotTransaction) error {
+	depotAccount := j.rules.account(depot.DepotDisplayId, depot.DepotId)
+	cashAccount := j.rules.account(depot.DefaultSettlementAccountId)
+
+	bw := bufio.NewWriter(w)
+	for _, t := range transactions {
+		date := tradeDate(t)
+		if t.BookingStatus == "NOTBOOKED" || date.IsZero() {
+			continue
+		}
+		e, err := j.depotEntry(t, depotAccount, cashAccount)
+		if err != nil {
+			return err
+		}
+		e.date = date
+		j.writeEntry(bw, e)
+	}
+	return bw.Flush()
+}
+
+// depotEntry creates the entry of a depot transaction. Buys and sells are paid from the cash account,
+// other transactions are booked against the Equity account.
+func (j *Journal) depotEntry(t comdirect.DepotTransaction, depotAccount string, cashAccount string) (entry, error) {
+	if t.Quantity.Value.IsZero() {
+		return entry{}, fmt.Errorf("export: depot transaction %s has no quantity", t.TransactionID)
+	}
+	quantity, cash, fees := tradeAmounts(t)
+	commodity := j.commodity(securityCommodity(t.Instrument))
+	price := j.amount(t.ExecutionPrice)
+
+	security := posting{account: depotAccount, amount: quantity.String() + " " + commodity}
+	switch {
+	case t.ExecutionPrice.Value.IsZero():
+	case quantity.Sign() < 0 && j.format == FormatBeancount:
+		security.amount += " {} @ " + price
+	case quantity.Sign() < 0 || j.format == FormatHLedger:
+		security.amount += " @ " + price
+	default:
+		security.amount += " {" + price + "}"
+	}
+
+	e := entry{
+		code:      t.TransactionID,
+		payee:     cmp.Or(t.Instrument.ShortName, t.Instrument.Name),
+		narration: strings.TrimSpace(t.TransactionType + " " + t.Quantity.Value.Abs().String() + " " + t.Instrument.WKN),
+		meta:      [][2]string{{"isin", t.Instrument.ISIN}, {"wkn", t.Instrument.WKN}},
+		postings:  []posting{security},
+	}
+	if t.TransactionType != "BUY" && t.TransactionType != "SELL" {
+		e.postings = append(e.postings, posting{account: j.rules.Equity})
+		return e, nil
+	}
+
+	// the difference between the transaction value and the price of the securities is booked as fees
+	unit := cmp.Or(t.TransactionValue.Unit, t.ExecutionPrice.Unit)
+	e.postings = append(e.postings, posting{account: cashAccount, amount: j.amount(comdirect.AmountValue{Value: cash, Unit: unit})})
+	if !fees.IsZero() {
+		e.postings = append(e.postings, posting{account: j.rules.Fees, amount: j.amount(comdirect.AmountValue{Value: fees, Unit: unit})})
+	}
+	if quantity.Sign() < 0 && j.format == FormatBeancount {
+		e.postings = append(e.postings, posting{account: j.rules.Gains})
+	}
+	return e, nil
+}
+
+// entry is a transaction of the journal.
+type entry struct {
+	date      comdirect.Date
+	code      string
+	payee     string
+	narration string
+	meta      [][2]string
+	postings  []posting
+}
+
+// posting is a posting of an entry, an empty amount is inferred by the accounting software.
+type posting struct {
+	account   string
+	amount    string
+	assertion string
+}
+
+func (j *Journal) writeEntry(w io.Writer, e entry) {
+	indent := "    "
+	switch j.format {
+	case FormatBeancount:
+		indent = "  "
+		fmt.Fprintf(w, "%s *", e.date)
+		if e.payee != "" {
+			fmt.Fprintf(w, " %s", beancountString(e.payee))
+		}
+		fmt.Fprintf(w, " %s\n", beancountString(e.narration))
+		if e.code != "" {
+			fmt.Fprintf(w, "%sreference: %s\n", indent, beancountString(e.code))
+		}
+		for _, m := range e.meta {
+			if m[1] != "" {
+				fmt.Fprintf(w, "%s%s: %s\n", indent, m[0], beancountString(m[1]))
+			}
+		}
+	default:
+		fmt.Fprintf(w, "%s *", e.date)
+		if code := strings.NewReplacer("(", "", ")", "").Replace(singleLine(e.code)); code != "" {
+			fmt.Fprintf(w, " (%s)", code)
+		}
+		description := singleLine(e.payee)
+		if j.format == FormatHLedger {
+			description = strings.ReplaceAll(description, "|", "/")
+			if narration := singleLine(e.narration); narration != "" {
+				description += " | " + narration
+			}
+		}
+		fmt.Fprintf(w, " %s\n", description)
+		if narration := singleLine(e.narration); j.format == FormatLedger && narration != "" {
+			fmt.Fprintf(w, "%s; %s\n", indent, narration)
+		}
+		for _, m := range e.meta {
+			if m[1] != "" {
+				fmt.Fprintf(w, "%s; %s: %s\n", indent, m[0], singleLine(m[1]))
+			}
+		}
+	}
+
+	for _, p := range e.postings {
+		line := indent + p.account
+		if p.amount != "" {
+			line = fmt.Sprintf("%s%-*s  %12s", indent, postingWidth, p.account, p.amount)
+		}
+		if p.assertion != "" {
+			line += " = " + p.assertion
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// amount formats an amount with its commodity, e.g. "-54.10 EUR".
+func (j *Journal) amount(a comdirect.AmountValue) string {
+	return a.Value.String() + " " + j.commodity(a.Unit)
+}
+
+// commodity returns the commodity symbol, ledger and hledger require quotes for symbols with digits.
+func (j *Journal) commodity(symbol string) string {
+	if j.format == FormatBeancount || strings.IndexFunc(symbol, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+		return symbol
+	}
+	return `"` + symbol + `"`
+}
+
+// securityCommodity returns the ISIN of the instrument, or its WKN prefixed with WKN, as commodities
+// must start with a letter in beancount.
+func securityCommodity(instrument comdirect.Instrument) string {
+	if instrument.ISIN != "" {
+		return instrument.ISIN
+	}
+	return "WKN" + instrument.WKN
+}
+
+// beancountString returns s as a quoted beancount string.
+func beancountString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(singleLine(s)) + `"`
+}
+
+// singleLine replaces line breaks and repeated whitespace in s by single spaces.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
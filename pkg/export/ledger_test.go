@@ -0,0 +1,124 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+	"github.com/jsattler/go-comdirect/pkg/export"
+)
+
+const testRules = `
+accounts:
+  DE89 3704 0044 0532 0130 00: Assets:Bank:Giro
+  ` + comdirecttest.AccountID + `: Assets:Bank:Giro
+  ` + comdirecttest.DepotID + `: Assets:Bank:Depot
+rules:
+  - payee: supermarkt
+    type: DIRECT_DEBIT
+    account: Expenses:Groceries
+  - remittance: miete
+    account: Expenses:Rent
+  - payee: arbeitgeber|gehalt
+    account: Income:Salary
+`
+
+func TestJournal_WriteStatement(t *testing.T) {
+	rules, err := export.ParseRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("failed to parse rules: %s", err)
+	}
+	for _, format := range []export.JournalFormat{export.FormatLedger, export.FormatHLedger, export.FormatBeancount} {
+		t.Run(string(format), func(t *testing.T) {
+			journal, err := export.NewJournal(format, rules)
+			if err != nil {
+				t.Fatalf("failed to create journal: %s", err)
+			}
+			var buf bytes.Buffer
+			if err = journal.WriteStatement(&buf, testStatement(t)); err != nil {
+				t.Fatalf("failed to write statement: %s", err)
+			}
+			assertGolden(t, "statement."+string(format), buf.Bytes())
+		})
+	}
+}
+
+func TestJournal_WriteDepotTransactions(t *testing.T) {
+	rules, err := export.ParseRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("failed to parse rules: %s", err)
+	}
+	fixtures := comdirecttest.DefaultFixtures()
+	buy := fixtures.DepotTransactions[comdirecttest.DepotID][0]
+	sell := buy
+	sell.TransactionID = "T0000002"
+	sell.BookingDate = comdirect.NewDate(2024, time.March, 27)
+	sell.TransactionDirection = "OUT"
+	sell.TransactionType = "SELL"
+	sell.Quantity.Value = comdirect.NewDecimal(4, 0)
+	sell.ExecutionPrice.Value = comdirect.MustParseDecimal("170.5")
+	sell.TransactionValue.Value = comdirect.MustParseDecimal("672.10")
+	transactions := []comdirect.DepotTransaction{buy, sell}
+
+	for _, format := range []export.JournalFormat{export.FormatLedger, export.FormatHLedger, export.FormatBeancount} {
+		t.Run(string(format), func(t *testing.T) {
+			journal, err := export.NewJournal(format, rules)
+			if err != nil {
+				t.Fatalf("failed to create journal: %s", err)
+			}
+			var buf bytes.Buffer
+			if err = journal.WriteDepotTransactions(&buf, fixtures.Depots[0], transactions); err != nil {
+				t.Fatalf("failed to write depot transactions: %s", err)
+			}
+			assertGolden(t, "depot."+string(format), buf.Bytes())
+		})
+	}
+}
+
+func TestNewJournal(t *testing.T) {
+	if _, err := export.NewJournal("gnucash", nil); !errors.Is(err, export.ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got: %v", err)
+	}
+	journal, err := export.NewJournal(export.FormatLedger, nil)
+	if err != nil {
+		t.Fatalf("failed to create journal without rules: %s", err)
+	}
+	var buf bytes.Buffer
+	if err = journal.WriteStatement(&buf, testStatement(t)); err != nil {
+		t.Fatalf("failed to write statement: %s", err)
+	}
+	if !strings.Contains(buf.String(), "Expenses:Unknown") || !strings.Contains(buf.String(), "Income:Unknown") {
+		t.Errorf("expected default accounts:\n%s", buf.String())
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":        "expense: Expenses:Misc\n",
+		"missing account":    "rules:\n  - payee: x\n",
+		"missing condition":  "rules:\n  - account: Expenses:Misc\n",
+		"invalid regexp":     "rules:\n  - payee: '('\n    account: Expenses:Misc\n",
+		"invalid IBAN":       "rules:\n  - iban: DE00123\n    account: Expenses:Misc\n",
+		"empty account name": "accounts:\n  DE89370400440532013000: ''\n",
+	}
+	for name, rules := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := export.ParseRules(strings.NewReader(rules)); !errors.Is(err, export.ErrInvalidRules) {
+				t.Errorf("expected ErrInvalidRules, got: %v", err)
+			}
+		})
+	}
+
+	rules, err := export.ParseRules(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("failed to parse empty rules: %s", err)
+	}
+	debit := comdirect.AccountTransaction{Amount: comdirect.AmountValue{Value: comdirect.NewDecimal(-1, 0), Unit: "EUR"}}
+	if account := rules.Match(debit); account != "Expenses:Unknown" {
+		t.Errorf("expected Expenses:Unknown, got %q", account)
+	}
+}
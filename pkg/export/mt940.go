@@ -0,0 +1,194 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+const (
+	// mt940LineLength is the maximum length of a line of an MT940 message.
+	mt940LineLength = 65
+	// mt940SubfieldLength is the maximum length of a subfield of the structured field :86:.
+	mt940SubfieldLength = 27
+	// mt940RemittanceSubfields is the number of remittance info subfields ?20 to ?29 of the field :86:.
+	mt940RemittanceSubfields = 10
+)
+
+// mt940Replacer transliterates German umlauts, which are not part of the SWIFT character set.
+var mt940Replacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss")
+
+// WriteMT940 writes the Statement as SWIFT MT940 customer statement in the variant used by German banks,
+// with a structured field :86: holding the business transaction code (GVC), booking text, remittance info
+// and the counterparty. Lines are terminated by CRLF, characters outside the SWIFT character set are replaced.
+func WriteMT940(w io.Writer, s *Statement) error {
+	bw := bufio.NewWriter(w)
+	writeLine := func(format string, args ...any) {
+		fmt.Fprintf(bw, format+"\r\n", args...)
+	}
+
+	writeLine(":20:%s", truncate(mt940Text(s.ID), 16))
+	writeLine(":25:%s", mt940AccountID(s.Account.Iban))
+	writeLine(":28C:%05d/001", max(s.Number, 1))
+	writeLine(":60F:%s", mt940Balance(s.OpeningBalance, s.From, s.currency()))
+	for _, t := range s.Transactions {
+		writeLine(":61:%s", mt940StatementLine(t))
+		for i, line := range wrap(":86:"+mt940Details(t), mt940LineLength) {
+			if i == 6 {
+				break
+			}
+			writeLine("%s", line)
+		}
+	}
+	writeLine(":62F:%s", mt940Balance(s.ClosingBalance, s.To, s.currency()))
+	writeLine("-")
+	return bw.Flush()
+}
+
+// mt940AccountID returns the bank code and account number of a German IBAN, e.g. 37040044/532013000,
+// or the IBAN itself for other countries.
+func mt940AccountID(s string) string {
+	bankCode, accountNumber, err := iban.German(s)
+	if err != nil {
+		return iban.Normalize(s)
+	}
+	return bankCode + "/" + accountNumber
+}
+
+// mt940Balance formats a balance as credit/debit mark, date, currency and amount, e.g. C240328EUR2500,25.
+func mt940Balance(amount comdirect.AmountValue, date comdirect.Date, currency string) string {
+	mark := "C"
+	if amount.Value.Sign() < 0 {
+		mark = "D"
+	}
+	if amount.Unit != "" {
+		currency = amount.Unit
+	}
+	return mark + date.Time().Format("060102") + currency + mt940Amount(amount.Value)
+}
+
+// mt940StatementLine formats the field :61: of a transaction: value date, entry date, debit/credit mark, amount,
+// transaction type, customer reference and bank reference.
+func mt940StatementLine(t comdirect.AccountTransaction) string {
+	valuta := t.ValutaDate
+	if valuta.IsZero() {
+		valuta = t.BookingDate
+	}
+	mark := "C"
+	if t.Amount.Value.Sign() < 0 {
+		mark = "D"
+	}
+	customerReference := "NONREF"
+	if ref := mt940Text(t.EndToEndReference); ref != "" && len(ref) <= 16 {
+		customerReference = ref
+	}
+	line := valuta.Time().Format("060102") + t.BookingDate.Time().Format("0102") + mark + mt940Amount(t.Amount.Value) +
+		"N" + mt940TransactionType(t) + customerReference
+	if t.Reference != "" {
+		line += "//" + truncate(mt940Text(t.Reference), 16)
+	}
+	return line
+}
+
+// mt940Details formats the structured field :86: of a transaction.
+func mt940Details(t comdirect.AccountTransaction) string {
+	var b strings.Builder
+	b.WriteString(mt940GVC(t))
+	writeSubfield := func(id int, value string) {
+		if value = mt940Text(value); value != "" {
+			fmt.Fprintf(&b, "?%02d%s", id, truncate(value, mt940SubfieldLength))
+		}
+	}
+
+	writeSubfield(0, t.TransactionType.Text)
+	var remittance []string
+	for _, line := range RemittanceLines(t.RemittanceInfo) {
+		remittance = append(remittance, wrap(mt940Text(line), mt940SubfieldLength)...)
+	}
+	if t.EndToEndReference != "" {
+		remittance = append(remittance, wrap("EREF+"+mt940Text(t.EndToEndReference), mt940SubfieldLength)...)
+	}
+	if t.DirectDebitMandateID != "" {
+		remittance = append(remittance, wrap("MREF+"+mt940Text(t.DirectDebitMandateID), mt940SubfieldLength)...)
+	}
+	if t.DirectDebitCreditorID != "" {
+		remittance = append(remittance, wrap("CRED+"+mt940Text(t.DirectDebitCreditorID), mt940SubfieldLength)...)
+	}
+	for i, line := range remittance {
+		if i == mt940RemittanceSubfields {
+			break
+		}
+		writeSubfield(20+i, line)
+	}
+
+	name, accountIBAN, bic := counterparty(t)
+	writeSubfield(30, bic)
+	writeSubfield(31, accountIBAN)
+	names := wrap(mt940Text(name), mt940SubfieldLength)
+	for i := 0; i < len(names) && i < 2; i++ {
+		writeSubfield(32+i, names[i])
+	}
+	return b.String()
+}
+
+// mt940TransactionType returns the SWIFT transaction type identification code of a transaction.
+func mt940TransactionType(t comdirect.AccountTransaction) string {
+	switch t.TransactionType.Key {
+	case "DIRECT_DEBIT":
+		return "DDT"
+	case "CARD_TRANSACTION":
+		return "MSC"
+	default:
+		return "TRF"
+	}
+}
+
+// mt940GVC returns the German business transaction code (Geschäftsvorfallcode) of a transaction.
+func mt940GVC(t comdirect.AccountTransaction) string {
+	switch {
+	case t.TransactionType.Key == "DIRECT_DEBIT":
+		return "105"
+	case t.TransactionType.Key == "CARD_TRANSACTION":
+		return "106"
+	case t.Amount.Value.Sign() < 0:
+		return "116"
+	default:
+		return "166"
+	}
+}
+
+func mt940Amount(d comdirect.Decimal) string {
+	return d.Abs().Round(2).Format(comdirect.Locale{DecimalSeparator: ","})
+}
+
+// mt940Text transliterates s to the SWIFT character set. Characters without transliteration and the
+// subfield separator ? are replaced by a dot.
+func mt940Text(s string) string {
+	s = mt940Replacer.Replace(strings.TrimSpace(s))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("/-:().,'+ ", r):
+			return r
+		default:
+			return '.'
+		}
+	}, s)
+}
+
+// wrap splits s into lines of at most n characters.
+func wrap(s string, n int) []string {
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > 0 {
+		line := runes[:min(n, len(runes))]
+		runes = runes[len(line):]
+		lines = append(lines, string(line))
+	}
+	return lines
+}
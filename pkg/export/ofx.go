@@ -0,0 +1,422 @@
+package export
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+// ofxHeader is the processing instruction of OFX 2.2 documents.
+const ofxHeader = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBrokerID identifies comdirect as broker of investment accounts.
+const ofxBrokerID = "comdirect.de"
+
+const (
+	maxOFXNameLength = 32
+	maxOFXMemoLength = 255
+)
+
+// WriteOFX writes the Statement as OFX 2.2 bank statement. The FITID of a transaction is derived from its
+// reference, see FITID.
+func WriteOFX(w io.Writer, s *Statement) error {
+	bankID, accountID := s.bic(), s.Account.Iban
+	if code, number, err := iban.German(s.Account.Iban); err == nil {
+		bankID, accountID = code, number
+	}
+	accountType := "CHECKING"
+	if s.Account.AccountType.Key == "TG" || strings.Contains(strings.ToUpper(s.Account.AccountType.Text), "TAGESGELD") {
+		accountType = "SAVINGS"
+	}
+
+	statement := &ofxBankResponse{
+		TrnUID: s.ID,
+		Status: ofxOK,
+		StmtRs: ofxStatement{
+			CurDef:       s.currency(),
+			BankAcctFrom: ofxBankAccount{BankID: bankID, AcctID: accountID, AcctType: accountType},
+			BankTranList: ofxBankTransactions{
+				DtStart: ofxDate(s.From),
+				DtEnd:   ofxDate(s.To.AddDays(1)),
+			},
+			LedgerBal: ofxBalance{BalAmt: s.ClosingBalance.Value.String(), DtAsOf: ofxDate(s.To)},
+		},
+	}
+	for _, t := range s.Transactions {
+		name, _, _ := counterparty(t)
+		transaction := ofxBankTransaction{
+			TrnType:  ofxTransactionType(t),
+			DtPosted: ofxDate(t.BookingDate),
+			TrnAmt:   t.Amount.Value.String(),
+			FITID:    FITID(t),
+			Name:     truncate(singleLine(cmp.Or(name, t.TransactionType.Text)), maxOFXNameLength),
+			Memo:     truncate(strings.Join(RemittanceLines(t.RemittanceInfo), " "), maxOFXMemoLength),
+		}
+		if !t.ValutaDate.IsZero() {
+			transaction.DtUser = ofxDate(t.ValutaDate)
+		}
+		statement.StmtRs.BankTranList.StmtTrn = append(statement.StmtRs.BankTranList.StmtTrn, transaction)
+	}
+	return writeOFX(w, ofxDocument{SignOn: newOFXSignOn(s.CreatedAt), Bank: statement})
+}
+
+// WriteInvestmentOFX writes the InvestmentStatement as OFX 2.2 investment statement with the transactions
+// and positions of the depot and the list of their securities. Securities are identified by their ISIN.
+func WriteInvestmentOFX(w io.Writer, s *InvestmentStatement) error {
+	currency := "EUR"
+	statement := &ofxInvestmentResponse{
+		TrnUID: s.ID,
+		Status: ofxOK,
+		InvStmtRs: ofxInvestmentStatement{
+			DtAsOf:      ofxTime(s.CreatedAt),
+			CurDef:      currency,
+			InvAcctFrom: ofxInvestmentAccount{BrokerID: ofxBrokerID, AcctID: cmp.Or(s.Depot.DepotDisplayId, s.Depot.DepotId)},
+		},
+	}
+	securities := &ofxSecurityList{}
+	seen := make(map[string]bool)
+	addSecurity := func(instrument comdirect.Instrument) ofxSecurityID {
+		id := newOFXSecurityID(instrument)
+		if !seen[id.UniqueID] {
+			seen[id.UniqueID] = true
+			securities.Securities = append(securities.Securities, ofxSecurity{
+				XMLName: xml.Name{Local: ofxSecurityKind(instrument) + "INFO"},
+				SecInfo: ofxSecurityInfo{
+					SecID:   id,
+					SecName: truncate(cmp.Or(instrument.Name, instrument.ShortName, instrument.WKN), 120),
+					Ticker:  instrument.Mnemonic,
+				},
+			})
+		}
+		return id
+	}
+
+	if len(s.Transactions) > 0 {
+		list := &ofxInvestmentTransactions{
+			DtStart: ofxDate(tradeDate(s.Transactions[0])),
+			DtEnd:   ofxDate(tradeDate(s.Transactions[len(s.Transactions)-1]).AddDays(1)),
+		}
+		for _, t := range s.Transactions {
+			list.Transactions = append(list.Transactions, newOFXInvestmentTransaction(t, addSecurity(t.Instrument)))
+		}
+		statement.InvStmtRs.InvTranList = list
+	}
+	if len(s.Positions) > 0 {
+		list := &ofxPositions{}
+		for _, p := range s.Positions {
+			instrument := p.Instrument
+			if instrument.WKN == "" && instrument.ISIN == "" {
+				instrument.WKN = p.Wkn
+			}
+			priceDate := p.CurrentPrice.PriceDateTime.Time()
+			if priceDate.IsZero() {
+				priceDate = s.CreatedAt
+			}
+			list.Positions = append(list.Positions, ofxPosition{
+				XMLName: xml.Name{Local: "POS" + ofxSecurityKind(instrument)},
+				InvPos: ofxInvestmentPosition{
+					SecID:       addSecurity(instrument),
+					HeldInAcct:  "CASH",
+					PosType:     "LONG",
+					Units:       p.Quantity.Value.String(),
+					UnitPrice:   p.CurrentPrice.Price.Value.String(),
+					MktVal:      p.CurrentValue.Value.String(),
+					DtPriceAsOf: ofxTime(priceDate),
+				},
+			})
+		}
+		statement.InvStmtRs.InvPosList = list
+	}
+
+	doc := ofxDocument{SignOn: newOFXSignOn(s.CreatedAt), Investment: statement}
+	if len(securities.Securities) > 0 {
+		doc.SecList = securities
+	}
+	return writeOFX(w, doc)
+}
+
+func writeOFX(w io.Writer, doc ofxDocument) error {
+	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+"\n"+ofxHeader); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newOFXSignOn(t time.Time) ofxSignOn {
+	return ofxSignOn{Status: ofxOK, DtServer: ofxTime(t), Language: "GER", FI: ofxFI{Org: "comdirect bank AG"}}
+}
+
+func newOFXInvestmentTransaction(t comdirect.DepotTransaction, secID ofxSecurityID) ofxInvestmentTransaction {
+	quantity, cash, fees := tradeAmounts(t)
+	invTran := ofxInvestmentTran{
+		FITID:   t.TransactionID,
+		DtTrade: ofxDate(tradeDate(t)),
+		Memo:    t.TransactionType,
+	}
+	if !t.BusinessDate.IsZero() && !t.BookingDate.IsZero() {
+		invTran.DtTrade, invTran.DtSettle = ofxDate(t.BusinessDate), ofxDate(t.BookingDate)
+	}
+	kind := ofxSecurityKind(t.Instrument)
+
+	if t.TransactionType != "BUY" && t.TransactionType != "SELL" {
+		action := "IN"
+		if quantity.Sign() < 0 {
+			action = "OUT"
+		}
+		return ofxInvestmentTransaction{
+			XMLName:    xml.Name{Local: "TRANSFER"},
+			InvTran:    &invTran,
+			SecID:      &secID,
+			SubAcctSec: "CASH",
+			Units:      quantity.String(),
+			TferAction: action,
+			PosType:    "LONG",
+		}
+	}
+
+	trade := &ofxInvestmentTrade{
+		InvTran:     invTran,
+		SecID:       secID,
+		Units:       quantity.String(),
+		UnitPrice:   t.ExecutionPrice.Value.String(),
+		Total:       cash.String(),
+		SubAcctSec:  "CASH",
+		SubAcctFund: "CASH",
+	}
+	if !fees.IsZero() {
+		trade.Commission = fees.String()
+	}
+	if quantity.Sign() < 0 {
+		transaction := ofxInvestmentTransaction{XMLName: xml.Name{Local: "SELL" + kind}, Sell: trade}
+		if kind != "OTHER" {
+			transaction.SellType = "SELL"
+		}
+		return transaction
+	}
+	transaction := ofxInvestmentTransaction{XMLName: xml.Name{Local: "BUY" + kind}, Buy: trade}
+	if kind != "OTHER" {
+		transaction.BuyType = "BUY"
+	}
+	return transaction
+}
+
+// ofxTransactionType returns the OFX transaction type of an account transaction.
+func ofxTransactionType(t comdirect.AccountTransaction) string {
+	switch {
+	case t.TransactionType.Key == "DIRECT_DEBIT":
+		return "DIRECTDEBIT"
+	case t.TransactionType.Key == "STANDING_ORDER":
+		return "REPEATPMT"
+	case t.TransactionType.Key == "CARD_TRANSACTION":
+		return "POS"
+	case t.Amount.Value.Sign() < 0:
+		return "DEBIT"
+	}
+	return "CREDIT"
+}
+
+// ofxSecurityKind returns the OFX security type of the instrument: STOCK, MF or OTHER.
+func ofxSecurityKind(instrument comdirect.Instrument) string {
+	switch instrument.StaticData.InstrumentType {
+	case "SHARE":
+		return "STOCK"
+	case "FUND", "ETF":
+		return "MF"
+	}
+	return "OTHER"
+}
+
+func newOFXSecurityID(instrument comdirect.Instrument) ofxSecurityID {
+	if instrument.ISIN != "" {
+		return ofxSecurityID{UniqueID: instrument.ISIN, UniqueIDType: "ISIN"}
+	}
+	return ofxSecurityID{UniqueID: instrument.WKN, UniqueIDType: "WKN"}
+}
+
+func ofxDate(d comdirect.Date) string {
+	return d.Time().Format("20060102")
+}
+
+// ofxTime formats t with its offset and time zone, e.g. 20240329080000.000[+1:CET].
+func ofxTime(t time.Time) string {
+	t = t.In(comdirect.Location)
+	name, offset := t.Zone()
+	return fmt.Sprintf("%s[%+d:%s]", t.Format("20060102150405.000"), offset/3600, name)
+}
+
+var ofxOK = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxDocument struct {
+	XMLName    xml.Name               `xml:"OFX"`
+	SignOn     ofxSignOn              `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank       *ofxBankResponse       `xml:"BANKMSGSRSV1>STMTTRNRS"`
+	Investment *ofxInvestmentResponse `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS"`
+	SecList    *ofxSecurityList       `xml:"SECLISTMSGSRSV1>SECLIST"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DtServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+	FI       ofxFI     `xml:"FI"`
+}
+
+type ofxFI struct {
+	Org string `xml:"ORG"`
+}
+
+type ofxBankResponse struct {
+	TrnUID string       `xml:"TRNUID"`
+	Status ofxStatus    `xml:"STATUS"`
+	StmtRs ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	CurDef       string              `xml:"CURDEF"`
+	BankAcctFrom ofxBankAccount      `xml:"BANKACCTFROM"`
+	BankTranList ofxBankTransactions `xml:"BANKTRANLIST"`
+	LedgerBal    ofxBalance          `xml:"LEDGERBAL"`
+}
+
+type ofxBankAccount struct {
+	BankID   string `xml:"BANKID"`
+	AcctID   string `xml:"ACCTID"`
+	AcctType string `xml:"ACCTTYPE"`
+}
+
+type ofxBankTransactions struct {
+	DtStart string               `xml:"DTSTART"`
+	DtEnd   string               `xml:"DTEND"`
+	StmtTrn []ofxBankTransaction `xml:"STMTTRN"`
+}
+
+type ofxBankTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DtPosted string `xml:"DTPOSTED"`
+	DtUser   string `xml:"DTUSER,omitempty"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DtAsOf string `xml:"DTASOF"`
+}
+
+type ofxInvestmentResponse struct {
+	TrnUID    string                 `xml:"TRNUID"`
+	Status    ofxStatus              `xml:"STATUS"`
+	InvStmtRs ofxInvestmentStatement `xml:"INVSTMTRS"`
+}
+
+type ofxInvestmentStatement struct {
+	DtAsOf      string                     `xml:"DTASOF"`
+	CurDef      string                     `xml:"CURDEF"`
+	InvAcctFrom ofxInvestmentAccount       `xml:"INVACCTFROM"`
+	InvTranList *ofxInvestmentTransactions `xml:"INVTRANLIST"`
+	InvPosList  *ofxPositions              `xml:"INVPOSLIST"`
+}
+
+type ofxInvestmentAccount struct {
+	BrokerID string `xml:"BROKERID"`
+	AcctID   string `xml:"ACCTID"`
+}
+
+type ofxInvestmentTransactions struct {
+	DtStart      string                     `xml:"DTSTART"`
+	DtEnd        string                     `xml:"DTEND"`
+	Transactions []ofxInvestmentTransaction `xml:",any"`
+}
+
+// ofxInvestmentTransaction is one of BUY*, SELL* or TRANSFER depending on XMLName.
+type ofxInvestmentTransaction struct {
+	XMLName    xml.Name
+	Buy        *ofxInvestmentTrade `xml:"INVBUY"`
+	Sell       *ofxInvestmentTrade `xml:"INVSELL"`
+	InvTran    *ofxInvestmentTran  `xml:"INVTRAN"`
+	SecID      *ofxSecurityID      `xml:"SECID"`
+	SubAcctSec string              `xml:"SUBACCTSEC,omitempty"`
+	Units      string              `xml:"UNITS,omitempty"`
+	TferAction string              `xml:"TFERACTION,omitempty"`
+	PosType    string              `xml:"POSTYPE,omitempty"`
+	BuyType    string              `xml:"BUYTYPE,omitempty"`
+	SellType   string              `xml:"SELLTYPE,omitempty"`
+}
+
+type ofxInvestmentTrade struct {
+	InvTran     ofxInvestmentTran `xml:"INVTRAN"`
+	SecID       ofxSecurityID     `xml:"SECID"`
+	Units       string            `xml:"UNITS"`
+	UnitPrice   string            `xml:"UNITPRICE"`
+	Commission  string            `xml:"COMMISSION,omitempty"`
+	Total       string            `xml:"TOTAL"`
+	SubAcctSec  string            `xml:"SUBACCTSEC"`
+	SubAcctFund string            `xml:"SUBACCTFUND"`
+}
+
+type ofxInvestmentTran struct {
+	FITID    string `xml:"FITID"`
+	DtTrade  string `xml:"DTTRADE"`
+	DtSettle string `xml:"DTSETTLE,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxSecurityID struct {
+	UniqueID     string `xml:"UNIQUEID"`
+	UniqueIDType string `xml:"UNIQUEIDTYPE"`
+}
+
+type ofxPositions struct {
+	Positions []ofxPosition `xml:",any"`
+}
+
+// ofxPosition is one of POSSTOCK, POSMF or POSOTHER depending on XMLName.
+type ofxPosition struct {
+	XMLName xml.Name
+	InvPos  ofxInvestmentPosition `xml:"INVPOS"`
+}
+
+type ofxInvestmentPosition struct {
+	SecID       ofxSecurityID `xml:"SECID"`
+	HeldInAcct  string        `xml:"HELDINACCT"`
+	PosType     string        `xml:"POSTYPE"`
+	Units       string        `xml:"UNITS"`
+	UnitPrice   string        `xml:"UNITPRICE"`
+	MktVal      string        `xml:"MKTVAL"`
+	DtPriceAsOf string        `xml:"DTPRICEASOF"`
+}
+
+type ofxSecurityList struct {
+	Securities []ofxSecurity `xml:",any"`
+}
+
+// ofxSecurity is one of STOCKINFO, MFINFO or OTHERINFO depending on XMLName.
+type ofxSecurity struct {
+	XMLName xml.Name
+	SecInfo ofxSecurityInfo `xml:"SECINFO"`
+}
+
+type ofxSecurityInfo struct {
+	SecID   ofxSecurityID `xml:"SECID"`
+	SecName string        `xml:"SECNAME"`
+	Ticker  string        `xml:"TICKER,omitempty"`
+}
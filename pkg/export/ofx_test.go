@@ -0,0 +1,107 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+	"github.com/jsattler/go-comdirect/pkg/export"
+)
+
+func TestWriteOFX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteOFX(&buf, testStatement(t)); err != nil {
+		t.Fatalf("failed to write OFX: %s", err)
+	}
+	assertGolden(t, "statement.ofx", buf.Bytes())
+
+	var doc struct {
+		Transactions []struct {
+			FITID  string `xml:"FITID"`
+			Amount string `xml:"TRNAMT"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>BANKTRANLIST>STMTTRN"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected well-formed XML: %s", err)
+	}
+	if len(doc.Transactions) != 4 || doc.Transactions[0].FITID != "3C2K00000026/1" || doc.Transactions[0].Amount != "-54.10" {
+		t.Errorf("unexpected transactions: %+v", doc.Transactions)
+	}
+}
+
+func TestWriteInvestmentOFX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteInvestmentOFX(&buf, testInvestmentStatement()); err != nil {
+		t.Fatalf("failed to write OFX: %s", err)
+	}
+	assertGolden(t, "depot.ofx", buf.Bytes())
+
+	var doc struct {
+		Buys      []string `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS>INVSTMTRS>INVTRANLIST>BUYSTOCK>INVBUY>UNITS"`
+		Sells     []string `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS>INVSTMTRS>INVTRANLIST>SELLSTOCK>INVSELL>UNITS"`
+		Positions []string `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS>INVSTMTRS>INVPOSLIST>POSSTOCK>INVPOS>SECID>UNIQUEID"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected well-formed XML: %s", err)
+	}
+	if len(doc.Buys) != 1 || len(doc.Sells) != 1 || doc.Sells[0] != "-4" || len(doc.Positions) != 1 || doc.Positions[0] != "US0378331005" {
+		t.Errorf("unexpected investment statement: %+v", doc)
+	}
+}
+
+func TestWriteQIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteQIF(&buf, testStatement(t)); err != nil {
+		t.Fatalf("failed to write QIF: %s", err)
+	}
+	assertGolden(t, "statement.qif", buf.Bytes())
+
+	buf.Reset()
+	if err := export.WriteInvestmentQIF(&buf, testInvestmentStatement()); err != nil {
+		t.Fatalf("failed to write QIF: %s", err)
+	}
+	assertGolden(t, "depot.qif", buf.Bytes())
+}
+
+func TestFITID(t *testing.T) {
+	transaction := comdirecttest.DefaultFixtures().Transactions[comdirecttest.AccountID][1]
+	if id := export.FITID(transaction); id != transaction.Reference {
+		t.Errorf("expected reference %q as FITID, got %q", transaction.Reference, id)
+	}
+
+	transaction.Reference = ""
+	id := export.FITID(transaction)
+	if id == "" || id != export.FITID(transaction) {
+		t.Errorf("expected stable FITID without reference, got %q", id)
+	}
+	transaction.Amount.Value = transaction.Amount.Value.Add(comdirect.NewDecimal(1, 2))
+	if export.FITID(transaction) == id {
+		t.Errorf("expected different FITID for different transactions")
+	}
+}
+
+// testInvestmentStatement returns a statement of the depot of the default fixtures with a buy and a sale.
+func testInvestmentStatement() *export.InvestmentStatement {
+	fixtures := comdirecttest.DefaultFixtures()
+	buy := fixtures.DepotTransactions[comdirecttest.DepotID][0]
+	sell := buy
+	sell.TransactionID = "T0000002"
+	sell.BookingDate = comdirect.NewDate(2024, time.March, 27)
+	sell.BusinessDate = comdirect.NewDate(2024, time.March, 25)
+	sell.TransactionDirection = "OUT"
+	sell.TransactionType = "SELL"
+	sell.Quantity.Value = comdirect.NewDecimal(4, 0)
+	sell.ExecutionPrice.Value = comdirect.MustParseDecimal("170.5")
+	sell.TransactionValue.Value = comdirect.MustParseDecimal("672.10")
+	pending := sell
+	pending.BookingStatus = "NOTBOOKED"
+
+	statement := export.NewInvestmentStatement(fixtures.Depots[0], fixtures.Positions[comdirecttest.DepotID],
+		[]comdirect.DepotTransaction{sell, buy, pending})
+	statement.ID = "INV20240329"
+	statement.CreatedAt = time.Date(2024, time.March, 29, 8, 0, 0, 0, comdirect.Location)
+	return statement
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// qifDateLayout is the date format of QIF files, the US format is understood by most finance tools.
+const qifDateLayout = "01/02/2006"
+
+// WriteQIF writes the transactions of the Statement as QIF bank account. QIF has no transaction IDs,
+// so use OFX if transactions are imported repeatedly.
+func WriteQIF(w io.Writer, s *Statement) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "!Type:Bank")
+	for _, t := range s.Transactions {
+		name, _, _ := counterparty(t)
+		fmt.Fprintf(bw, "D%s\n", t.BookingDate.Time().Format(qifDateLayout))
+		fmt.Fprintf(bw, "T%s\n", t.Amount.Value.String())
+		fmt.Fprintln(bw, "C*")
+		writeQIFField(bw, 'N', t.Reference)
+		writeQIFField(bw, 'P', cmp.Or(name, t.TransactionType.Text))
+		writeQIFField(bw, 'M', strings.Join(RemittanceLines(t.RemittanceInfo), " "))
+		fmt.Fprintln(bw, "^")
+	}
+	return bw.Flush()
+}
+
+// WriteInvestmentQIF writes the transactions of the InvestmentStatement as QIF investment account.
+// Securities are named by their ISIN, positions are not part of QIF.
+func WriteInvestmentQIF(w io.Writer, s *InvestmentStatement) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "!Type:Invst")
+	for _, t := range s.Transactions {
+		quantity, cash, fees := tradeAmounts(t)
+		action := "ShrsIn"
+		switch {
+		case t.TransactionType == "BUY":
+			action = "Buy"
+		case t.TransactionType == "SELL":
+			action = "Sell"
+		case quantity.Sign() < 0:
+			action = "ShrsOut"
+		}
+		fmt.Fprintf(bw, "D%s\n", tradeDate(t).Time().Format(qifDateLayout))
+		fmt.Fprintf(bw, "N%s\n", action)
+		writeQIFField(bw, 'Y', securityCommodity(t.Instrument))
+		if !t.ExecutionPrice.Value.IsZero() {
+			fmt.Fprintf(bw, "I%s\n", t.ExecutionPrice.Value.String())
+		}
+		fmt.Fprintf(bw, "Q%s\n", quantity.Abs().String())
+		if action == "Buy" || action == "Sell" {
+			fmt.Fprintf(bw, "T%s\n", cash.Abs().String())
+			if !fees.IsZero() {
+				fmt.Fprintf(bw, "O%s\n", fees.String())
+			}
+		}
+		writeQIFField(bw, 'M', strings.TrimSpace(cmp.Or(t.Instrument.Name, t.Instrument.ShortName)+" "+t.TransactionID))
+		fmt.Fprintln(bw, "^")
+	}
+	return bw.Flush()
+}
+
+// writeQIFField writes a QIF field on a single line, empty values are omitted.
+func writeQIFField(w io.Writer, code byte, value string) {
+	if value = singleLine(value); value != "" {
+		fmt.Fprintf(w, "%c%s\n", code, value)
+	}
+}
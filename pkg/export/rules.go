@@ -0,0 +1,177 @@
+package export
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/iban"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidRules is returned if Rules cannot be used, e.g. because of an invalid regular expression.
+var ErrInvalidRules = errors.New("export: invalid rules")
+
+// Rules map comdirect accounts and the counterparties of transactions to the accounts of a journal.
+// Rules are usually loaded from a YAML file with LoadRules:
+//
+//	accounts:
+//	  DE89370400440532013000: Assets:Bank:Giro
+//	expenses: Expenses:Unknown
+//	rules:
+//	  - payee: supermarkt
+//	    account: Expenses:Groceries
+//	  - iban: DE02120300000000202051
+//	    account: Expenses:Rent
+type Rules struct {
+	// Accounts maps comdirect account and depot IDs, display IDs and IBANs to journal accounts.
+	// Unmapped accounts are named Assets:Comdirect: followed by their display ID.
+	Accounts map[string]string `yaml:"accounts"`
+	// Expenses and Income are the accounts of payments and receipts that match no rule,
+	// they default to Expenses:Unknown and Income:Unknown.
+	Expenses string `yaml:"expenses"`
+	Income   string `yaml:"income"`
+	// Fees is the account of the difference between the value and the price of a depot transaction,
+	// defaults to Expenses:Fees.
+	Fees string `yaml:"fees"`
+	// Gains is the account of realized gains of sales in beancount, defaults to Income:Gains.
+	Gains string `yaml:"gains"`
+	// Equity is the account of depot transactions without payment, e.g. transfers of securities from
+	// another bank, defaults to Equity:Transfers.
+	Equity string `yaml:"equity"`
+	// Rules are matched in order, the first matching Rule determines the account of a transaction.
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule assigns an account to the transactions matching all of its non-empty conditions.
+type Rule struct {
+	// Payee is a regular expression matched case-insensitively against the name of the counterparty.
+	Payee string `yaml:"payee"`
+	// IBAN is the IBAN of the counterparty.
+	IBAN string `yaml:"iban"`
+	// Remittance is a regular expression matched case-insensitively against the remittance info.
+	Remittance string `yaml:"remittance"`
+	// Type is the key of the transaction type, e.g. DIRECT_DEBIT.
+	Type string `yaml:"type"`
+	// Account is the journal account of matching transactions, e.g. Expenses:Groceries.
+	Account string `yaml:"account"`
+
+	payee      *regexp.Regexp
+	remittance *regexp.Regexp
+}
+
+// LoadRules reads Rules from the YAML file at path.
+func LoadRules(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRules(f)
+}
+
+// ParseRules reads Rules in YAML from r. Unknown keys are rejected to detect typos.
+func ParseRules(r io.Reader) (*Rules, error) {
+	var rules Rules
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// Match returns the journal account of the counterparty of the transaction, that is the account of
+// the first matching Rule or the Expenses or Income account.
+func (r *Rules) Match(t comdirect.AccountTransaction) string {
+	name, counterpartyIBAN, _ := counterparty(t)
+	remittance := strings.Join(RemittanceLines(t.RemittanceInfo), " ")
+	for _, rule := range r.Rules {
+		switch {
+		case rule.payee != nil && !rule.payee.MatchString(name):
+		case rule.IBAN != "" && iban.Normalize(rule.IBAN) != iban.Normalize(counterpartyIBAN):
+		case rule.remittance != nil && !rule.remittance.MatchString(remittance):
+		case rule.Type != "" && !strings.EqualFold(rule.Type, t.TransactionType.Key):
+		default:
+			return rule.Account
+		}
+	}
+	if t.Amount.Value.Sign() < 0 {
+		return r.Expenses
+	}
+	return r.Income
+}
+
+// account returns the journal account mapped to one of the keys, e.g. the display ID, ID and IBAN of
+// an account, or Assets:Comdirect: followed by the first non-empty key.
+func (r *Rules) account(keys ...string) string {
+	fallback := ""
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if account, ok := r.Accounts[iban.Normalize(key)]; ok {
+			return account
+		}
+		if fallback == "" {
+			fallback = "Assets:Comdirect:" + key
+		}
+	}
+	return fallback
+}
+
+// compile validates the rules, compiles the regular expressions and sets the default accounts.
+func (r *Rules) compile() error {
+	accounts := make(map[string]string, len(r.Accounts))
+	for key, account := range r.Accounts {
+		if account == "" {
+			return fmt.Errorf("%w: missing journal account of %q", ErrInvalidRules, key)
+		}
+		accounts[iban.Normalize(key)] = account
+	}
+	r.Accounts = accounts
+	r.Expenses = cmp.Or(r.Expenses, "Expenses:Unknown")
+	r.Income = cmp.Or(r.Income, "Income:Unknown")
+	r.Fees = cmp.Or(r.Fees, "Expenses:Fees")
+	r.Gains = cmp.Or(r.Gains, "Income:Gains")
+	r.Equity = cmp.Or(r.Equity, "Equity:Transfers")
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Account == "" {
+			return fmt.Errorf("%w: rule %d has no account", ErrInvalidRules, i+1)
+		}
+		if rule.Payee == "" && rule.IBAN == "" && rule.Remittance == "" && rule.Type == "" {
+			return fmt.Errorf("%w: rule %d has no condition", ErrInvalidRules, i+1)
+		}
+		if rule.IBAN != "" {
+			if err := iban.Validate(rule.IBAN); err != nil {
+				return fmt.Errorf("%w: rule %d: %w", ErrInvalidRules, i+1, err)
+			}
+		}
+		var err error
+		if rule.payee, err = compileRegexp(rule.Payee); err != nil {
+			return fmt.Errorf("%w: rule %d: %w", ErrInvalidRules, i+1, err)
+		}
+		if rule.remittance, err = compileRegexp(rule.Remittance); err != nil {
+			return fmt.Errorf("%w: rule %d: %w", ErrInvalidRules, i+1, err)
+		}
+	}
+	return nil
+}
+
+// compileRegexp compiles a case-insensitive regular expression, an empty expression results in nil.
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + expr)
+}
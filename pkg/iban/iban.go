@@ -0,0 +1,145 @@
+// Package iban validates and formats International Bank Account Numbers (IBAN) according to ISO 13616
+// and Business Identifier Codes (BIC) according to ISO 9362.
+//
+// The functions accept IBANs in electronic format, e.g. DE02120300000000202051, as well as in paper
+// format with groups of four characters, e.g. DE02 1203 0000 0000 2020 51.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrFormat is returned if an IBAN contains invalid characters.
+	ErrFormat = errors.New("iban: invalid format")
+	// ErrCountry is returned if the country code of an IBAN is unknown.
+	ErrCountry = errors.New("iban: unknown country code")
+	// ErrLength is returned if an IBAN does not have the length defined for its country.
+	ErrLength = errors.New("iban: invalid length")
+	// ErrChecksum is returned if the mod-97 check digits of an IBAN are wrong, e.g. because of a typo.
+	ErrChecksum = errors.New("iban: invalid checksum")
+	// ErrNotGerman is returned by German if the IBAN is not a German IBAN.
+	ErrNotGerman = errors.New("iban: not a German IBAN")
+	// ErrBIC is returned if a BIC does not have the format defined by ISO 9362.
+	ErrBIC = errors.New("iban: invalid BIC")
+)
+
+// lengths are the lengths of the IBANs of the countries in the IBAN registry.
+var lengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HN": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26,
+	"IT": 27, "JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21,
+	"LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28,
+	"NL": 18, "NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22,
+	"RU": 33, "SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25,
+	"SV": 28, "TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// Normalize returns s in electronic format, i.e. without spaces and in upper case.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// Validate checks the format, the country-specific length and the mod-97 check digits of an IBAN.
+func Validate(s string) error {
+	s = Normalize(s)
+	if len(s) < 4 || !isUpper(s[0]) || !isUpper(s[1]) || !isDigit(s[2]) || !isDigit(s[3]) {
+		return fmt.Errorf("%w: %q", ErrFormat, s)
+	}
+	for i := 4; i < len(s); i++ {
+		if !isUpper(s[i]) && !isDigit(s[i]) {
+			return fmt.Errorf("%w: %q", ErrFormat, s)
+		}
+	}
+	length, ok := lengths[s[:2]]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrCountry, s[:2])
+	}
+	if len(s) != length {
+		return fmt.Errorf("%w: %s IBANs have %d characters, got %d", ErrLength, s[:2], length, len(s))
+	}
+	if mod97(s[4:]+s[:4]) != 1 {
+		return fmt.Errorf("%w: %q", ErrChecksum, s)
+	}
+	return nil
+}
+
+// Country returns the ISO 3166-1 country code of an IBAN, e.g. DE.
+func Country(s string) string {
+	s = Normalize(s)
+	if len(s) < 2 {
+		return ""
+	}
+	return s[:2]
+}
+
+// Format returns the IBAN s in paper format, i.e. in groups of four characters separated by spaces.
+func Format(s string) string {
+	s = Normalize(s)
+	var b strings.Builder
+	for i := 0; i < len(s); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s[i:min(i+4, len(s))])
+	}
+	return b.String()
+}
+
+// German returns the bank code (Bankleitzahl, BLZ) and the account number of a German IBAN.
+// Leading zeros of the account number are removed.
+func German(s string) (bankCode string, accountNumber string, err error) {
+	if err = Validate(s); err != nil {
+		return "", "", err
+	}
+	s = Normalize(s)
+	if s[:2] != "DE" {
+		return "", "", fmt.Errorf("%w: %q", ErrNotGerman, s)
+	}
+	accountNumber = strings.TrimLeft(s[12:], "0")
+	if accountNumber == "" {
+		accountNumber = "0"
+	}
+	return s[4:12], accountNumber, nil
+}
+
+// ValidateBIC checks the format of a BIC with 8 or 11 characters: a four letter institution code,
+// a two letter country code, a two character location code and an optional three character branch code.
+func ValidateBIC(s string) error {
+	s = Normalize(s)
+	if len(s) != 8 && len(s) != 11 {
+		return fmt.Errorf("%w: %q must have 8 or 11 characters", ErrBIC, s)
+	}
+	for i := 0; i < len(s); i++ {
+		if i < 6 && !isUpper(s[i]) || i >= 6 && !isUpper(s[i]) && !isDigit(s[i]) {
+			return fmt.Errorf("%w: %q", ErrBIC, s)
+		}
+	}
+	return nil
+}
+
+// mod97 returns the remainder of the division by 97 of the number that results from replacing
+// each letter of s with two digits, A = 10, B = 11, ..., Z = 35.
+func mod97(s string) int {
+	remainder := 0
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			remainder = (remainder*10 + int(s[i]-'0')) % 97
+		} else {
+			remainder = (remainder*100 + int(s[i]-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,91 @@
+package iban_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/iban"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"DE89370400440532013000",
+		"DE89 3704 0044 0532 0130 00",
+		"de89370400440532013000",
+		"DE02120300000000202051",
+		"DE02100100100006820101",
+		"GB82WEST12345698765432",
+		"NL91ABNA0417164300",
+		"BE68539007547034",
+		"NO9386011117947",
+		"FR1420041010050500013M02606",
+	}
+	for _, s := range valid {
+		if err := iban.Validate(s); err != nil {
+			t.Errorf("expected %q to be valid, got: %v", s, err)
+		}
+	}
+
+	invalid := map[string]error{
+		"":                            iban.ErrFormat,
+		"DE8937040044053201300!":      iban.ErrFormat,
+		"1289370400440532013000":      iban.ErrFormat,
+		"XX89370400440532013000":      iban.ErrCountry,
+		"DE8937040044053201300":       iban.ErrLength,
+		"DE893704004405320130000":     iban.ErrLength,
+		"DE89370400440532013001":      iban.ErrChecksum,
+		"DE89370400440523013000":      iban.ErrChecksum,
+		"DE98 3704 0044 0532 0130 00": iban.ErrChecksum,
+	}
+	for s, want := range invalid {
+		if err := iban.Validate(s); !errors.Is(err, want) {
+			t.Errorf("expected %v for %q, got: %v", want, s, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := map[string]string{
+		"DE89370400440532013000":      "DE89 3704 0044 0532 0130 00",
+		"de89 3704 0044 0532 0130 00": "DE89 3704 0044 0532 0130 00",
+		"NO9386011117947":             "NO93 8601 1117 947",
+		"":                            "",
+	}
+	for s, want := range tests {
+		if got := iban.Format(s); got != want {
+			t.Errorf("expected %q for %q, got %q", want, s, got)
+		}
+	}
+	if got := iban.Normalize(" DE89 3704 0044 0532 0130 00 "); got != "DE89370400440532013000" {
+		t.Errorf("unexpected normalized IBAN: %q", got)
+	}
+}
+
+func TestGerman(t *testing.T) {
+	bankCode, accountNumber, err := iban.German("DE89 3704 0044 0532 0130 00")
+	if err != nil {
+		t.Fatalf("failed to extract German account: %s", err)
+	}
+	if bankCode != "37040044" || accountNumber != "532013000" {
+		t.Errorf("unexpected bank code %q or account number %q", bankCode, accountNumber)
+	}
+	if _, _, err = iban.German("NL91ABNA0417164300"); !errors.Is(err, iban.ErrNotGerman) {
+		t.Errorf("expected ErrNotGerman, got: %v", err)
+	}
+	if _, _, err = iban.German("DE89370400440532013001"); !errors.Is(err, iban.ErrChecksum) {
+		t.Errorf("expected ErrChecksum, got: %v", err)
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	for _, s := range []string{"COBADEHD", "COBADEHDXXX", "BYLADEM1001", "deutdeff500"} {
+		if err := iban.ValidateBIC(s); err != nil {
+			t.Errorf("expected %q to be valid, got: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "COBADEH", "COBADEHDXX", "C0BADEHD", "COBA1EHD", "COBADEHD-XX"} {
+		if err := iban.ValidateBIC(s); !errors.Is(err, iban.ErrBIC) {
+			t.Errorf("expected ErrBIC for %q, got: %v", s, err)
+		}
+	}
+}
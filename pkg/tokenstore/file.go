@@ -0,0 +1,71 @@
+package tokenstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/jsattler/go-comdirect/internal/atomicfile"
+	"github.com/jsattler/go-comdirect/internal/secretbox"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// ErrWrongPassphrase is returned by File if the file cannot be decrypted with the passphrase.
+var ErrWrongPassphrase = secretbox.ErrDecrypt
+
+// File is a comdirect.TokenStore that stores the Authentication in a JSON file encrypted with a
+// passphrase. The key is derived with argon2id and the content is encrypted with AES-256-GCM.
+type File struct {
+	mu         sync.Mutex
+	path       string
+	passphrase []byte
+}
+
+// NewFile creates a File that stores the Authentication at path, encrypted with passphrase.
+func NewFile(path string, passphrase []byte) *File {
+	return &File{path: path, passphrase: passphrase}
+}
+
+func (f *File) Load(ctx context.Context) (*comdirect.Authentication, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, comdirect.ErrNoAuthentication
+	}
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := secretbox.Open(data, f.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return decode(plaintext)
+}
+
+func (f *File) Save(ctx context.Context, auth *comdirect.Authentication) error {
+	plaintext, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	data, err := secretbox.Seal(plaintext, f.passphrase)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return atomicfile.WriteFile(f.path, data)
+}
+
+func (f *File) Delete(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := os.Remove(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
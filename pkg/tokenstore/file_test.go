@@ -0,0 +1,80 @@
+package tokenstore_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/tokenstore"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "comdirect", "token.json")
+	store := tokenstore.NewFile(path, []byte("correct horse battery staple"))
+
+	if _, err := store.Load(ctx); !errors.Is(err, comdirect.ErrNoAuthentication) {
+		t.Fatalf("expected ErrNoAuthentication, got: %v", err)
+	}
+
+	auth := testAuthentication()
+	if err := store.Save(ctx, auth); err != nil {
+		t.Fatalf("save failed: %s", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected file mode 0600, got %s", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), auth.AccessToken().RefreshToken) {
+		t.Error("expected refresh token to be encrypted")
+	}
+
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("load failed: %s", err)
+	}
+	assertAuthentication(t, auth, loaded)
+
+	wrong := tokenstore.NewFile(path, []byte("wrong"))
+	if _, err = wrong.Load(ctx); !errors.Is(err, tokenstore.ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got: %v", err)
+	}
+
+	if err = store.Delete(ctx); err != nil {
+		t.Fatalf("delete failed: %s", err)
+	}
+	if err = store.Delete(ctx); err != nil {
+		t.Errorf("expected deleting a missing file to succeed: %s", err)
+	}
+	if _, err = store.Load(ctx); !errors.Is(err, comdirect.ErrNoAuthentication) {
+		t.Errorf("expected ErrNoAuthentication after delete, got: %v", err)
+	}
+}
+
+func testAuthentication() *comdirect.Authentication {
+	return comdirect.NewAuthentication(comdirect.AccessToken{
+		AccessToken:  "access-token",
+		TokenType:    "bearer",
+		RefreshToken: "refresh-token",
+		ExpiresIn:    599,
+		Scope:        "TWO_FACTOR",
+	}, "session-id", time.Date(2024, 3, 28, 10, 30, 0, 0, time.UTC))
+}
+
+func assertAuthentication(t *testing.T, expected *comdirect.Authentication, actual *comdirect.Authentication) {
+	t.Helper()
+	if actual.AccessToken() != expected.AccessToken() ||
+		actual.SessionID() != expected.SessionID() ||
+		!actual.ExpiryTime().Equal(expected.ExpiryTime()) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
@@ -0,0 +1,49 @@
+package tokenstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/zalando/go-keyring"
+)
+
+// Keyring is a comdirect.TokenStore that stores the Authentication as JSON in the keyring
+// of the operating system, e.g. the macOS Keychain or the Secret Service on Linux.
+type Keyring struct {
+	service string
+	user    string
+}
+
+// NewKeyring creates a Keyring that stores the Authentication under the given service and user.
+func NewKeyring(service string, user string) *Keyring {
+	return &Keyring{service: service, user: user}
+}
+
+func (k *Keyring) Load(ctx context.Context) (*comdirect.Authentication, error) {
+	data, err := keyring.Get(k.service, k.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, comdirect.ErrNoAuthentication
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode([]byte(data))
+}
+
+func (k *Keyring) Save(ctx context.Context, auth *comdirect.Authentication) error {
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(k.service, k.user, string(data))
+}
+
+func (k *Keyring) Delete(ctx context.Context) error {
+	err := keyring.Delete(k.service, k.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package tokenstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/tokenstore"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit()
+	ctx := context.Background()
+	store := tokenstore.NewKeyring("go-comdirect-test", "comdirect")
+
+	if _, err := store.Load(ctx); !errors.Is(err, comdirect.ErrNoAuthentication) {
+		t.Fatalf("expected ErrNoAuthentication, got: %v", err)
+	}
+	auth := testAuthentication()
+	if err := store.Save(ctx, auth); err != nil {
+		t.Fatalf("save failed: %s", err)
+	}
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("load failed: %s", err)
+	}
+	assertAuthentication(t, auth, loaded)
+
+	if err = store.Delete(ctx); err != nil {
+		t.Fatalf("delete failed: %s", err)
+	}
+	if err = store.Delete(ctx); err != nil {
+		t.Errorf("expected deleting a missing entry to succeed: %s", err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package tokenstore provides persistent implementations of comdirect.TokenStore.
+//
+//	store := tokenstore.NewKeyring("my-app", "comdirect")
+//	client := comdirect.NewWithAuthOptions(options, comdirect.WithTokenStore(store))
+package tokenstore
+
+import (
+	"encoding/json"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+func decode(data []byte) (*comdirect.Authentication, error) {
+	var auth comdirect.Authentication
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
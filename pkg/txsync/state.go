@@ -0,0 +1,99 @@
+package txsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jsattler/go-comdirect/internal/atomicfile"
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// State is the durable state of the Syncer, i.e. what was seen in the previous runs.
+type State struct {
+	Accounts map[string]*AccountState `json:"accounts"`
+}
+
+// AccountState is the State of a single account.
+type AccountState struct {
+	// LastSync is the time of the last successful sync of the account.
+	LastSync time.Time `json:"lastSync"`
+	// LastBookingDate is the latest booking date of all booked transactions seen so far.
+	LastBookingDate comdirect.Date `json:"lastBookingDate"`
+	// Booked holds the booked transactions within the overlap window before LastBookingDate by their key.
+	Booked map[string]Seen `json:"booked"`
+	// Pending holds the transactions that were not booked in the last run.
+	Pending []comdirect.AccountTransaction `json:"pending"`
+}
+
+// Seen is a booked transaction that was already emitted.
+type Seen struct {
+	BookingDate comdirect.Date `json:"bookingDate"`
+	Hash        string         `json:"hash"`
+}
+
+// NewState creates an empty State.
+func NewState() *State {
+	return &State{Accounts: map[string]*AccountState{}}
+}
+
+// account returns the AccountState of accountID or nil if the account was never synced.
+func (s *State) account(accountID string) *AccountState {
+	if s.Accounts == nil {
+		return nil
+	}
+	return s.Accounts[accountID]
+}
+
+// Store persists the State between runs. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the stored State, or an empty State if nothing was stored yet.
+	Load(ctx context.Context) (*State, error)
+	// Save replaces the stored State.
+	Save(ctx context.Context, state *State) error
+}
+
+// FileStore is a Store that keeps the State in a JSON file.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a FileStore for the file at path. The file is created on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Load(ctx context.Context) (*State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := NewState()
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Accounts == nil {
+		state.Accounts = map[string]*AccountState{}
+	}
+	return state, nil
+}
+
+func (f *FileStore) Save(ctx context.Context, state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return atomicfile.WriteFile(f.path, data)
+}
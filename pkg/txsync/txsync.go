@@ -0,0 +1,269 @@
+// Package txsync synchronizes the transactions of comdirect accounts incrementally. It remembers what was
+// seen in previous runs in a durable State and reports new, changed and removed transactions as Events,
+// e.g. to feed a bookkeeping system from a cron job.
+//
+// Booked transactions are requested starting at the latest known booking date, minus an overlap window
+// for transactions that are booked late. Transactions that are not booked yet have no reference and may
+// change or disappear until they are booked. A booked transaction that replaces a pending one is reported
+// as EventChanged instead of EventAdded, a pending transaction that disappears without being booked is
+// reported as EventRemoved.
+package txsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"iter"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+)
+
+// DefaultOverlap is the number of days before the last booking date that are requested again,
+// so that transactions booked with an earlier booking date are not missed.
+const DefaultOverlap = 7
+
+// EventType is the kind of change of an Event.
+type EventType string
+
+const (
+	// EventAdded is emitted for transactions that were not seen before.
+	EventAdded EventType = "added"
+	// EventChanged is emitted for pending transactions that were booked and for booked transactions that changed.
+	EventChanged EventType = "changed"
+	// EventRemoved is emitted for pending transactions that disappeared without being booked.
+	EventRemoved EventType = "removed"
+)
+
+// Event is a change of a transaction since the previous sync.
+type Event struct {
+	Type        EventType                     `json:"type"`
+	AccountID   string                        `json:"accountId"`
+	Transaction comdirect.AccountTransaction  `json:"transaction"`
+	Previous    *comdirect.AccountTransaction `json:"previous,omitempty"`
+}
+
+// TransactionSource provides the transactions of an account. It is implemented by comdirect.Client.
+type TransactionSource interface {
+	AllTransactions(ctx context.Context, accountID string, options ...comdirect.Options) iter.Seq2[comdirect.AccountTransaction, error]
+}
+
+// Syncer synchronizes the transactions of accounts with a Store.
+type Syncer struct {
+	source   TransactionSource
+	store    Store
+	overlap  int
+	initial  comdirect.Date
+	pageSize int
+	now      func() time.Time
+}
+
+// Option configures a Syncer.
+type Option func(*Syncer)
+
+// WithOverlap sets the number of days before the last booking date that are requested again.
+// Defaults to DefaultOverlap.
+func WithOverlap(days int) Option {
+	return func(s *Syncer) {
+		if days >= 0 {
+			s.overlap = days
+		}
+	}
+}
+
+// WithInitialDate limits the first sync of an account to transactions booked on or after date.
+// By default, the first sync requests the full history.
+func WithInitialDate(date comdirect.Date) Option {
+	return func(s *Syncer) {
+		s.initial = date
+	}
+}
+
+// WithPageSize sets the number of transactions requested per page. Defaults to comdirect.DefaultPageSize.
+func WithPageSize(size int) Option {
+	return func(s *Syncer) {
+		if size > 0 {
+			s.pageSize = size
+		}
+	}
+}
+
+// New creates a Syncer that requests transactions from source and keeps its State in store.
+func New(source TransactionSource, store Store, opts ...Option) *Syncer {
+	s := &Syncer{
+		source:   source,
+		store:    store,
+		overlap:  DefaultOverlap,
+		pageSize: comdirect.DefaultPageSize,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Sync synchronizes the given accounts and returns the Events since the previous sync, oldest first.
+// The State is only saved if all accounts were synchronized, so that a failed run can simply be repeated.
+func (s *Syncer) Sync(ctx context.Context, accountIDs ...string) ([]Event, error) {
+	state, err := s.store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, accountID := range accountIDs {
+		accountState, accountEvents, err := s.syncAccount(ctx, accountID, state.account(accountID))
+		if err != nil {
+			return nil, err
+		}
+		state.Accounts[accountID] = accountState
+		events = append(events, accountEvents...)
+	}
+	if err = s.store.Save(ctx, state); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *Syncer) syncAccount(ctx context.Context, accountID string, previous *AccountState) (*AccountState, []Event, error) {
+	if previous == nil {
+		previous = &AccountState{}
+	}
+	from := s.initial
+	if !previous.LastBookingDate.IsZero() {
+		from = previous.LastBookingDate.AddDays(-s.overlap)
+	}
+
+	booked, err := s.fetch(ctx, accountID, comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusBooked).From(from), from)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, err := s.fetch(ctx, accountID, comdirect.NewTransactionQuery().BookingStatus(comdirect.BookingStatusNotBooked), comdirect.Date{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := &AccountState{
+		LastSync:        s.now(),
+		LastBookingDate: previous.LastBookingDate,
+		Booked:          map[string]Seen{},
+		Pending:         pending,
+	}
+	for key, seen := range previous.Booked {
+		next.Booked[key] = seen
+	}
+
+	var events []Event
+	unmatched := slices.Clone(previous.Pending)
+	// comdirect returns the newest transactions first, events are emitted oldest first.
+	for _, t := range slices.Backward(booked) {
+		key, hash := bookedKey(t), hashOf(t)
+		if seen, ok := next.Booked[key]; ok {
+			if seen.Hash != hash {
+				events = append(events, Event{Type: EventChanged, AccountID: accountID, Transaction: t})
+			}
+		} else if i := matchPending(unmatched, t); i >= 0 {
+			replaced := unmatched[i]
+			events = append(events, Event{Type: EventChanged, AccountID: accountID, Transaction: t, Previous: &replaced})
+			unmatched = slices.Delete(unmatched, i, i+1)
+		} else {
+			events = append(events, Event{Type: EventAdded, AccountID: accountID, Transaction: t})
+		}
+		next.Booked[key] = Seen{BookingDate: t.BookingDate, Hash: hash}
+		if t.BookingDate.After(next.LastBookingDate) {
+			next.LastBookingDate = t.BookingDate
+		}
+	}
+
+	known := map[string]bool{}
+	for _, t := range unmatched {
+		known[pendingKey(t)] = true
+	}
+	current := map[string]bool{}
+	for _, t := range slices.Backward(pending) {
+		key := pendingKey(t)
+		current[key] = true
+		if !known[key] {
+			events = append(events, Event{Type: EventAdded, AccountID: accountID, Transaction: t})
+		}
+	}
+	for _, t := range unmatched {
+		if !current[pendingKey(t)] {
+			events = append(events, Event{Type: EventRemoved, AccountID: accountID, Transaction: t})
+		}
+	}
+
+	// Only the booked transactions within the overlap window are requested again and must be remembered.
+	window := next.LastBookingDate.AddDays(-s.overlap)
+	for key, seen := range next.Booked {
+		if seen.BookingDate.Before(window) {
+			delete(next.Booked, key)
+		}
+	}
+	return next, events, nil
+}
+
+// fetch returns all transactions matching query. Transactions booked before from are skipped, in case
+// the date filter is not applied by comdirect.
+func (s *Syncer) fetch(ctx context.Context, accountID string, query *comdirect.TransactionQuery, from comdirect.Date) ([]comdirect.AccountTransaction, error) {
+	options := query.Options()
+	options.Add(comdirect.PagingCountQueryKey, strconv.Itoa(s.pageSize))
+
+	var transactions []comdirect.AccountTransaction
+	for t, err := range s.source.AllTransactions(ctx, accountID, options) {
+		if err != nil {
+			return nil, err
+		}
+		if !from.IsZero() && !t.BookingDate.IsZero() && t.BookingDate.Before(from) {
+			break
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+// matchPending returns the index of the pending transaction that was booked as t, or -1.
+// Pending transactions are matched by end-to-end reference, or by amount, counterparty and remittance info.
+func matchPending(pending []comdirect.AccountTransaction, t comdirect.AccountTransaction) int {
+	for i, p := range pending {
+		if p.EndToEndReference != "" && p.EndToEndReference == t.EndToEndReference {
+			return i
+		}
+	}
+	for i, p := range pending {
+		if p.EndToEndReference == "" && p.Amount.Value.Equal(t.Amount.Value) && p.Amount.Unit == t.Amount.Unit &&
+			p.Creditor.HolderName == t.Creditor.HolderName && p.Remitter.HolderName == t.Remitter.HolderName &&
+			p.RemittanceInfo == t.RemittanceInfo {
+			return i
+		}
+	}
+	return -1
+}
+
+// bookedKey identifies a booked transaction by its reference, which is unique per account.
+func bookedKey(t comdirect.AccountTransaction) string {
+	if t.Reference != "" {
+		return "ref:" + t.Reference
+	}
+	return "hash:" + hashOf(t)
+}
+
+// pendingKey identifies a pending transaction, which has no reference yet.
+func pendingKey(t comdirect.AccountTransaction) string {
+	if t.EndToEndReference != "" {
+		return "e2e:" + t.EndToEndReference
+	}
+	return "hash:" + hashOf(t)
+}
+
+// hashOf returns a hash of the content of t. The NewTransaction flag is ignored, as it changes
+// with every login to the comdirect website.
+func hashOf(t comdirect.AccountTransaction) string {
+	t.NewTransaction = false
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,147 @@
+package txsync_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jsattler/go-comdirect/pkg/comdirect"
+	"github.com/jsattler/go-comdirect/pkg/comdirect/comdirecttest"
+	"github.com/jsattler/go-comdirect/pkg/txsync"
+)
+
+func TestSyncer_Sync(t *testing.T) {
+	client, server := newTestClient(t)
+	store := txsync.NewFileStore(filepath.Join(t.TempDir(), "sync.json"))
+	ctx := context.Background()
+
+	events, err := txsync.New(client, store).Sync(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("initial sync failed: %s", err)
+	}
+	if len(events) != 30 || countEvents(events, txsync.EventAdded) != 30 {
+		t.Fatalf("expected 30 added transactions, got %d events", len(events))
+	}
+	if !events[0].Transaction.BookingDate.Equal(comdirect.NewDate(2024, time.January, 4)) {
+		t.Errorf("expected oldest transaction first, got: %s", events[0].Transaction.BookingDate)
+	}
+
+	state, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load state: %s", err)
+	}
+	account := state.Accounts[comdirecttest.AccountID]
+	if account == nil || !account.LastBookingDate.Equal(comdirect.NewDate(2024, time.March, 28)) {
+		t.Fatalf("unexpected account state: %+v", account)
+	}
+	if len(account.Booked) != 3 || len(account.Pending) != 1 {
+		t.Errorf("expected 3 booked transactions within the overlap and 1 pending transaction, got %d and %d", len(account.Booked), len(account.Pending))
+	}
+
+	events, err = txsync.New(client, store).Sync(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("second sync failed: %s", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events without changes, got: %+v", events)
+	}
+
+	// Book the pending transaction and add a new pending one.
+	fixtures := comdirecttest.DefaultFixtures()
+	transactions := fixtures.Transactions[comdirecttest.AccountID]
+	booked := transactions[0]
+	booked.Reference = "3C2K00000030/1"
+	booked.BookingStatus = "BOOKED"
+	booked.BookingDate = comdirect.NewDate(2024, time.March, 29)
+	booked.ValutaDate = booked.BookingDate
+	pending := comdirect.AccountTransaction{
+		BookingStatus:     "NOTBOOKED",
+		Amount:            comdirect.AmountValue{Value: comdirect.MustParseDecimal("-10"), Unit: "EUR"},
+		Creditor:          comdirect.Creditor{HolderName: "Max Mustermann"},
+		EndToEndReference: "PAYOUT-1",
+	}
+	fixtures.Transactions[comdirecttest.AccountID] = append([]comdirect.AccountTransaction{pending, booked}, transactions[1:]...)
+	server.Seed(fixtures)
+
+	events, err = txsync.New(client, store).Sync(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("third sync failed: %s", err)
+	}
+	if len(events) != 2 || events[0].Type != txsync.EventChanged || events[1].Type != txsync.EventAdded {
+		t.Fatalf("expected booked pending transaction and new pending transaction, got: %+v", events)
+	}
+	if events[0].Previous == nil || events[0].Previous.BookingStatus != "NOTBOOKED" || events[0].Transaction.Reference != booked.Reference {
+		t.Errorf("unexpected changed event: %+v", events[0])
+	}
+	if events[1].Transaction.EndToEndReference != "PAYOUT-1" {
+		t.Errorf("unexpected added event: %+v", events[1])
+	}
+
+	// The pending transaction is canceled.
+	fixtures.Transactions[comdirecttest.AccountID] = fixtures.Transactions[comdirecttest.AccountID][1:]
+	server.Seed(fixtures)
+
+	events, err = txsync.New(client, store).Sync(ctx, comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("fourth sync failed: %s", err)
+	}
+	if len(events) != 1 || events[0].Type != txsync.EventRemoved || events[0].Transaction.EndToEndReference != "PAYOUT-1" {
+		t.Errorf("expected removed pending transaction, got: %+v", events)
+	}
+}
+
+func TestSyncer_InitialDate(t *testing.T) {
+	client, _ := newTestClient(t)
+	store := txsync.NewFileStore(filepath.Join(t.TempDir(), "sync.json"))
+
+	syncer := txsync.New(client, store, txsync.WithInitialDate(comdirect.NewDate(2024, time.March, 1)), txsync.WithPageSize(3))
+	events, err := syncer.Sync(context.Background(), comdirecttest.AccountID)
+	if err != nil {
+		t.Fatalf("sync failed: %s", err)
+	}
+	if len(events) != 11 {
+		t.Errorf("expected 10 booked transactions since March and 1 pending transaction, got %d", len(events))
+	}
+}
+
+func TestSyncer_Error(t *testing.T) {
+	client, _ := newTestClient(t)
+	store := txsync.NewFileStore(filepath.Join(t.TempDir(), "sync.json"))
+	ctx := context.Background()
+
+	if _, err := txsync.New(client, store).Sync(ctx, comdirecttest.AccountID, "unknown"); err == nil {
+		t.Fatal("expected sync of unknown account to fail")
+	}
+	state, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load state: %s", err)
+	}
+	if len(state.Accounts) != 0 {
+		t.Errorf("expected state not to be saved after a failed sync, got: %+v", state.Accounts)
+	}
+}
+
+func countEvents(events []txsync.Event, eventType txsync.EventType) int {
+	count := 0
+	for _, e := range events {
+		if e.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// newTestClient returns a Client authenticated against a new comdirecttest.Server.
+func newTestClient(t *testing.T) (*comdirect.Client, *comdirecttest.Server) {
+	t.Helper()
+	server := comdirecttest.NewServer()
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := server.AuthenticatedClient(ctx)
+	if err != nil {
+		t.Fatalf("authentication failed: %s", err)
+	}
+	return client, server
+}